@@ -2,22 +2,47 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"flag"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
 
 	"github.com/zucchinho/ocpp/internal/domain"
 	processor "github.com/zucchinho/ocpp/internal/event_processor"
+	idgenerator "github.com/zucchinho/ocpp/internal/id_generator"
 	inmemoryeventsource "github.com/zucchinho/ocpp/internal/in_memory_event_source"
+	"github.com/zucchinho/ocpp/internal/ocppj"
 	"github.com/zucchinho/ocpp/internal/projection"
 )
 
 func main() {
 	ctx := context.Background()
 	var inputFlag = flag.String("input", "", "input file")
+	var projectionFlag = flag.String("projection", "incremental", "projection to use: basic or incremental")
+	var formatFlag = flag.String("format", "json", "format of the input: json for events, or ocppj for captured OCPP-J frames")
+	var consistencyFlag = flag.Bool("consistency", false, "print the connector count consistency report for each charging station")
+	var asOfFlag = flag.String("as-of", "", "only consider the events which occurred at or before this RFC 3339 time")
+	var readingsStationFlag = flag.String("readings-station", "", "print the readings of a connector of this charging station as CSV, instead of the charging stations")
+	var readingsConnectorFlag = flag.Int("readings-connector", 1, "connector to print the readings of with -readings-station")
+	var fromFlag = flag.String("from", "", "start of the period to print readings or energy delivered for, as an RFC 3339 time")
+	var toFlag = flag.String("to", "", "end of the period to print readings or energy delivered for, as an RFC 3339 time")
+	var energyFlag = flag.Bool("energy", false, "print the energy delivered by each charging station in the period from -from to -to")
+	var anomaliesFlag = flag.Bool("anomalies", false, "print the anomalies in the connector readings of each charging station")
+	var correlationsFlag = flag.Bool("correlations", false, "print the requests and responses which haven't been paired by their correlation ID")
+	var deadlineFlag = flag.Duration("deadline", 30*time.Second, "time within which a request must be answered with -correlations, or 0 for no deadline")
+	var latencyFlag = flag.String("latency", "", "print the request latency statistics of each charging station: table or json")
+	var validationFlag = flag.Bool("validation", false, "print the violations of responses against their requests, of the responses which occurred in the period from -from to -to")
+	var statusesFlag = flag.Bool("statuses", false, "print the connector status transitions of each charging station")
+	var sessionsFlag = flag.Bool("sessions", false, "print the charging sessions of each charging station")
+	var idTagsFlag = flag.Bool("idtags", false, "print how each idTag has been used, and the verdicts on each charging station's authorizations")
+	var failuresFlag = flag.Bool("failures", false, "print the number of each charging station's requests of each message type which were answered with an error")
 	flag.Parse()
 
 	if *inputFlag == "" {
@@ -25,6 +50,10 @@ func main() {
 		return
 	}
 
+	asOf := parseTimeFlag("as-of", *asOfFlag)
+	from := parseTimeFlag("from", *fromFlag)
+	to := parseTimeFlag("to", *toFlag)
+
 	input := *inputFlag
 
 	jsonFile, err := os.Open(input)
@@ -37,17 +66,30 @@ func main() {
 		log.Fatalf("failed to read file: %v", err)
 	}
 
-	var events []domain.Event
-
-	if err := json.Unmarshal(jsonBytes, &events); err != nil {
-		log.Fatalf("failed to unmarshal json: %v", err)
+	events, frameErrs, err := decodeEvents(jsonBytes, *formatFlag)
+	if err != nil {
+		log.Fatalf("failed to decode input: %v", err)
+	}
+	for _, frameErr := range frameErrs {
+		log.Printf("skipped OCPP-J frame: %v", frameErr)
 	}
 
-	eventSource := inmemoryeventsource.NewInMemoryEventSource()
+	eventSource := inmemoryeventsource.NewInMemoryEventSource(idgenerator.NewULIDGenerator(), domain.OrderBySequence)
 	eventProcessor := processor.NewEventProcessor(
 		eventSource,
 	)
 
+	// The incremental projection folds in the events as they are created, so it subscribes before they are processed.
+	var incrementalProjection *projection.IncrementalProjection
+	switch *projectionFlag {
+	case "basic":
+	case "incremental":
+		incrementalProjection = projection.NewIncrementalProjection()
+		eventSource.Subscribe(incrementalProjection)
+	default:
+		log.Fatalf("unknown projection: %s", *projectionFlag)
+	}
+
 	var errEventProcessing error
 	for _, event := range events {
 		if err := eventProcessor.ProcessEvent(ctx, event); err != nil {
@@ -62,7 +104,27 @@ func main() {
 
 	log.Printf("processed %d events\n", len(events))
 
-	views := projection.NewBasicProjection(eventSource)
+	var views domain.Projection = projection.NewBasicProjection(eventSource)
+	if incrementalProjection != nil {
+		views = incrementalProjection
+	}
+	if !asOf.IsZero() {
+		views = views.AsOf(asOf)
+	}
+	if !asOf.IsZero() {
+		log.Printf("as of %s\n", asOf.Format(time.RFC3339))
+	}
+
+	if *readingsStationFlag != "" {
+		readings, err := views.ConnectorReadings(ctx, *readingsStationFlag, int32(*readingsConnectorFlag), from, to)
+		if err != nil {
+			log.Fatalf("failed to get connector readings: %v", err)
+		}
+		if err := writeReadingsCSV(os.Stdout, readings); err != nil {
+			log.Fatalf("failed to write connector readings: %v", err)
+		}
+		return
+	}
 
 	// print the number of charging stations
 	numChargingStations, err := views.NumChargingStations(ctx)
@@ -83,4 +145,248 @@ func main() {
 		}
 		log.Printf("charging station: %s\n", stationJSON)
 	}
+	if *consistencyFlag {
+		for _, station := range chargingStations {
+			report, err := views.ConnectorConsistency(ctx, station.ID)
+			if err != nil {
+				log.Fatalf("failed to get connector consistency: %v", err)
+			}
+			reportJSON, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				log.Fatalf("failed to marshal connector consistency: %v", err)
+			}
+			log.Printf("connector consistency: %s\n", reportJSON)
+		}
+	}
+	if *energyFlag {
+		for _, station := range chargingStations {
+			energy, err := views.StationEnergyDelivered(ctx, station.ID, from, to)
+			if err != nil {
+				log.Fatalf("failed to get energy delivered: %v", err)
+			}
+			energyJSON, err := json.MarshalIndent(energy, "", "  ")
+			if err != nil {
+				log.Fatalf("failed to marshal energy delivered: %v", err)
+			}
+			log.Printf("energy delivered: %s\n", energyJSON)
+		}
+	}
+	if *correlationsFlag {
+		// Pending requests are aged to the instant the view is as of, or to the latest event.
+		report, err := views.Correlations(ctx, asOf, *deadlineFlag)
+		if err != nil {
+			log.Fatalf("failed to get correlations: %v", err)
+		}
+		reportJSON, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatalf("failed to marshal correlations: %v", err)
+		}
+		log.Printf("correlations: %s\n", reportJSON)
+	}
+	if *validationFlag {
+		violations, err := views.ValidationLog(ctx, domain.ValidationLogFilter{
+			OccurredFrom: from,
+			OccurredTo:   to,
+		})
+		if err != nil {
+			log.Fatalf("failed to get validation log: %v", err)
+		}
+		for _, violation := range violations {
+			violationJSON, err := json.MarshalIndent(violation, "", "  ")
+			if err != nil {
+				log.Fatalf("failed to marshal response violation: %v", err)
+			}
+			log.Printf("response violation: %s\n", violationJSON)
+		}
+	}
+	switch *latencyFlag {
+	case "":
+	case "table", "json":
+		latencies, err := views.RequestLatencies(ctx)
+		if err != nil {
+			log.Fatalf("failed to get request latencies: %v", err)
+		}
+		if *latencyFlag == "table" {
+			if err := writeLatencyTable(os.Stdout, latencies); err != nil {
+				log.Fatalf("failed to write request latencies: %v", err)
+			}
+			break
+		}
+		latenciesJSON, err := json.MarshalIndent(latencies, "", "  ")
+		if err != nil {
+			log.Fatalf("failed to marshal request latencies: %v", err)
+		}
+		fmt.Println(string(latenciesJSON))
+	default:
+		log.Fatalf("unknown latency format: %s", *latencyFlag)
+	}
+	if *anomaliesFlag {
+		for _, station := range chargingStations {
+			anomalies, err := views.ReadingAnomalies(ctx, station.ID)
+			if err != nil {
+				log.Fatalf("failed to get reading anomalies: %v", err)
+			}
+			for _, anomaly := range anomalies {
+				anomalyJSON, err := json.MarshalIndent(anomaly, "", "  ")
+				if err != nil {
+					log.Fatalf("failed to marshal reading anomaly: %v", err)
+				}
+				log.Printf("reading anomaly: %s\n", anomalyJSON)
+			}
+		}
+	}
+	if *statusesFlag {
+		for _, station := range chargingStations {
+			transitions, err := views.ConnectorStatusTransitions(ctx, station.ID)
+			if err != nil {
+				log.Fatalf("failed to get connector status transitions: %v", err)
+			}
+			for _, transition := range transitions {
+				transitionJSON, err := json.MarshalIndent(transition, "", "  ")
+				if err != nil {
+					log.Fatalf("failed to marshal connector status transition: %v", err)
+				}
+				log.Printf("connector status transition: %s\n", transitionJSON)
+			}
+		}
+	}
+	if *sessionsFlag {
+		for _, station := range chargingStations {
+			sessions, err := views.Sessions(ctx, station.ID)
+			if err != nil {
+				log.Fatalf("failed to get sessions: %v", err)
+			}
+			for _, session := range sessions {
+				sessionJSON, err := json.MarshalIndent(session, "", "  ")
+				if err != nil {
+					log.Fatalf("failed to marshal session: %v", err)
+				}
+				log.Printf("session: %s\n", sessionJSON)
+			}
+		}
+	}
+	if *idTagsFlag {
+		idTags, err := views.IDTags(ctx)
+		if err != nil {
+			log.Fatalf("failed to get idTags: %v", err)
+		}
+		idTagsJSON, err := json.MarshalIndent(idTags, "", "  ")
+		if err != nil {
+			log.Fatalf("failed to marshal idTags: %v", err)
+		}
+		log.Printf("idTags: %s\n", idTagsJSON)
+
+		authorizations, err := views.Authorizations(ctx)
+		if err != nil {
+			log.Fatalf("failed to get authorizations: %v", err)
+		}
+		authorizationsJSON, err := json.MarshalIndent(authorizations, "", "  ")
+		if err != nil {
+			log.Fatalf("failed to marshal authorizations: %v", err)
+		}
+		log.Printf("authorizations: %s\n", authorizationsJSON)
+	}
+
+	if *failuresFlag {
+		failures, err := views.Failures(ctx)
+		if err != nil {
+			log.Fatalf("failed to get failures: %v", err)
+		}
+		failuresJSON, err := json.MarshalIndent(failures, "", "  ")
+		if err != nil {
+			log.Fatalf("failed to marshal failures: %v", err)
+		}
+		log.Printf("failures: %s\n", failuresJSON)
+	}
+}
+
+// decodeEvents decodes the events of the input in the format. The frames of an OCPP-J capture which can't be converted
+// to events, e.g. a CALL of an action which isn't supported, are skipped with an error each, so that the rest of the
+// capture is still processed.
+func decodeEvents(data []byte, format string) ([]domain.Event, []error, error) {
+	switch format {
+	case "json":
+		var events []domain.Event
+		if err := json.Unmarshal(data, &events); err != nil {
+			return nil, nil, fmt.Errorf("unmarshal json: %w", err)
+		}
+		return events, nil, nil
+	case "ocppj":
+		var envelopes []ocppj.Envelope
+		if err := json.Unmarshal(data, &envelopes); err != nil {
+			return nil, nil, fmt.Errorf("decode OCPP-J frames: %w", err)
+		}
+		events, err := ocppj.Events(envelopes)
+		var frameErrs []error
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			frameErrs = joined.Unwrap()
+		}
+		return events, frameErrs, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+// parseTimeFlag parses the value of the named flag as an RFC 3339 time, returning the zero time if it isn't set.
+func parseTimeFlag(name string, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Fatalf("failed to parse %s time: %v", name, err)
+	}
+
+	return t
+}
+
+// writeReadingsCSV writes the connector readings as CSV, with a header.
+func writeReadingsCSV(w io.Writer, readings []domain.ConnectorReading) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write([]string{"occurredAt", "stationId", "connectorId", "reading", "readingWh", "messageType", "eventId"}); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	for _, reading := range readings {
+		var readingWh string
+		if reading.ReadingValue != nil {
+			readingWh = reading.ReadingValue.Wh().String()
+		}
+		if err := csvWriter.Write([]string{
+			reading.OccurredAt.Format(time.RFC3339Nano),
+			reading.StationID,
+			strconv.Itoa(int(reading.ConnectorID)),
+			reading.Reading,
+			readingWh,
+			reading.MessageType,
+			reading.EventID,
+		}); err != nil {
+			return fmt.Errorf("write reading: %w", err)
+		}
+	}
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// writeLatencyTable writes the request latency statistics as a table, with a row for each station's requests of all
+// message types, followed by a row for each message type.
+func writeLatencyTable(w io.Writer, latencies []domain.StationLatencies) error {
+	tabWriter := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "STATION\tMESSAGE TYPE\tCOUNT\tMIN\tP50\tP95\tMAX")
+	writeRow := func(stationID string, stats domain.LatencyStats) {
+		messageType := stats.MessageType
+		if messageType == "" {
+			messageType = "all"
+		}
+		fmt.Fprintf(tabWriter, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n", stationID, messageType, stats.Count, stats.Min, stats.P50, stats.P95, stats.Max)
+	}
+	for _, stationLatencies := range latencies {
+		writeRow(stationLatencies.StationID, stationLatencies.All)
+		for _, stats := range stationLatencies.ByMessageType {
+			writeRow(stationLatencies.StationID, stats)
+		}
+	}
+
+	return tabWriter.Flush()
 }
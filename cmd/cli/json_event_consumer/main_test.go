@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+	processor "github.com/zucchinho/ocpp/internal/event_processor"
+	idgenerator "github.com/zucchinho/ocpp/internal/id_generator"
+	inmemoryeventsource "github.com/zucchinho/ocpp/internal/in_memory_event_source"
+	"github.com/zucchinho/ocpp/internal/projection"
+)
+
+func TestDecodeEvents_MixedOCPPJCapture(t *testing.T) {
+	// arrange
+	data := []byte(`[
+		{"stationId": "station-1", "timestamp": "2022-01-01T00:00:00Z", "frame": [2, "u1", "Heartbeat", {}]},
+		{"stationId": "station-1", "timestamp": "2022-01-01T00:00:01Z", "frame": [3, "u1", {"currentTime": "2022-01-01T00:00:01Z"}]},
+		{"stationId": "station-1", "timestamp": "2022-01-01T00:00:02Z", "frame": [2, "u2", "DataTransfer", {"vendorId": "vendor"}]},
+		{"stationId": "station-2", "timestamp": "2022-01-01T00:00:03Z", "frame": [2, "u3", "RemoteStartTransaction", {"idTag": "tag-1"}]},
+		{"stationId": "station-2", "timestamp": "2022-01-01T00:00:04Z", "frame": [2, "u4", "Heartbeat", {}]}
+	]`)
+
+	// act
+	events, frameErrs, err := decodeEvents(data, "ocppj")
+
+	// assert
+	require.NoError(t, err)
+	require.Len(t, frameErrs, 2)
+	assert.ErrorIs(t, frameErrs[0], domain.ErrUnknownMessageType)
+	assert.ErrorIs(t, frameErrs[1], domain.ErrUnknownMessageType)
+	require.Len(t, events, 3)
+
+	ctx := context.Background()
+	eventSource := inmemoryeventsource.NewInMemoryEventSource(idgenerator.NewULIDGenerator(), domain.OrderBySequence)
+	eventProcessor := processor.NewEventProcessor(eventSource)
+	for _, event := range events {
+		require.NoError(t, eventProcessor.ProcessEvent(ctx, event))
+	}
+	numChargingStations, err := projection.NewBasicProjection(eventSource).NumChargingStations(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, numChargingStations)
+}
+
+func TestDecodeEvents_InvalidOCPPJCapture(t *testing.T) {
+	// act
+	_, _, err := decodeEvents([]byte(`{`), "ocppj")
+
+	// assert
+	assert.Error(t, err)
+}
@@ -1,8 +1,63 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrEventNotFound is returned when the event is not found.
 	ErrEventNotFound = errors.New("event not found")
+	// ErrChargingStationNotFound is returned when there is no charging station with the given ID.
+	ErrChargingStationNotFound = errors.New("charging station not found")
+	// ErrEventAlreadyExists is returned when creating an event with the ID of an existing event.
+	ErrEventAlreadyExists = errors.New("event already exists")
+	// ErrSubscriberFailed is returned when an event was created, but a subscriber of the event source failed to handle
+	// it. The event is stored nonetheless, so creating it again is a no-op.
+	ErrSubscriberFailed = errors.New("event created, but a subscriber failed to handle it")
+	// ErrNotDurable is returned when a change was persisted and is served, but couldn't be synced, so it may not
+	// survive a crash.
+	ErrNotDurable = errors.New("change persisted, but not synced, so it may not survive a crash")
+	// ErrDuplicateConflict is returned when an event has the same correlation ID and message ID as an existing event,
+	// but a different message.
+	ErrDuplicateConflict = errors.New("event conflicts with an existing event with the same correlation ID and message ID")
+	// ErrInvalidCursor is returned when querying with a cursor which wasn't returned by a previous query.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidFilter is returned when querying with an invalid filter, e.g. one with a negative limit.
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrInvalidReading is returned when a meter reading can't be parsed.
+	ErrInvalidReading = errors.New("invalid reading")
+	// ErrInvalidPeriod is returned when a period ends before it starts.
+	ErrInvalidPeriod = errors.New("invalid period")
+	// ErrUnknownMessageType is returned when decoding the payload of an event of a message type with no registered payload.
+	ErrUnknownMessageType = errors.New("unknown message type")
+	// ErrMismatchedPayload is returned when an event's payload is of another message type than the event.
+	ErrMismatchedPayload = errors.New("payload doesn't match the event's message type")
+	// ErrInvalidDeadline is returned when the deadline for answering a request is negative.
+	ErrInvalidDeadline = errors.New("invalid deadline")
+	// ErrInvalidInterval is returned when a heartbeat interval is negative.
+	ErrInvalidInterval = errors.New("invalid interval")
+	// ErrInvalidConnectorID is returned when a connector ID is negative.
+	ErrInvalidConnectorID = errors.New("invalid connector ID")
+	// ErrInvalidStatus is returned when a connector status isn't one defined by OCPP.
+	ErrInvalidStatus = errors.New("invalid status")
+	// ErrMissingIDTag is returned when an idTag which must be given isn't.
+	ErrMissingIDTag = errors.New("missing idTag")
+	// ErrMissingErrorCode is returned when an error a request was answered with has no error code.
+	ErrMissingErrorCode = errors.New("missing error code")
 )
+
+// ValidationError is returned when a field of an event's payload is invalid.
+type ValidationError struct {
+	// Field is the path of the field in the payload, e.g. "meterValues[0].reading".
+	Field string
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %v", e.Field, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
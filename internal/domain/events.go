@@ -1,7 +1,11 @@
 package domain
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
 	"time"
 )
 
@@ -11,15 +15,154 @@ const (
 	EventTypeMeterValuesNotification = "MeterValuesNotification"
 	EventTypeConnectorListRequest    = "ConnectorListRequest"
 	EventTypeConnectorListResponse   = "ConnectorListResponse"
+	// The message types of the messages a station sends about itself are named after their OCPP 1.6 actions.
+	EventTypeBootNotification           = "BootNotification"
+	EventTypeBootNotificationResponse   = "BootNotificationResponse"
+	EventTypeHeartbeat                  = "Heartbeat"
+	EventTypeHeartbeatResponse          = "HeartbeatResponse"
+	EventTypeStatusNotification         = "StatusNotification"
+	EventTypeStatusNotificationResponse = "StatusNotificationResponse"
+	EventTypeStartTransaction           = "StartTransaction"
+	EventTypeStartTransactionResponse   = "StartTransactionResponse"
+	EventTypeStopTransaction            = "StopTransaction"
+	EventTypeStopTransactionResponse    = "StopTransactionResponse"
+	EventTypeAuthorize                  = "Authorize"
+	EventTypeAuthorizeResponse          = "AuthorizeResponse"
+	// EventTypeCallError is the message type of the errors a request is answered with instead of a response.
+	EventTypeCallError = "CallError"
 )
 
 type Event struct {
-	ID            string         `json:"id"`
-	MessageID     string         `json:"messageId"`
-	CorrelationID string         `json:"correlationId"`
-	MessageType   string         `json:"messageType"`
-	OccurredAt    time.Time      `json:"occurredAt"`
-	Payload       map[string]any `json:"payload"`
+	ID            string    `json:"id"`
+	MessageID     string    `json:"messageId"`
+	CorrelationID string    `json:"correlationId"`
+	MessageType   string    `json:"messageType"`
+	OccurredAt    time.Time `json:"occurredAt"`
+	// Payload is the typed payload of the event's message type, decoded as the payload type it is registered with.
+	Payload Payload `json:"payload"`
+	// Sequence is the position of the event in the order it was created in, assigned by the event source.
+	Sequence uint64 `json:"sequence,omitempty"`
+	// StationID is the ID of the station the event is attributed to, resolved by the event source. Events whose
+	// payload doesn't name a station, e.g. responses, are attributed to the station of the events they are
+	// correlated with.
+	StationID string `json:"stationId,omitempty"`
+}
+
+// After reports whether the event is later than the other event: either it occurred after it, or it occurred at
+// the same time but was created after it.
+func (e Event) After(other Event) bool {
+	if !e.OccurredAt.Equal(other.OccurredAt) {
+		return e.OccurredAt.After(other.OccurredAt)
+	}
+
+	return e.Sequence > other.Sequence
+}
+
+// EventOrdering is the order in which an event source returns events.
+type EventOrdering int
+
+const (
+	// OrderBySequence orders events by the order they were created in.
+	OrderBySequence EventOrdering = iota
+	// OrderByOccurredAt orders events by when they occurred, then by the order they were created in.
+	OrderByOccurredAt
+)
+
+// SortEvents sorts the events in the given order.
+func SortEvents(events []Event, ordering EventOrdering) {
+	switch ordering {
+	case OrderByOccurredAt:
+		sort.Slice(events, func(i, j int) bool {
+			return events[j].After(events[i])
+		})
+	default:
+		sort.Slice(events, func(i, j int) bool {
+			return events[i].Sequence < events[j].Sequence
+		})
+	}
+}
+
+// MessageKey identifies an OCPP message, which may be received more than once, e.g. when a station retransmits it.
+type MessageKey struct {
+	CorrelationID string
+	MessageID     string
+}
+
+// MessageKey returns the key identifying the event's message, or false if the event has no message ID.
+func (e Event) MessageKey() (MessageKey, bool) {
+	if e.MessageID == "" {
+		return MessageKey{}, false
+	}
+
+	return MessageKey{
+		CorrelationID: e.CorrelationID,
+		MessageID:     e.MessageID,
+	}, true
+}
+
+// SameMessage reports whether the events carry the same message, i.e. have the same message type and payload. When
+// they occurred isn't compared, as a retransmission of a message is received after the original. Payloads are
+// compared by their JSON encoding, so that a payload which has been through JSON is the same as the original.
+func (e Event) SameMessage(other Event) bool {
+	if e.MessageType != other.MessageType {
+		return false
+	}
+
+	payload, err := json.Marshal(e.Payload)
+	if err != nil {
+		return false
+	}
+	otherPayload, err := json.Marshal(other.Payload)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(payload, otherPayload)
+}
+
+// PayloadStationID returns the station ID named by the event's payload, or an empty string if it doesn't name one,
+// as extracted by its registered message type.
+func (e Event) PayloadStationID() string {
+	messageType, ok := LookupMessageType(e.MessageType)
+	if !ok {
+		return ""
+	}
+
+	return messageType.StationID(e.Payload)
+}
+
+// MarshalJSON encodes the event as JSON. It returns ErrMismatchedPayload if the event's payload is of another message
+// type, as the event couldn't be decoded again.
+func (e Event) MarshalJSON() ([]byte, error) {
+	if e.Payload != nil && e.Payload.MessageType() != e.MessageType {
+		return nil, fmt.Errorf("%w: %s payload for %s event", ErrMismatchedPayload, e.Payload.MessageType(), e.MessageType)
+	}
+
+	// event has the fields of Event, but not its methods, so is encoded as a plain struct.
+	type event Event
+	return json.Marshal(event(e))
+}
+
+// UnmarshalJSON decodes the event from JSON, decoding its payload as the payload registered for its message type. It
+// returns ErrUnknownMessageType if no payload is registered for the message type.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	type event Event
+	var decoded struct {
+		event
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	payload, err := DecodePayload(decoded.MessageType, decoded.Payload)
+	if err != nil {
+		return err
+	}
+	*e = Event(decoded.event)
+	e.Payload = payload
+
+	return nil
 }
 
 // MeterValuesRequestPayload is the payload for the MeterValuesRequest event.
@@ -28,13 +171,44 @@ type MeterValuesRequestPayload struct {
 	ConnectorID int32  `json:"connectorId"`
 }
 
-// MeterValue is a single meter value containing the reading for a connector.
+// MeterValue is a single meter value containing the reading for a connector. Meter values are created with
+// NewMeterValue or decoded from JSON, which parse the reading once for the projections to read.
 type MeterValue struct {
-	ConnectorID int32  `json:"connectorId"`
-	Reading     string `json:"reading"`
+	ConnectorID int32 `json:"connectorId"`
+	// Reading is the reading as reported by the station, kept for audit.
+	Reading string `json:"reading"`
+	// Value is the parsed reading, or nil if the reading couldn't be parsed. It is derived from Reading, so isn't
+	// encoded.
+	Value *Reading `json:"-"`
 }
 
-// MeterValuesRequestPayload is the payload for the MeterValuesRequest event.
+// NewMeterValue returns the meter value of the connector's reading, parsing the reading.
+func NewMeterValue(connectorID int32, reading string) MeterValue {
+	meterValue := MeterValue{
+		ConnectorID: connectorID,
+		Reading:     reading,
+	}
+	if value, err := ParseReading(reading); err == nil {
+		meterValue.Value = &value
+	}
+
+	return meterValue
+}
+
+// UnmarshalJSON decodes the meter value from JSON, parsing its reading.
+func (m *MeterValue) UnmarshalJSON(data []byte) error {
+	// meterValue has the fields of a MeterValue without its methods, so that decoding it doesn't recurse.
+	type meterValue MeterValue
+	var decoded meterValue
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*m = NewMeterValue(decoded.ConnectorID, decoded.Reading)
+
+	return nil
+}
+
+// MeterValuesResponsePayload is the payload for the MeterValuesResponse event.
 type MeterValuesResponsePayload struct {
 	MeterValues []MeterValue `json:"meterValues"`
 }
@@ -55,6 +229,216 @@ type ConnectorListResponsePayload struct {
 	NumConnectors int `json:"numConnectors"`
 }
 
+// BootNotificationPayload is the payload for the BootNotification event, sent by a station when it starts up.
+type BootNotificationPayload struct {
+	StationID               string `json:"stationId"`
+	ChargePointVendor       string `json:"chargePointVendor"`
+	ChargePointModel        string `json:"chargePointModel"`
+	ChargePointSerialNumber string `json:"chargePointSerialNumber,omitempty"`
+	FirmwareVersion         string `json:"firmwareVersion,omitempty"`
+}
+
+// RegistrationStatus is whether the central system accepted a station's BootNotification.
+type RegistrationStatus string
+
+const (
+	RegistrationAccepted RegistrationStatus = "Accepted"
+	RegistrationPending  RegistrationStatus = "Pending"
+	RegistrationRejected RegistrationStatus = "Rejected"
+)
+
+// BootNotificationResponsePayload is the payload for the BootNotificationResponse event.
+type BootNotificationResponsePayload struct {
+	Status      RegistrationStatus `json:"status"`
+	CurrentTime time.Time          `json:"currentTime"`
+	// Interval is the number of seconds the station is to send heartbeats at, if accepted, or to wait before sending
+	// another BootNotification otherwise.
+	Interval int `json:"interval"`
+}
+
+// HeartbeatPayload is the payload for the Heartbeat event, sent by a station to let the central system know it is
+// still connected.
+type HeartbeatPayload struct {
+	StationID string `json:"stationId"`
+}
+
+// HeartbeatResponsePayload is the payload for the HeartbeatResponse event.
+type HeartbeatResponsePayload struct {
+	CurrentTime time.Time `json:"currentTime"`
+}
+
+// ChargePointStatus is the availability of a connector, or of the whole station for connector 0.
+type ChargePointStatus string
+
+const (
+	StatusAvailable     ChargePointStatus = "Available"
+	StatusPreparing     ChargePointStatus = "Preparing"
+	StatusCharging      ChargePointStatus = "Charging"
+	StatusSuspendedEVSE ChargePointStatus = "SuspendedEVSE"
+	StatusSuspendedEV   ChargePointStatus = "SuspendedEV"
+	StatusFinishing     ChargePointStatus = "Finishing"
+	StatusReserved      ChargePointStatus = "Reserved"
+	StatusUnavailable   ChargePointStatus = "Unavailable"
+	StatusFaulted       ChargePointStatus = "Faulted"
+)
+
+// chargePointStatuses holds the statuses defined by OCPP 1.6.
+var chargePointStatuses = map[ChargePointStatus]bool{
+	StatusAvailable:     true,
+	StatusPreparing:     true,
+	StatusCharging:      true,
+	StatusSuspendedEVSE: true,
+	StatusSuspendedEV:   true,
+	StatusFinishing:     true,
+	StatusReserved:      true,
+	StatusUnavailable:   true,
+	StatusFaulted:       true,
+}
+
+// ChargePointErrorCode is the error reported by a station for a connector, e.g. "GroundFailure", or NoError.
+type ChargePointErrorCode string
+
+// NoError means that the connector has no error.
+const NoError ChargePointErrorCode = "NoError"
+
+// StatusNotificationPayload is the payload for the StatusNotification event, sent by a station when the status of
+// one of its connectors changes. Connector 0 stands for the whole station.
+type StatusNotificationPayload struct {
+	StationID   string               `json:"stationId"`
+	ConnectorID int32                `json:"connectorId"`
+	Status      ChargePointStatus    `json:"status"`
+	ErrorCode   ChargePointErrorCode `json:"errorCode"`
+	// Info is free-form information about the error, and VendorID and VendorErrorCode identify a vendor-specific error.
+	Info            string `json:"info,omitempty"`
+	VendorID        string `json:"vendorId,omitempty"`
+	VendorErrorCode string `json:"vendorErrorCode,omitempty"`
+}
+
+// StatusNotificationResponsePayload is the payload for the StatusNotificationResponse event, which has no fields.
+type StatusNotificationResponsePayload struct{}
+
+// AuthorizationStatus is whether the central system accepted an idTag.
+type AuthorizationStatus string
+
+const (
+	AuthorizationAccepted     AuthorizationStatus = "Accepted"
+	AuthorizationBlocked      AuthorizationStatus = "Blocked"
+	AuthorizationExpired      AuthorizationStatus = "Expired"
+	AuthorizationInvalid      AuthorizationStatus = "Invalid"
+	AuthorizationConcurrentTx AuthorizationStatus = "ConcurrentTx"
+)
+
+// IDTagInfo is the central system's verdict on an idTag.
+type IDTagInfo struct {
+	Status      AuthorizationStatus `json:"status"`
+	ExpiryDate  *time.Time          `json:"expiryDate,omitempty"`
+	ParentIDTag string              `json:"parentIdTag,omitempty"`
+}
+
+// AuthorizePayload is the payload for the Authorize event, sent by a station to ask whether an idTag may charge.
+type AuthorizePayload struct {
+	StationID string `json:"stationId"`
+	IDTag     string `json:"idTag"`
+}
+
+// AuthorizeResponsePayload is the payload for the AuthorizeResponse event.
+type AuthorizeResponsePayload struct {
+	IDTagInfo IDTagInfo `json:"idTagInfo"`
+}
+
+// CallErrorCode is the code of the error a request was answered with, as defined by OCPP-J.
+type CallErrorCode string
+
+const (
+	CallErrorNotImplemented               CallErrorCode = "NotImplemented"
+	CallErrorNotSupported                 CallErrorCode = "NotSupported"
+	CallErrorInternalError                CallErrorCode = "InternalError"
+	CallErrorProtocolError                CallErrorCode = "ProtocolError"
+	CallErrorSecurityError                CallErrorCode = "SecurityError"
+	CallErrorFormationViolation           CallErrorCode = "FormationViolation"
+	CallErrorPropertyConstraintViolation  CallErrorCode = "PropertyConstraintViolation"
+	CallErrorOccurenceConstraintViolation CallErrorCode = "OccurenceConstraintViolation"
+	CallErrorTypeConstraintViolation      CallErrorCode = "TypeConstraintViolation"
+	CallErrorGenericError                 CallErrorCode = "GenericError"
+)
+
+// CallErrorPayload is the payload for the CallError event, which answers the request with the same correlation ID
+// with an error instead of a response.
+type CallErrorPayload struct {
+	ErrorCode        CallErrorCode `json:"errorCode"`
+	ErrorDescription string        `json:"errorDescription,omitempty"`
+	// ErrorDetails are the details of the error, as sent.
+	ErrorDetails json.RawMessage `json:"errorDetails,omitempty"`
+}
+
+// StartTransactionPayload is the payload for the StartTransaction event, sent by a station when charging starts on
+// one of its connectors.
+type StartTransactionPayload struct {
+	StationID   string `json:"stationId"`
+	ConnectorID int32  `json:"connectorId"`
+	IDTag       string `json:"idTag"`
+	// MeterStart is the reading of the connector's meter in Wh when the transaction started.
+	MeterStart int `json:"meterStart"`
+	// Timestamp is when the transaction started, according to the station.
+	Timestamp     time.Time `json:"timestamp"`
+	ReservationID *int      `json:"reservationId,omitempty"`
+}
+
+// StartTransactionResponsePayload is the payload for the StartTransactionResponse event.
+type StartTransactionResponsePayload struct {
+	IDTagInfo IDTagInfo `json:"idTagInfo"`
+	// TransactionID is the ID the central system assigned to the transaction, which its StopTransaction refers to.
+	TransactionID int `json:"transactionId"`
+}
+
+// StopReason is why a transaction was stopped.
+type StopReason string
+
+const (
+	StopReasonEmergencyStop  StopReason = "EmergencyStop"
+	StopReasonEVDisconnected StopReason = "EVDisconnected"
+	StopReasonHardReset      StopReason = "HardReset"
+	StopReasonLocal          StopReason = "Local"
+	StopReasonOther          StopReason = "Other"
+	StopReasonPowerLoss      StopReason = "PowerLoss"
+	StopReasonReboot         StopReason = "Reboot"
+	StopReasonRemote         StopReason = "Remote"
+	StopReasonSoftReset      StopReason = "SoftReset"
+	StopReasonUnlockCommand  StopReason = "UnlockCommand"
+	StopReasonDeAuthorized   StopReason = "DeAuthorized"
+)
+
+// StopTransactionPayload is the payload for the StopTransaction event, sent by a station when charging stops.
+type StopTransactionPayload struct {
+	StationID     string `json:"stationId"`
+	TransactionID int    `json:"transactionId"`
+	// IDTag is the idTag which stopped the transaction, if it was stopped by one.
+	IDTag string `json:"idTag,omitempty"`
+	// MeterStop is the reading of the connector's meter in Wh when the transaction stopped.
+	MeterStop int `json:"meterStop"`
+	// Timestamp is when the transaction stopped, according to the station.
+	Timestamp time.Time `json:"timestamp"`
+	// Reason is why the transaction stopped, Local if it isn't given.
+	Reason StopReason `json:"reason,omitempty"`
+}
+
+// StopTransactionResponsePayload is the payload for the StopTransactionResponse event.
+type StopTransactionResponsePayload struct {
+	IDTagInfo *IDTagInfo `json:"idTagInfo,omitempty"`
+}
+
+// IDGenerator generates unique IDs for events.
+type IDGenerator interface {
+	// NewID returns a new ID, which sorts after every ID previously returned.
+	NewID() (string, error)
+}
+
+// EventHandler handles events as they are created in an event source.
+type EventHandler interface {
+	// HandleEvent handles the given event.
+	HandleEvent(ctx context.Context, event Event) error
+}
+
 type EventProcessor interface {
 	// ProcessEvent processes the given event.
 	ProcessEvent(ctx context.Context, event Event) error
@@ -64,10 +448,22 @@ type EventProcessor interface {
 type EventSource interface {
 	// Get returns the event by the ID.
 	Get(ctx context.Context, correlationID string) (Event, error)
-	// Create creates a new event, returning the event's unique ID.
+	// Create creates a new event, returning the event's unique ID, which is generated if the event doesn't have one.
+	// The event is assigned the next sequence number, overwriting any it was created with, and attributed to a
+	// station: the one named by its payload, else the one it was created with, else the one of the events it is
+	// correlated with. Events created before any correlated event names a station are attributed once one does.
+	// If an event with the same message key already exists, no event is created and the existing event's ID is
+	// returned, or ErrDuplicateConflict if its message differs. If an event with the same ID already exists,
+	// ErrEventAlreadyExists is returned. If the event was created but a subscriber failed to handle it, its ID is
+	// returned with ErrSubscriberFailed.
 	Create(ctx context.Context, event Event) (string, error)
-	// GetByCorrelationID returns all events with the given correlation ID.
+	// GetByCorrelationID returns all events with the given correlation ID, in the event source's ordering.
 	GetByCorrelationID(ctx context.Context, correlationID string) []Event
-	// GetAll returns all events.
+	// GetAll returns all events, in the event source's ordering.
 	GetAll(ctx context.Context) []Event
+	// Query returns a page of the events selected by the filter, in the event source's ordering. It returns
+	// ErrInvalidCursor if the filter's cursor wasn't returned by a previous query.
+	Query(ctx context.Context, filter Filter) (EventPage, error)
+	// Subscribe registers a handler which is called with every event created after the call.
+	Subscribe(handler EventHandler)
 }
@@ -0,0 +1,121 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zucchinho/ocpp/internal/domain (interfaces: EventSource)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/zucchinho/ocpp/internal/domain"
+)
+
+// MockEventSource is a mock of EventSource interface.
+type MockEventSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventSourceMockRecorder
+}
+
+// MockEventSourceMockRecorder is the mock recorder for MockEventSource.
+type MockEventSourceMockRecorder struct {
+	mock *MockEventSource
+}
+
+// NewMockEventSource creates a new mock instance.
+func NewMockEventSource(ctrl *gomock.Controller) *MockEventSource {
+	mock := &MockEventSource{ctrl: ctrl}
+	mock.recorder = &MockEventSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventSource) EXPECT() *MockEventSourceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockEventSource) Create(arg0 context.Context, arg1 domain.Event) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockEventSourceMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockEventSource)(nil).Create), arg0, arg1)
+}
+
+// Get mocks base method.
+func (m *MockEventSource) Get(arg0 context.Context, arg1 string) (domain.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(domain.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockEventSourceMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockEventSource)(nil).Get), arg0, arg1)
+}
+
+// GetAll mocks base method.
+func (m *MockEventSource) GetAll(arg0 context.Context) []domain.Event {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", arg0)
+	ret0, _ := ret[0].([]domain.Event)
+	return ret0
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockEventSourceMockRecorder) GetAll(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockEventSource)(nil).GetAll), arg0)
+}
+
+// GetByCorrelationID mocks base method.
+func (m *MockEventSource) GetByCorrelationID(arg0 context.Context, arg1 string) []domain.Event {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCorrelationID", arg0, arg1)
+	ret0, _ := ret[0].([]domain.Event)
+	return ret0
+}
+
+// GetByCorrelationID indicates an expected call of GetByCorrelationID.
+func (mr *MockEventSourceMockRecorder) GetByCorrelationID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCorrelationID", reflect.TypeOf((*MockEventSource)(nil).GetByCorrelationID), arg0, arg1)
+}
+
+// Query mocks base method.
+func (m *MockEventSource) Query(arg0 context.Context, arg1 domain.Filter) (domain.EventPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", arg0, arg1)
+	ret0, _ := ret[0].(domain.EventPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockEventSourceMockRecorder) Query(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockEventSource)(nil).Query), arg0, arg1)
+}
+
+// Subscribe mocks base method.
+func (m *MockEventSource) Subscribe(arg0 domain.EventHandler) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Subscribe", arg0)
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockEventSourceMockRecorder) Subscribe(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockEventSource)(nil).Subscribe), arg0)
+}
@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Payload is the payload of an event, of the message type it is registered for with RegisterMessageType.
+type Payload interface {
+	// MessageType returns the message type of the events which carry the payload.
+	MessageType() string
+}
+
+// Validator is implemented by payloads which can check their fields, returning a ValidationError for each invalid one.
+type Validator interface {
+	Validate() error
+}
+
+// DecodePayload decodes the JSON payload of an event of the message type, which is the zero payload if the data is
+// empty or null. It returns ErrUnknownMessageType if no payload is registered for the message type.
+func DecodePayload(messageType string, data []byte) (Payload, error) {
+	registered, ok := LookupMessageType(messageType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMessageType, messageType)
+	}
+
+	payload, err := registered.decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s payload: %w", messageType, err)
+	}
+
+	return payload, nil
+}
+
+// EventPayload returns the event's payload, or the zero payload of its message type if it has none. It returns
+// ErrUnknownMessageType if its message type isn't registered, and ErrMismatchedPayload if its payload is of another
+// message type.
+func EventPayload(event Event) (Payload, error) {
+	if event.Payload == nil {
+		return DecodePayload(event.MessageType, nil)
+	}
+	if _, ok := LookupMessageType(event.MessageType); !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMessageType, event.MessageType)
+	}
+	if event.Payload.MessageType() != event.MessageType {
+		return nil, fmt.Errorf("%w: %s payload for %s event", ErrMismatchedPayload, event.Payload.MessageType(), event.MessageType)
+	}
+
+	return event.Payload, nil
+}
+
+func (MeterValuesRequestPayload) MessageType() string       { return EventTypeMeterValuesRequest }
+func (MeterValuesResponsePayload) MessageType() string      { return EventTypeMeterValuesResponse }
+func (MeterValuesNotificationPayload) MessageType() string  { return EventTypeMeterValuesNotification }
+func (ConnectorListRequestPayload) MessageType() string     { return EventTypeConnectorListRequest }
+func (ConnectorListResponsePayload) MessageType() string    { return EventTypeConnectorListResponse }
+func (BootNotificationPayload) MessageType() string         { return EventTypeBootNotification }
+func (BootNotificationResponsePayload) MessageType() string { return EventTypeBootNotificationResponse }
+func (HeartbeatPayload) MessageType() string                { return EventTypeHeartbeat }
+func (HeartbeatResponsePayload) MessageType() string        { return EventTypeHeartbeatResponse }
+func (StatusNotificationPayload) MessageType() string       { return EventTypeStatusNotification }
+func (StatusNotificationResponsePayload) MessageType() string {
+	return EventTypeStatusNotificationResponse
+}
+func (StartTransactionPayload) MessageType() string         { return EventTypeStartTransaction }
+func (StartTransactionResponsePayload) MessageType() string { return EventTypeStartTransactionResponse }
+func (StopTransactionPayload) MessageType() string          { return EventTypeStopTransaction }
+func (StopTransactionResponsePayload) MessageType() string  { return EventTypeStopTransactionResponse }
+func (AuthorizePayload) MessageType() string                { return EventTypeAuthorize }
+func (AuthorizeResponsePayload) MessageType() string        { return EventTypeAuthorizeResponse }
+func (CallErrorPayload) MessageType() string                { return EventTypeCallError }
+
+// Validate checks that the reading of each of the meter values can be parsed.
+func (p MeterValuesResponsePayload) Validate() error {
+	return ValidateMeterValues(p.MeterValues)
+}
+
+// Validate checks that the reading of each of the meter values can be parsed.
+func (p MeterValuesNotificationPayload) Validate() error {
+	return ValidateMeterValues(p.MeterValues)
+}
+
+// Validate checks that the heartbeat interval isn't negative.
+func (p BootNotificationResponsePayload) Validate() error {
+	if p.Interval < 0 {
+		return &ValidationError{Field: "interval", Err: fmt.Errorf("%w: %d", ErrInvalidInterval, p.Interval)}
+	}
+
+	return nil
+}
+
+// Validate checks that the connector ID isn't negative and that the status is one defined by OCPP.
+func (p StatusNotificationPayload) Validate() error {
+	var errValidation error
+	if p.ConnectorID < 0 {
+		errValidation = errors.Join(errValidation, &ValidationError{
+			Field: "connectorId",
+			Err:   fmt.Errorf("%w: %d", ErrInvalidConnectorID, p.ConnectorID),
+		})
+	}
+	if !chargePointStatuses[p.Status] {
+		errValidation = errors.Join(errValidation, &ValidationError{
+			Field: "status",
+			Err:   fmt.Errorf("%w: %q", ErrInvalidStatus, p.Status),
+		})
+	}
+
+	return errValidation
+}
+
+// Validate checks that the transaction is on a connector, rather than the whole station, and that the meter reading
+// isn't negative.
+func (p StartTransactionPayload) Validate() error {
+	var errValidation error
+	if p.ConnectorID <= 0 {
+		errValidation = errors.Join(errValidation, &ValidationError{
+			Field: "connectorId",
+			Err:   fmt.Errorf("%w: %d", ErrInvalidConnectorID, p.ConnectorID),
+		})
+	}
+	if p.MeterStart < 0 {
+		errValidation = errors.Join(errValidation, &ValidationError{
+			Field: "meterStart",
+			Err:   fmt.Errorf("%w: %d", ErrInvalidReading, p.MeterStart),
+		})
+	}
+
+	return errValidation
+}
+
+// Validate checks that the meter reading isn't negative.
+func (p StopTransactionPayload) Validate() error {
+	if p.MeterStop < 0 {
+		return &ValidationError{Field: "meterStop", Err: fmt.Errorf("%w: %d", ErrInvalidReading, p.MeterStop)}
+	}
+
+	return nil
+}
+
+// Validate checks that the idTag is given.
+func (p AuthorizePayload) Validate() error {
+	if p.IDTag == "" {
+		return &ValidationError{Field: "idTag", Err: ErrMissingIDTag}
+	}
+
+	return nil
+}
+
+// Validate checks that the error has a code.
+func (p CallErrorPayload) Validate() error {
+	if p.ErrorCode == "" {
+		return &ValidationError{Field: "errorCode", Err: ErrMissingErrorCode}
+	}
+
+	return nil
+}
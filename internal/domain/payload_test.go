@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvent_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		wantPayload Payload
+		wantErr     error
+	}{
+		{
+			name:        "meter values request",
+			json:        `{"messageType": "MeterValuesRequest", "payload": {"stationId": "station-1", "connectorId": 1}}`,
+			wantPayload: MeterValuesRequestPayload{StationID: "station-1", ConnectorID: 1},
+		},
+		{
+			name: "meter values notification",
+			json: `{"messageType": "MeterValuesNotification", "payload": {"stationId": "station-1", "meterValues": [{"connectorId": 1, "reading": "100"}]}}`,
+			wantPayload: MeterValuesNotificationPayload{
+				StationID:   "station-1",
+				MeterValues: []MeterValue{NewMeterValue(1, "100")},
+			},
+		},
+		{
+			name:        "connector list response",
+			json:        `{"messageType": "ConnectorListResponse", "payload": {"numConnectors": 2}}`,
+			wantPayload: ConnectorListResponsePayload{NumConnectors: 2},
+		},
+		{
+			name: "boot notification response",
+			json: `{"messageType": "BootNotificationResponse", "payload": {"status": "Accepted", "currentTime": "2022-01-01T00:00:00Z", "interval": 300}}`,
+			wantPayload: BootNotificationResponsePayload{
+				Status:      RegistrationAccepted,
+				CurrentTime: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+				Interval:    300,
+			},
+		},
+		{
+			name:        "no payload",
+			json:        `{"messageType": "ConnectorListRequest"}`,
+			wantPayload: ConnectorListRequestPayload{},
+		},
+		{
+			name:    "unknown message type",
+			json:    `{"messageType": "Unknown", "payload": {}}`,
+			wantErr: ErrUnknownMessageType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			var event Event
+			err := json.Unmarshal([]byte(tt.json), &event)
+
+			// assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPayload, event.Payload)
+		})
+	}
+}
+
+func TestEvent_UnmarshalJSON_InvalidPayload(t *testing.T) {
+	// act
+	var event Event
+	err := json.Unmarshal([]byte(`{"messageType": "ConnectorListResponse", "payload": {"numConnectors": "two"}}`), &event)
+
+	// assert
+	assert.ErrorContains(t, err, "decode ConnectorListResponse payload")
+}
+
+func TestEvent_MarshalJSON_RoundTrip(t *testing.T) {
+	// arrange
+	event := Event{
+		ID:            "event-1",
+		MessageID:     "message-1",
+		CorrelationID: "correlation-1",
+		MessageType:   EventTypeMeterValuesResponse,
+		OccurredAt:    time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Payload: MeterValuesResponsePayload{
+			MeterValues: []MeterValue{NewMeterValue(1, "100")},
+		},
+		Sequence:  1,
+		StationID: "station-1",
+	}
+
+	// act
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+	var decoded Event
+	err = json.Unmarshal(data, &decoded)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, event, decoded)
+}
+
+func TestEvent_MarshalJSON_MismatchedPayload(t *testing.T) {
+	// act
+	_, err := json.Marshal(Event{
+		MessageType: EventTypeMeterValuesRequest,
+		Payload:     ConnectorListRequestPayload{StationID: "station-1"},
+	})
+
+	// assert
+	assert.ErrorIs(t, err, ErrMismatchedPayload)
+}
+
+func TestEventPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   Event
+		want    Payload
+		wantErr error
+	}{
+		{
+			name:  "payload",
+			event: Event{MessageType: EventTypeConnectorListRequest, Payload: ConnectorListRequestPayload{StationID: "station-1"}},
+			want:  ConnectorListRequestPayload{StationID: "station-1"},
+		},
+		{
+			name:  "no payload",
+			event: Event{MessageType: EventTypeConnectorListRequest},
+			want:  ConnectorListRequestPayload{},
+		},
+		{
+			name:    "no payload of an unknown message type",
+			event:   Event{MessageType: "Unknown"},
+			wantErr: ErrUnknownMessageType,
+		},
+		{
+			name:    "mismatched payload",
+			event:   Event{MessageType: EventTypeMeterValuesRequest, Payload: ConnectorListRequestPayload{}},
+			wantErr: ErrMismatchedPayload,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got, err := EventPayload(tt.event)
+
+			// assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Filter selects the events returned by EventSource.Query. Each field which is set narrows the selection, so the zero
+// value selects every event.
+type Filter struct {
+	// MessageTypes selects the events with any of the message types.
+	MessageTypes []string
+	// StationID selects the events attributed to the station.
+	StationID string
+	// CorrelationID selects the events with the correlation ID.
+	CorrelationID string
+	// OccurredFrom selects the events which occurred at or after the time.
+	OccurredFrom time.Time
+	// OccurredTo selects the events which occurred before the time.
+	OccurredTo time.Time
+	// Cursor continues the query after the end of a previous page, from its NextCursor.
+	Cursor string
+	// Limit is the maximum number of events in a page, with no maximum if it is zero.
+	Limit int
+}
+
+// Matches reports whether the event is selected by the filter, regardless of the cursor and limit.
+func (f Filter) Matches(event Event) bool {
+	if len(f.MessageTypes) > 0 && !containsString(f.MessageTypes, event.MessageType) {
+		return false
+	}
+	if f.StationID != "" && event.StationID != f.StationID {
+		return false
+	}
+	if f.CorrelationID != "" && event.CorrelationID != f.CorrelationID {
+		return false
+	}
+	if !f.OccurredFrom.IsZero() && event.OccurredAt.Before(f.OccurredFrom) {
+		return false
+	}
+	if !f.OccurredTo.IsZero() && !event.OccurredAt.Before(f.OccurredTo) {
+		return false
+	}
+
+	return true
+}
+
+// EventPage is a page of the events selected by a query.
+type EventPage struct {
+	Events []Event
+	// NextCursor continues the query after the last event of the page, and is empty if there are no more events.
+	NextCursor string
+}
+
+// Cursor is the position of the last event of a page, which the next page starts after.
+type Cursor struct {
+	OccurredAt time.Time `json:"occurredAt"`
+	Sequence   uint64    `json:"sequence"`
+}
+
+// EncodeCursor returns the cursor which continues a query after the event.
+func EncodeCursor(event Event) (string, error) {
+	data, err := json.Marshal(Cursor{
+		OccurredAt: event.OccurredAt,
+		Sequence:   event.Sequence,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes a cursor returned by EncodeCursor. It returns ErrInvalidCursor if it wasn't.
+func DecodeCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("decode cursor: %w", ErrInvalidCursor)
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cursor{}, fmt.Errorf("unmarshal cursor: %w", ErrInvalidCursor)
+	}
+
+	return c, nil
+}
+
+// Precedes reports whether the event comes after the cursor in the given order.
+func (c Cursor) Precedes(event Event, ordering EventOrdering) bool {
+	if ordering == OrderByOccurredAt {
+		return event.After(Event{OccurredAt: c.OccurredAt, Sequence: c.Sequence})
+	}
+
+	return event.Sequence > c.Sequence
+}
+
+// PageEvents returns the page of the events selected by the filter's cursor and limit. The events must be those
+// matching the filter, in any order; they are sorted in place in the given order. Event sources whose indexes are kept
+// in their ordering page through them without sorting; this is for candidates which can't be read in order.
+func PageEvents(events []Event, filter Filter, ordering EventOrdering) (EventPage, error) {
+	if filter.Limit < 0 {
+		return EventPage{}, fmt.Errorf("limit %d: %w", filter.Limit, ErrInvalidFilter)
+	}
+
+	SortEvents(events, ordering)
+
+	if filter.Cursor != "" {
+		c, err := DecodeCursor(filter.Cursor)
+		if err != nil {
+			return EventPage{}, err
+		}
+		events = events[sort.Search(len(events), func(i int) bool {
+			return c.Precedes(events[i], ordering)
+		}):]
+	}
+
+	if filter.Limit == 0 || len(events) <= filter.Limit {
+		return EventPage{Events: events}, nil
+	}
+
+	events = events[:filter.Limit]
+	nextCursor, err := EncodeCursor(events[len(events)-1])
+	if err != nil {
+		return EventPage{}, err
+	}
+
+	return EventPage{
+		Events:     events,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
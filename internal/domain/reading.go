@@ -0,0 +1,245 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// decimalPattern matches a decimal number without an exponent, e.g. "12", "-0.5" or "12.50".
+var decimalPattern = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+
+// Decimal is an exact decimal number, which keeps the number of digits after the decimal point it was written with.
+// The zero value is 0.
+type Decimal struct {
+	// value is the canonical form of the number, e.g. "-0.50" for "-.50".
+	value string
+}
+
+// ParseDecimal parses a decimal number without an exponent, e.g. "12.50".
+func ParseDecimal(s string) (Decimal, error) {
+	if !decimalPattern.MatchString(s) {
+		return Decimal{}, fmt.Errorf("not a decimal number: %q", s)
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	s = strings.TrimSuffix(s, ".")
+	if strings.HasPrefix(s, ".") {
+		s = "0" + s
+	}
+	if negative {
+		s = "-" + s
+	}
+
+	return Decimal{value: s}, nil
+}
+
+// MustParseDecimal parses a decimal number, panicking if it can't be parsed. It is intended for constants.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// decimalFromRat returns the rational number as a decimal with the number of digits after the decimal point,
+// rounding the last digit to the nearest if the number isn't exact.
+func decimalFromRat(r *big.Rat, scale int) Decimal {
+	return MustParseDecimal(r.FloatString(scale))
+}
+
+func (d Decimal) String() string {
+	if d.value == "" {
+		return "0"
+	}
+
+	return d.value
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int {
+	if _, fraction, ok := strings.Cut(d.value, "."); ok {
+		return len(fraction)
+	}
+
+	return 0
+}
+
+func (d Decimal) rat() *big.Rat {
+	r, _ := new(big.Rat).SetString(d.String())
+	return r
+}
+
+// Cmp compares the decimals, returning -1 if d is less than other, 0 if they are equal, and +1 if d is greater.
+func (d Decimal) Cmp(other Decimal) int {
+	return d.rat().Cmp(other.rat())
+}
+
+// Sign returns -1 if the decimal is negative, 0 if it is zero, and +1 if it is positive.
+func (d Decimal) Sign() int {
+	return d.rat().Sign()
+}
+
+// Add returns the sum of the decimals, with the larger of their scales.
+func (d Decimal) Add(other Decimal) Decimal {
+	return decimalFromRat(new(big.Rat).Add(d.rat(), other.rat()), max(d.Scale(), other.Scale()))
+}
+
+// Sub returns the difference of the decimals, with the larger of their scales.
+func (d Decimal) Sub(other Decimal) Decimal {
+	return decimalFromRat(new(big.Rat).Sub(d.rat(), other.rat()), max(d.Scale(), other.Scale()))
+}
+
+// MulRatio returns the decimal multiplied by the ratio of the numerator to the denominator, rounded to the number of
+// digits after the decimal point.
+func (d Decimal) MulRatio(numerator, denominator int64, scale int) Decimal {
+	return decimalFromRat(new(big.Rat).Mul(d.rat(), big.NewRat(numerator, denominator)), scale)
+}
+
+// shift returns the decimal multiplied by 10 to the power of places, moving the decimal point right by that many
+// places, or left if it is negative.
+func (d Decimal) shift(places int) Decimal {
+	factor := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(places))), nil))
+	if places < 0 {
+		factor.Inv(factor)
+	}
+
+	return decimalFromRat(new(big.Rat).Mul(d.rat(), factor), max(d.Scale()-places, 0))
+}
+
+// Float64 returns the nearest float64 to the decimal.
+func (d Decimal) Float64() float64 {
+	f, _ := d.rat().Float64()
+	return f
+}
+
+// MarshalJSON encodes the decimal as a JSON number, exactly as written.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON decodes the decimal from a JSON number or string.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+
+	return nil
+}
+
+// Unit is the unit of a meter reading.
+type Unit string
+
+const (
+	UnitWh  Unit = "Wh"
+	UnitKWh Unit = "kWh"
+)
+
+// unitExponents holds the power of 10 each unit is of a Wh.
+var unitExponents = map[Unit]int{
+	UnitWh:  0,
+	UnitKWh: 3,
+}
+
+// Reading is a meter reading, of the energy delivered by a connector.
+type Reading struct {
+	Value Decimal `json:"value"`
+	Unit  Unit    `json:"unit"`
+}
+
+// ParseReading parses a meter reading: a non-negative decimal number optionally followed by a unit, e.g. "12345",
+// "12345 Wh" or "12.345kWh". The unit is matched case insensitively, and defaults to Wh. It returns
+// ErrInvalidReading if the reading can't be parsed.
+func ParseReading(s string) (Reading, error) {
+	s = strings.TrimSpace(s)
+	unitStart := strings.IndexFunc(s, unicode.IsLetter)
+	if unitStart < 0 {
+		unitStart = len(s)
+	}
+
+	value, err := ParseDecimal(strings.TrimSpace(s[:unitStart]))
+	if err != nil {
+		return Reading{}, fmt.Errorf("%w: %v", ErrInvalidReading, err)
+	}
+	if value.Sign() < 0 {
+		return Reading{}, fmt.Errorf("%w: negative reading %q", ErrInvalidReading, s)
+	}
+
+	unit := UnitWh
+	if unitString := s[unitStart:]; unitString != "" {
+		var ok bool
+		if unit, ok = parseUnit(unitString); !ok {
+			return Reading{}, fmt.Errorf("%w: unknown unit %q", ErrInvalidReading, unitString)
+		}
+	}
+
+	return Reading{
+		Value: value,
+		Unit:  unit,
+	}, nil
+}
+
+func parseUnit(s string) (Unit, bool) {
+	for unit := range unitExponents {
+		if strings.EqualFold(s, string(unit)) {
+			return unit, true
+		}
+	}
+
+	return "", false
+}
+
+// Wh returns the reading in Wh.
+func (r Reading) Wh() Decimal {
+	return r.Value.shift(unitExponents[r.Unit])
+}
+
+func (r Reading) String() string {
+	return fmt.Sprintf("%s %s", r.Value, r.Unit)
+}
+
+// ValidateMeterValues checks that the reading of each of the meter values can be parsed, returning a ValidationError
+// for each which can't.
+func ValidateMeterValues(meterValues []MeterValue) error {
+	var errValidation error
+	for i, meterValue := range meterValues {
+		if _, err := ParseReading(meterValue.Reading); err != nil {
+			errValidation = errors.Join(errValidation, &ValidationError{
+				Field: fmt.Sprintf("meterValues[%d].reading", i),
+				Err:   err,
+			})
+		}
+	}
+
+	return errValidation
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+
+	return i
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReading(t *testing.T) {
+	tests := []struct {
+		name    string
+		reading string
+		want    Reading
+		wantWh  string
+		wantErr bool
+	}{
+		{
+			name:    "no unit",
+			reading: "12345",
+			want:    Reading{Value: MustParseDecimal("12345"), Unit: UnitWh},
+			wantWh:  "12345",
+		},
+		{
+			name:    "Wh",
+			reading: "12345.6 Wh",
+			want:    Reading{Value: MustParseDecimal("12345.6"), Unit: UnitWh},
+			wantWh:  "12345.6",
+		},
+		{
+			name:    "kWh without space",
+			reading: "12.3456kWh",
+			want:    Reading{Value: MustParseDecimal("12.3456"), Unit: UnitKWh},
+			wantWh:  "12345.6",
+		},
+		{
+			name:    "unit is case insensitive",
+			reading: " 12 KWH ",
+			want:    Reading{Value: MustParseDecimal("12"), Unit: UnitKWh},
+			wantWh:  "12000",
+		},
+		{
+			name:    "empty",
+			reading: "",
+			wantErr: true,
+		},
+		{
+			name:    "not a number",
+			reading: "twelve",
+			wantErr: true,
+		},
+		{
+			name:    "exponent",
+			reading: "1e3",
+			wantErr: true,
+		},
+		{
+			name:    "negative",
+			reading: "-1",
+			wantErr: true,
+		},
+		{
+			name:    "unknown unit",
+			reading: "12 MJ",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got, err := ParseReading(tt.reading)
+
+			// assert
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidReading)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantWh, got.Wh().String())
+		})
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	// arrange
+	a := MustParseDecimal("+.50")
+	b := MustParseDecimal("12.125")
+
+	// act
+	sum := a.Add(b)
+	difference := a.Sub(b)
+
+	// assert
+	assert.Equal(t, "0.50", a.String())
+	assert.Equal(t, "12.625", sum.String())
+	assert.Equal(t, "-11.625", difference.String())
+	assert.Equal(t, -1, a.Cmp(b))
+	assert.Equal(t, 0, MustParseDecimal("0.5").Cmp(a))
+	assert.Equal(t, -1, difference.Sign())
+	assert.Equal(t, "0", Decimal{}.String())
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	// arrange
+	var reading Reading
+
+	// act
+	err := json.Unmarshal([]byte(`{"value":12.50,"unit":"kWh"}`), &reading)
+	data, errMarshal := json.Marshal(reading)
+
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, errMarshal)
+	assert.Equal(t, `{"value":12.50,"unit":"kWh"}`, string(data))
+}
+
+func TestMeterValue_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want MeterValue
+	}{
+		{
+			name: "reading parsed",
+			json: `{"connectorId": 1, "reading": "1.5 kWh"}`,
+			want: MeterValue{
+				ConnectorID: 1,
+				Reading:     "1.5 kWh",
+				Value:       &Reading{Value: MustParseDecimal("1.5"), Unit: UnitKWh},
+			},
+		},
+		{
+			name: "unparsable reading kept without a value",
+			json: `{"connectorId": 1, "reading": "twelve"}`,
+			want: MeterValue{ConnectorID: 1, Reading: "twelve"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			var got MeterValue
+			err := json.Unmarshal([]byte(tt.json), &got)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, NewMeterValue(tt.want.ConnectorID, tt.want.Reading), got)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package domain
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// MessageKind is the role of a message in an exchange between the central system and a station.
+type MessageKind string
+
+const (
+	// MessageKindRequest is a message which expects a response with the same correlation ID.
+	MessageKindRequest MessageKind = "request"
+	// MessageKindResponse is a message which answers a request with the same correlation ID.
+	MessageKindResponse MessageKind = "response"
+	// MessageKindNotification is a message which expects no response.
+	MessageKindNotification MessageKind = "notification"
+	// MessageKindError is a message which answers a request of any message type with the same correlation ID with an
+	// error instead of a response.
+	MessageKindError MessageKind = "error"
+)
+
+// MessageType describes a message type registered with RegisterMessageType.
+type MessageType struct {
+	Name string
+	Kind MessageKind
+	// ResponseType is the name of the message type of the responses to a request, and RequestType the name of the
+	// message type of the requests a response answers.
+	ResponseType string
+	RequestType  string
+	// ExpectsNoResponse is whether requests of the message type aren't expected to be answered among the events, e.g.
+	// because their responses aren't captured, so that an unanswered request isn't pending or timed out.
+	ExpectsNoResponse bool
+	// stationID returns the ID of the station named by a payload of the message type, or an empty string if it
+	// doesn't name one.
+	stationID func(payload Payload) string
+	// decode decodes a JSON payload of the message type.
+	decode func(data []byte) (Payload, error)
+}
+
+// StationID returns the ID of the station named by the payload, or an empty string if it doesn't name one.
+func (m MessageType) StationID(payload Payload) string {
+	if m.stationID == nil || payload == nil {
+		return ""
+	}
+
+	return m.stationID(payload)
+}
+
+// MessageTypeOptions are the options a message type is registered with.
+type MessageTypeOptions[P Payload] struct {
+	Kind MessageKind
+	// ResponseType is the name of the message type of the responses to a request.
+	ResponseType string
+	// ExpectsNoResponse is whether requests aren't expected to be answered among the events.
+	ExpectsNoResponse bool
+	// StationID returns the ID of the station named by a payload, if payloads of the message type name one.
+	StationID func(payload P) string
+}
+
+// messageTypes holds the registered message types by name.
+var messageTypes = make(map[string]MessageType)
+
+func init() {
+	RegisterMessageType(MessageTypeOptions[MeterValuesRequestPayload]{
+		Kind:         MessageKindRequest,
+		ResponseType: EventTypeMeterValuesResponse,
+		StationID:    func(payload MeterValuesRequestPayload) string { return payload.StationID },
+	})
+	RegisterMessageType(MessageTypeOptions[MeterValuesResponsePayload]{
+		Kind: MessageKindResponse,
+	})
+	RegisterMessageType(MessageTypeOptions[MeterValuesNotificationPayload]{
+		Kind:      MessageKindNotification,
+		StationID: func(payload MeterValuesNotificationPayload) string { return payload.StationID },
+	})
+	RegisterMessageType(MessageTypeOptions[ConnectorListRequestPayload]{
+		Kind:         MessageKindRequest,
+		ResponseType: EventTypeConnectorListResponse,
+		StationID:    func(payload ConnectorListRequestPayload) string { return payload.StationID },
+	})
+	RegisterMessageType(MessageTypeOptions[ConnectorListResponsePayload]{
+		Kind: MessageKindResponse,
+	})
+	RegisterMessageType(MessageTypeOptions[BootNotificationPayload]{
+		Kind:         MessageKindRequest,
+		ResponseType: EventTypeBootNotificationResponse,
+		StationID:    func(payload BootNotificationPayload) string { return payload.StationID },
+	})
+	RegisterMessageType(MessageTypeOptions[BootNotificationResponsePayload]{
+		Kind: MessageKindResponse,
+	})
+	RegisterMessageType(MessageTypeOptions[HeartbeatPayload]{
+		Kind:         MessageKindRequest,
+		ResponseType: EventTypeHeartbeatResponse,
+		StationID:    func(payload HeartbeatPayload) string { return payload.StationID },
+	})
+	RegisterMessageType(MessageTypeOptions[HeartbeatResponsePayload]{
+		Kind: MessageKindResponse,
+	})
+	RegisterMessageType(MessageTypeOptions[StatusNotificationPayload]{
+		Kind:         MessageKindRequest,
+		ResponseType: EventTypeStatusNotificationResponse,
+		StationID:    func(payload StatusNotificationPayload) string { return payload.StationID },
+	})
+	RegisterMessageType(MessageTypeOptions[StatusNotificationResponsePayload]{
+		Kind: MessageKindResponse,
+	})
+	RegisterMessageType(MessageTypeOptions[StartTransactionPayload]{
+		Kind:         MessageKindRequest,
+		ResponseType: EventTypeStartTransactionResponse,
+		StationID:    func(payload StartTransactionPayload) string { return payload.StationID },
+	})
+	RegisterMessageType(MessageTypeOptions[StartTransactionResponsePayload]{
+		Kind: MessageKindResponse,
+	})
+	RegisterMessageType(MessageTypeOptions[StopTransactionPayload]{
+		Kind:         MessageKindRequest,
+		ResponseType: EventTypeStopTransactionResponse,
+		StationID:    func(payload StopTransactionPayload) string { return payload.StationID },
+	})
+	RegisterMessageType(MessageTypeOptions[StopTransactionResponsePayload]{
+		Kind: MessageKindResponse,
+	})
+	RegisterMessageType(MessageTypeOptions[AuthorizePayload]{
+		Kind:         MessageKindRequest,
+		ResponseType: EventTypeAuthorizeResponse,
+		StationID:    func(payload AuthorizePayload) string { return payload.StationID },
+	})
+	RegisterMessageType(MessageTypeOptions[AuthorizeResponsePayload]{
+		Kind: MessageKindResponse,
+	})
+	RegisterMessageType(MessageTypeOptions[CallErrorPayload]{
+		Kind: MessageKindError,
+	})
+}
+
+// RegisterMessageType registers the message type of the payload type, named by its MessageType method, replacing any
+// message type already registered with the name. It is meant to be called from init functions, before events are
+// decoded or handled.
+func RegisterMessageType[P Payload](options MessageTypeOptions[P]) {
+	var zero P
+	messageType := MessageType{
+		Name:              zero.MessageType(),
+		Kind:              options.Kind,
+		ResponseType:      options.ResponseType,
+		ExpectsNoResponse: options.ExpectsNoResponse,
+		decode: func(data []byte) (Payload, error) {
+			var payload P
+			if len(data) > 0 {
+				if err := json.Unmarshal(data, &payload); err != nil {
+					return nil, err
+				}
+			}
+			return payload, nil
+		},
+	}
+	if options.StationID != nil {
+		messageType.stationID = func(payload Payload) string {
+			typed, ok := payload.(P)
+			if !ok {
+				return ""
+			}
+			return options.StationID(typed)
+		}
+	}
+	if existing, ok := messageTypes[messageType.Name]; ok {
+		messageType.RequestType = existing.RequestType
+	}
+	messageTypes[messageType.Name] = messageType
+
+	// The response type knows the request type it answers, whichever of them is registered first.
+	if messageType.ResponseType != "" {
+		response := messageTypes[messageType.ResponseType]
+		response.RequestType = messageType.Name
+		messageTypes[messageType.ResponseType] = response
+	}
+}
+
+// LookupMessageType returns the registered message type with the name, or false if there isn't one.
+func LookupMessageType(name string) (MessageType, bool) {
+	messageType, ok := messageTypes[name]
+	if !ok || messageType.decode == nil {
+		return MessageType{}, false
+	}
+
+	return messageType, true
+}
+
+// MessageTypes returns the registered message types, ordered by name.
+func MessageTypes() []MessageType {
+	var registered []MessageType
+	for _, messageType := range messageTypes {
+		if messageType.decode != nil {
+			registered = append(registered, messageType)
+		}
+	}
+	sort.Slice(registered, func(i, j int) bool {
+		return registered[i].Name < registered[j].Name
+	})
+
+	return registered
+}
+
+// MessageTypeNames returns the names of the registered message types of the kind, ordered by name.
+func MessageTypeNames(kind MessageKind) []string {
+	var names []string
+	for _, messageType := range MessageTypes() {
+		if messageType.Kind == kind {
+			names = append(names, messageType.Name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pingRequestPayload and pingResponsePayload are the payloads of a message type registered by the tests.
+type pingRequestPayload struct {
+	StationID string `json:"stationId"`
+}
+
+type pingResponsePayload struct{}
+
+func (pingRequestPayload) MessageType() string  { return "PingRequest" }
+func (pingResponsePayload) MessageType() string { return "PingResponse" }
+
+func TestRegisterMessageType(t *testing.T) {
+	// arrange
+	t.Cleanup(func() {
+		delete(messageTypes, "PingRequest")
+		delete(messageTypes, "PingResponse")
+	})
+
+	// act
+	RegisterMessageType(MessageTypeOptions[pingRequestPayload]{
+		Kind:         MessageKindRequest,
+		ResponseType: "PingResponse",
+		StationID:    func(payload pingRequestPayload) string { return payload.StationID },
+	})
+	RegisterMessageType(MessageTypeOptions[pingResponsePayload]{
+		Kind: MessageKindResponse,
+	})
+
+	// assert
+	request, ok := LookupMessageType("PingRequest")
+	require.True(t, ok)
+	assert.Equal(t, MessageKindRequest, request.Kind)
+	assert.Equal(t, "PingResponse", request.ResponseType)
+	assert.Equal(t, "station-1", request.StationID(pingRequestPayload{StationID: "station-1"}))
+
+	response, ok := LookupMessageType("PingResponse")
+	require.True(t, ok)
+	assert.Equal(t, MessageKindResponse, response.Kind)
+	assert.Equal(t, "PingRequest", response.RequestType)
+	assert.Empty(t, response.StationID(pingResponsePayload{}))
+
+	assert.Contains(t, MessageTypeNames(MessageKindRequest), "PingRequest")
+
+	var event Event
+	require.NoError(t, json.Unmarshal([]byte(`{"messageType": "PingRequest", "payload": {"stationId": "station-1"}}`), &event))
+	assert.Equal(t, pingRequestPayload{StationID: "station-1"}, event.Payload)
+	assert.Equal(t, "station-1", event.PayloadStationID())
+}
+
+func TestRegisterMessageType_ResponseFirst(t *testing.T) {
+	// arrange
+	t.Cleanup(func() {
+		delete(messageTypes, "PingRequest")
+		delete(messageTypes, "PingResponse")
+	})
+
+	// act
+	RegisterMessageType(MessageTypeOptions[pingResponsePayload]{
+		Kind: MessageKindResponse,
+	})
+	RegisterMessageType(MessageTypeOptions[pingRequestPayload]{
+		Kind:         MessageKindRequest,
+		ResponseType: "PingResponse",
+	})
+
+	// assert
+	response, ok := LookupMessageType("PingResponse")
+	require.True(t, ok)
+	assert.Equal(t, "PingRequest", response.RequestType)
+}
+
+func TestRegisterMessageType_ExpectsNoResponse(t *testing.T) {
+	// arrange
+	t.Cleanup(func() {
+		delete(messageTypes, "PingRequest")
+	})
+
+	// act
+	RegisterMessageType(MessageTypeOptions[pingRequestPayload]{
+		Kind:              MessageKindRequest,
+		ExpectsNoResponse: true,
+	})
+
+	// assert
+	request, ok := LookupMessageType("PingRequest")
+	require.True(t, ok)
+	assert.True(t, request.ExpectsNoResponse)
+	heartbeat, ok := LookupMessageType(EventTypeHeartbeat)
+	require.True(t, ok)
+	assert.False(t, heartbeat.ExpectsNoResponse)
+}
+
+func TestLookupMessageType_Unregistered(t *testing.T) {
+	// arrange
+	t.Cleanup(func() {
+		delete(messageTypes, "PingRequest")
+		delete(messageTypes, "PingResponse")
+	})
+	// Registering a request names its response type, without registering it.
+	RegisterMessageType(MessageTypeOptions[pingRequestPayload]{
+		Kind:         MessageKindRequest,
+		ResponseType: "PingResponse",
+	})
+
+	// act
+	_, ok := LookupMessageType("PingResponse")
+
+	// assert
+	assert.False(t, ok)
+	assert.NotContains(t, MessageTypeNames(MessageKindResponse), "PingResponse")
+}
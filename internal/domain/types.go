@@ -10,13 +10,149 @@ type ChargingStation struct {
 	NumConnectors int         `json:"numConnectors"`
 	Connectors    []Connector `json:"connectors"`
 	UpdatedAt     time.Time   `json:"updatedAt"`
+	// Vendor, Model, SerialNumber and FirmwareVersion are as reported by the station's latest BootNotification.
+	Vendor          string `json:"vendor,omitempty"`
+	Model           string `json:"model,omitempty"`
+	SerialNumber    string `json:"serialNumber,omitempty"`
+	FirmwareVersion string `json:"firmwareVersion,omitempty"`
+	// LastHeartbeatAt is when the station last sent a Heartbeat, if it has.
+	LastHeartbeatAt *time.Time `json:"lastHeartbeatAt,omitempty"`
+	// HeartbeatInterval is the interval the station was told to send heartbeats at in response to its latest
+	// BootNotification, or the default interval if it wasn't told one.
+	HeartbeatInterval time.Duration `json:"heartbeatInterval,omitempty"`
+	// Status is whether the station is online, or empty if it has never sent a BootNotification or Heartbeat.
+	Status StationStatus `json:"status,omitempty"`
+	// ConnectorStatus is the status of the station as a whole, reported for connector 0, if it has reported one.
+	ConnectorStatus *ConnectorStatus `json:"connectorStatus,omitempty"`
 }
 
+// StationStatus is whether a station is connected to the central system, as judged by when it was last heard from.
+type StationStatus string
+
+const (
+	// StationOnline means that the station has sent a BootNotification or Heartbeat within two heartbeat intervals.
+	StationOnline StationStatus = "online"
+	// StationOffline means that the station has missed more than one heartbeat.
+	StationOffline StationStatus = "offline"
+)
+
 type Connector struct {
-	ID                int32     `json:"id"`
-	ChargingStationID string    `json:"chargingStationId"`
-	Reading           string    `json:"reading"`
-	UpdatedAt         time.Time `json:"updatedAt"`
+	ID                int32  `json:"id"`
+	ChargingStationID string `json:"chargingStationId"`
+	// Reading is the reading as reported by the station.
+	Reading string `json:"reading"`
+	// ReadingValue is the parsed reading, or nil if the reading couldn't be parsed.
+	ReadingValue *Reading  `json:"readingValue,omitempty"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	// Status is the status of the connector as reported by its latest StatusNotification, if it has reported one.
+	Status *ConnectorStatus `json:"status,omitempty"`
+}
+
+// ConnectorStatus is the status of a connector, as reported by its latest StatusNotification.
+type ConnectorStatus struct {
+	Status          ChargePointStatus    `json:"status"`
+	ErrorCode       ChargePointErrorCode `json:"errorCode"`
+	Info            string               `json:"info,omitempty"`
+	VendorID        string               `json:"vendorId,omitempty"`
+	VendorErrorCode string               `json:"vendorErrorCode,omitempty"`
+	// Since is when the connector entered the status with the error code, and UpdatedAt when it last reported them.
+	Since     time.Time `json:"since"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ConnectorStatusTransition is a change of a connector's status or error code, as reported by a StatusNotification.
+type ConnectorStatusTransition struct {
+	StationID string `json:"stationId"`
+	// ConnectorID is the ID of the connector, or 0 for the station as a whole.
+	ConnectorID int32 `json:"connectorId"`
+	// From is the status the connector was in before, or empty if it hadn't reported one.
+	From      ChargePointStatus    `json:"from,omitempty"`
+	To        ChargePointStatus    `json:"to"`
+	ErrorCode ChargePointErrorCode `json:"errorCode"`
+	// EventID is the ID of the StatusNotification event which reported the transition.
+	EventID    string    `json:"eventId"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// SessionCheck is the verdict on whether the energy a session delivered according to its transaction agrees with the
+// connector's meter values.
+type SessionCheck string
+
+const (
+	// SessionConsistent means that the energy measured from the meter values is within 1% of the transaction's.
+	SessionConsistent SessionCheck = "consistent"
+	// SessionInconsistent means that the energy measured from the meter values differs from the transaction's.
+	SessionInconsistent SessionCheck = "inconsistent"
+	// SessionUnchecked means that the session hasn't stopped, or there aren't enough meter values to check it against.
+	SessionUnchecked SessionCheck = "unchecked"
+)
+
+// Session is a charging session on a connector, from its StartTransaction to its StopTransaction.
+type Session struct {
+	StationID string `json:"stationId"`
+	// ConnectorID is the ID of the connector, or 0 if the session's StartTransaction hasn't been seen.
+	ConnectorID int32 `json:"connectorId"`
+	// TransactionID is the ID the central system assigned to the transaction, if its response has been seen.
+	TransactionID *int   `json:"transactionId,omitempty"`
+	IDTag         string `json:"idTag,omitempty"`
+	// IDTagStatus is the central system's verdict on the idTag in response to the StartTransaction, if seen.
+	IDTagStatus AuthorizationStatus `json:"idTagStatus,omitempty"`
+	// StartedAt and StoppedAt are when the session started and stopped according to the station, StoppedAt being nil
+	// if it hasn't stopped.
+	StartedAt time.Time     `json:"startedAt"`
+	StoppedAt *time.Time    `json:"stoppedAt,omitempty"`
+	Duration  time.Duration `json:"duration,omitempty"`
+	// MeterStart and MeterStop are the readings of the connector's meter in Wh when the session started and stopped,
+	// and Wh the energy delivered between them.
+	MeterStart int        `json:"meterStart"`
+	MeterStop  *int       `json:"meterStop,omitempty"`
+	Wh         *Decimal   `json:"wh,omitempty"`
+	StopReason StopReason `json:"stopReason,omitempty"`
+	// MeterValues is the energy delivered by the connector during the session, measured from its meter values, and
+	// Check whether it agrees with Wh.
+	MeterValues *EnergyDelivered `json:"meterValues,omitempty"`
+	Check       SessionCheck     `json:"check"`
+	// StartEventID and StopEventID are the IDs of the session's StartTransaction and StopTransaction events.
+	StartEventID string `json:"startEventId,omitempty"`
+	StopEventID  string `json:"stopEventId,omitempty"`
+}
+
+// IDTagUsage is how an idTag has been used, as named by the Authorize, StartTransaction and StopTransaction messages
+// of the stations it was presented at.
+type IDTagUsage struct {
+	IDTag string `json:"idTag"`
+	// StationIDs are the IDs of the stations the idTag was presented at, ordered by ID.
+	StationIDs []string `json:"stationIds"`
+	// LastSeenAt is when the idTag was last presented, and LastSeenStationID the station it was presented at.
+	LastSeenAt        time.Time `json:"lastSeenAt"`
+	LastSeenStationID string    `json:"lastSeenStationId"`
+	// LastStatus is the central system's latest verdict on the idTag, if it has given one.
+	LastStatus AuthorizationStatus `json:"lastStatus,omitempty"`
+}
+
+// StationAuthorizations counts the verdicts on a station's Authorize requests.
+type StationAuthorizations struct {
+	StationID string `json:"stationId"`
+	Total     int    `json:"total"`
+	Accepted  int    `json:"accepted"`
+	// Rejected is the number of authorizations which weren't accepted, and RejectedByStatus the number with each
+	// status other than Accepted.
+	Rejected         int                         `json:"rejected"`
+	RejectedByStatus map[AuthorizationStatus]int `json:"rejectedByStatus,omitempty"`
+	// Unanswered is the number of Authorize requests without a response.
+	Unanswered int `json:"unanswered"`
+}
+
+// RequestFailures counts a station's requests of a message type which were answered with an error.
+type RequestFailures struct {
+	StationID   string `json:"stationId"`
+	MessageType string `json:"messageType"`
+	// Requests is the number of the station's requests of the message type, and Failed the number of them which were
+	// answered with an error before any response.
+	Requests int `json:"requests"`
+	Failed   int `json:"failed"`
+	// FailedByErrorCode is the number of failed requests with each error code.
+	FailedByErrorCode map[CallErrorCode]int `json:"failedByErrorCode"`
 }
 
 type Store interface {
@@ -26,9 +162,388 @@ type Store interface {
 	GetChargingStations(ctx context.Context) ([]ChargingStation, error)
 }
 
-type Projection interface {
+// A projection answers queries about the charging stations from the events in an event source. Each feature of a
+// projection is its own interface, so that callers depend only on the features they use.
+
+// ChargingStationProjection projects the charging stations and their connectors.
+type ChargingStationProjection interface {
 	NumChargingStations(ctx context.Context) (int, error)
 	NumConnectors(ctx context.Context, stationID string) (int, error)
 	ChargingStation(ctx context.Context, stationID string) (ChargingStation, error)
 	ChargingStations(ctx context.Context) ([]ChargingStation, error)
 }
+
+// ConsistencyProjection judges whether the sources of each charging station's number of connectors agree.
+type ConsistencyProjection interface {
+	ConnectorConsistency(ctx context.Context, stationID string) (ConnectorConsistency, error)
+}
+
+// ReadingProjection projects the readings of the connectors' meters.
+type ReadingProjection interface {
+	// ConnectorReadings returns the readings of the connector which occurred in the window from the time, inclusive,
+	// to the time, exclusive, with the window unbounded at a zero time. The readings are ordered by when they
+	// occurred, and readings of the same value at the same time reported by more than one event are only returned once.
+	ConnectorReadings(ctx context.Context, stationID string, connectorID int32, from, to time.Time) ([]ConnectorReading, error)
+	// ReadingAnomalies returns the anomalies in the readings of each of the station's connectors, ordered by connector,
+	// then by when the anomalous readings occurred.
+	ReadingAnomalies(ctx context.Context, stationID string) ([]ReadingAnomaly, error)
+}
+
+// EnergyProjection projects the energy delivered by the connectors from their readings.
+type EnergyProjection interface {
+	// EnergyDelivered returns the energy delivered by the connector in the period from the time to the time, each
+	// unbounded if zero, measured from its meter readings. It returns ErrInvalidPeriod if the period ends before it starts.
+	EnergyDelivered(ctx context.Context, stationID string, connectorID int32, from, to time.Time) (EnergyDelivered, error)
+	// StationEnergyDelivered returns the energy delivered by each of the station's connectors in the period, and
+	// their total.
+	StationEnergyDelivered(ctx context.Context, stationID string, from, to time.Time) (StationEnergyDelivered, error)
+}
+
+// CorrelationProjection pairs requests with their responses by correlation ID.
+type CorrelationProjection interface {
+	// Correlations returns the requests and responses which haven't been paired by their correlation ID, with the age
+	// of each pending request measured at the instant, or at the latest event if it is zero. Requests which weren't
+	// answered within the deadline are reported as timed out instead, unless it is zero. It returns ErrInvalidDeadline
+	// if the deadline is negative.
+	Correlations(ctx context.Context, at time.Time, deadline time.Duration) (CorrelationReport, error)
+	// RequestLatencies returns the statistics of the time each station took to answer requests, overall and for each
+	// request message type, ordered by station. Responses which occurred before their request are left out.
+	RequestLatencies(ctx context.Context) ([]StationLatencies, error)
+	// Failures returns the number of each station's requests of each message type which were answered with an error,
+	// of the message types with at least one, ordered by station and message type.
+	Failures(ctx context.Context) ([]RequestFailures, error)
+}
+
+// ValidationProjection validates responses against the requests they answer.
+type ValidationProjection interface {
+	// ValidationLog returns the violations of responses against their requests selected by the filter, ordered by
+	// when the responses occurred.
+	ValidationLog(ctx context.Context, filter ValidationLogFilter) ([]ResponseViolation, error)
+}
+
+// StatusProjection projects the statuses the connectors report.
+type StatusProjection interface {
+	// ConnectorStatusTransitions returns the changes of status or error code of each of the station's connectors,
+	// including connector 0 for the station as a whole, ordered by when they occurred.
+	ConnectorStatusTransitions(ctx context.Context, stationID string) ([]ConnectorStatusTransition, error)
+}
+
+// SessionProjection projects the charging sessions.
+type SessionProjection interface {
+	// Sessions returns the station's charging sessions, from their StartTransaction and StopTransaction, ordered by
+	// connector, then by when they started, with the energy each delivered checked against the meter values.
+	Sessions(ctx context.Context, stationID string) ([]Session, error)
+}
+
+// AuthorizationProjection projects the use and authorization of idTags.
+type AuthorizationProjection interface {
+	// IDTags returns how each idTag has been used, from the Authorize, StartTransaction and StopTransaction messages
+	// naming it, ordered by idTag.
+	IDTags(ctx context.Context) ([]IDTagUsage, error)
+	// Authorizations returns the verdicts on each station's Authorize requests, ordered by station.
+	Authorizations(ctx context.Context) ([]StationAuthorizations, error)
+}
+
+// Projection is a projection with all of the features, which can also be viewed as of an instant.
+type Projection interface {
+	ChargingStationProjection
+	ConsistencyProjection
+	ReadingProjection
+	EnergyProjection
+	CorrelationProjection
+	ValidationProjection
+	StatusProjection
+	SessionProjection
+	AuthorizationProjection
+	// AsOf returns a view of the projection over the events which occurred at or before the instant.
+	AsOf(at time.Time) Projection
+}
+
+// ConnectorReading is a reading of a connector's meter, as reported by a meter values event.
+type ConnectorReading struct {
+	StationID   string `json:"stationId"`
+	ConnectorID int32  `json:"connectorId"`
+	// Reading is the reading as reported by the station.
+	Reading string `json:"reading"`
+	// ReadingValue is the parsed reading, or nil if the reading couldn't be parsed.
+	ReadingValue *Reading  `json:"readingValue,omitempty"`
+	OccurredAt   time.Time `json:"occurredAt"`
+	// MessageType is the message type of the event which reported the reading.
+	MessageType string `json:"messageType"`
+	// EventID is the ID of the event which reported the reading.
+	EventID string `json:"eventId"`
+}
+
+// ConnectorCountVerdict is the verdict on whether the sources of a charging station's number of connectors agree.
+type ConnectorCountVerdict string
+
+const (
+	// ConnectorCountConsistent means that all of the sources agree on the number of connectors.
+	ConnectorCountConsistent ConnectorCountVerdict = "consistent"
+	// ConnectorCountStale means that some sources disagree with the latest one, but are older than it, so are likely out of date.
+	ConnectorCountStale ConnectorCountVerdict = "stale"
+	// ConnectorCountConflicting means that the latest sources disagree with each other, or with the connectors seen in meter values.
+	ConnectorCountConflicting ConnectorCountVerdict = "conflicting"
+)
+
+// ConnectorCountClaim is the number of connectors claimed by the latest event of a message type.
+type ConnectorCountClaim struct {
+	MessageType   string    `json:"messageType"`
+	EventID       string    `json:"eventId"`
+	NumConnectors int       `json:"numConnectors"`
+	OccurredAt    time.Time `json:"occurredAt"`
+}
+
+// ConnectorConsistency reports whether the view of a charging station's number of connectors is consistent with each source.
+type ConnectorConsistency struct {
+	StationID string `json:"stationId"`
+	// NumConnectors is the current view of the number of connectors, as claimed by the latest source.
+	NumConnectors int                   `json:"numConnectors"`
+	Claims        []ConnectorCountClaim `json:"claims"`
+	// DeclaredNumConnectors is the number of connectors declared by the latest ConnectorListResponse, if any.
+	DeclaredNumConnectors *int `json:"declaredNumConnectors,omitempty"`
+	// MeterValueConnectorIDs are the IDs of the connectors seen in the latest meter values.
+	MeterValueConnectorIDs []int32 `json:"meterValueConnectorIds"`
+	// UndeclaredConnectorIDs are the IDs of the connectors seen in the latest meter values which exceed the declared number of connectors.
+	UndeclaredConnectorIDs []int32               `json:"undeclaredConnectorIds,omitempty"`
+	Verdict                ConnectorCountVerdict `json:"verdict"`
+}
+
+// EnergyConfidence is how the energy delivered in a period was measured, from the most to the least confident.
+type EnergyConfidence string
+
+const (
+	// EnergyExact means that there are readings at the boundaries of the period.
+	EnergyExact EnergyConfidence = "exact"
+	// EnergyInterpolated means that the reading at a boundary of the period was interpolated between the readings
+	// either side of it.
+	EnergyInterpolated EnergyConfidence = "interpolated"
+	// EnergyBracketed means that the reading at a boundary of the period was taken from the nearest reading inside the
+	// period, as there is no reading to interpolate with on the other side of it, so some energy may be missing.
+	EnergyBracketed EnergyConfidence = "bracketed"
+	// EnergyUncertain means that the meter fell from near the capacity of a register with as many digits, so it may
+	// have rolled over rather than been reset. The register's capacity isn't known, so the fall is measured as a
+	// reset, and the energy delivered up to the capacity may be missing.
+	EnergyUncertain EnergyConfidence = "uncertain"
+	// EnergyUnknown means that there are no readings in or around the period.
+	EnergyUnknown EnergyConfidence = "unknown"
+)
+
+// energyConfidenceRanks orders the confidences from the most to the least confident.
+var energyConfidenceRanks = map[EnergyConfidence]int{
+	EnergyExact:        0,
+	EnergyInterpolated: 1,
+	EnergyBracketed:    2,
+	EnergyUncertain:    3,
+	EnergyUnknown:      4,
+}
+
+// LeastConfident returns the less confident of the confidences.
+func (c EnergyConfidence) LeastConfident(other EnergyConfidence) EnergyConfidence {
+	if energyConfidenceRanks[other] > energyConfidenceRanks[c] {
+		return other
+	}
+
+	return c
+}
+
+// EnergyDelivered is the energy delivered by a connector in a period.
+type EnergyDelivered struct {
+	StationID   string    `json:"stationId"`
+	ConnectorID int32     `json:"connectorId"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	// Wh is the energy delivered in Wh.
+	Wh         Decimal          `json:"wh"`
+	Confidence EnergyConfidence `json:"confidence"`
+	// Resets is the number of times the meter went backwards in the period, each measured as a reset to zero, and
+	// PossibleRollovers the number of them which may instead have been the meter wrapping around to zero on reaching
+	// its capacity.
+	Resets            int `json:"resets"`
+	PossibleRollovers int `json:"possibleRollovers"`
+}
+
+// KWh returns the energy delivered in kWh.
+func (e EnergyDelivered) KWh() Decimal {
+	return e.Wh.shift(-3)
+}
+
+// StationEnergyDelivered is the energy delivered by a station's connectors in a period.
+type StationEnergyDelivered struct {
+	StationID string    `json:"stationId"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	// Wh is the total energy delivered by the connectors in Wh.
+	Wh Decimal `json:"wh"`
+	// Confidence is the least confident of the connectors' confidences.
+	Confidence EnergyConfidence  `json:"confidence"`
+	Connectors []EnergyDelivered `json:"connectors"`
+}
+
+// KWh returns the total energy delivered in kWh.
+func (e StationEnergyDelivered) KWh() Decimal {
+	return e.Wh.shift(-3)
+}
+
+// ReadingAnomalyKind is the kind of an anomaly in a connector's readings.
+type ReadingAnomalyKind string
+
+const (
+	// ReadingAnomalyDecrease means that a reading is lower than the reading before it, which a cumulative meter
+	// can't explain.
+	ReadingAnomalyDecrease ReadingAnomalyKind = "decrease"
+	// ReadingAnomalyReset means that a reading is lower than a tenth of the reading before it, so the meter is likely
+	// to have been reset to zero.
+	ReadingAnomalyReset ReadingAnomalyKind = "reset"
+	// ReadingAnomalyRollover means that a reading is lower than the reading before it, which was within a tenth of the
+	// capacity of a register with as many digits, so the meter may have wrapped around to zero. The register's
+	// capacity isn't known, so it may as well have been reset.
+	ReadingAnomalyRollover ReadingAnomalyKind = "rollover"
+	// ReadingAnomalyJump means that a reading is higher than the reading before it by more energy than a connector
+	// could plausibly have delivered in the time between them.
+	ReadingAnomalyJump ReadingAnomalyKind = "jump"
+	// ReadingAnomalyOutOfOrder means that a reading was received after a reading which occurred later.
+	ReadingAnomalyOutOfOrder ReadingAnomalyKind = "outOfOrder"
+)
+
+// ReadingAnomaly is an anomaly in a connector's readings.
+type ReadingAnomaly struct {
+	StationID   string             `json:"stationId"`
+	ConnectorID int32              `json:"connectorId"`
+	Kind        ReadingAnomalyKind `json:"kind"`
+	// Reading is the anomalous reading, and Previous the reading it is anomalous in relation to: the one before it,
+	// or for an out of order reading, the later reading which was received before it.
+	Reading  ConnectorReading `json:"reading"`
+	Previous ConnectorReading `json:"previous"`
+	// Detail describes the anomaly.
+	Detail string `json:"detail"`
+}
+
+// PendingRequest is a request which hasn't been answered.
+type PendingRequest struct {
+	StationID string `json:"stationId"`
+	Request   Event  `json:"request"`
+	// Age is the time since the request occurred.
+	Age time.Duration `json:"age"`
+}
+
+// TimedOutRequest is a request which wasn't answered within the deadline.
+type TimedOutRequest struct {
+	StationID string `json:"stationId"`
+	Request   Event  `json:"request"`
+	// Response is the first response or error the request was answered with, if it was answered after the deadline.
+	Response *Event `json:"response,omitempty"`
+	// Waited is the time from the request to its first response or error, or to the instant of the report if there
+	// isn't one.
+	Waited time.Duration `json:"waited"`
+}
+
+// MultipleResponses is a request which was answered more than once, by responses which aren't retransmissions of
+// each other.
+type MultipleResponses struct {
+	StationID     string  `json:"stationId"`
+	CorrelationID string  `json:"correlationId"`
+	Request       Event   `json:"request"`
+	Responses     []Event `json:"responses"`
+}
+
+// CorrelationReport reports the requests and responses which haven't been paired by their correlation ID.
+type CorrelationReport struct {
+	// At is the instant the ages of pending requests are measured at.
+	At       time.Time     `json:"at"`
+	Deadline time.Duration `json:"deadline"`
+	// Pending are the requests answered with neither a response nor an error which are still within the deadline,
+	// ordered by when they occurred, and TimedOut the requests not answered within the deadline, whether still
+	// unanswered or answered late. A request is in at most one of them. Requests of a message type registered as
+	// expecting no response are in neither.
+	Pending  []PendingRequest  `json:"pending"`
+	TimedOut []TimedOutRequest `json:"timedOut"`
+	// OrphanResponses are the responses without a request of the message type they answer, and the errors without a
+	// request, ordered by when they occurred.
+	OrphanResponses   []Event             `json:"orphanResponses"`
+	MultipleResponses []MultipleResponses `json:"multipleResponses"`
+}
+
+// LatencyStats are the statistics of the time taken to answer requests.
+type LatencyStats struct {
+	// MessageType is the message type of the requests, or empty for requests of all message types.
+	MessageType string        `json:"messageType,omitempty"`
+	Count       int           `json:"count"`
+	Min         time.Duration `json:"min"`
+	P50         time.Duration `json:"p50"`
+	P95         time.Duration `json:"p95"`
+	Max         time.Duration `json:"max"`
+}
+
+// StationLatencies are the statistics of the time a station took to answer requests.
+type StationLatencies struct {
+	StationID string `json:"stationId"`
+	// All are the statistics of the requests of all message types, and ByMessageType those of each message type,
+	// ordered by message type.
+	All           LatencyStats   `json:"all"`
+	ByMessageType []LatencyStats `json:"byMessageType"`
+}
+
+// ResponseViolationKind is the kind of a violation of a response against its request.
+type ResponseViolationKind string
+
+const (
+	// ResponseViolationUnrequestedConnector means that a MeterValuesResponse has a meter value for a connector other
+	// than the one its request asked for.
+	ResponseViolationUnrequestedConnector ResponseViolationKind = "unrequestedConnector"
+	// ResponseViolationUnknownStation means that the request doesn't name the station it was sent to, so the response
+	// can't be attributed to a station.
+	ResponseViolationUnknownStation ResponseViolationKind = "unknownStation"
+	// ResponseViolationBeforeRequest means that a response occurred before its request.
+	ResponseViolationBeforeRequest ResponseViolationKind = "responseBeforeRequest"
+)
+
+// ResponseViolation is a violation of a response against the request it answers.
+type ResponseViolation struct {
+	StationID     string                `json:"stationId"`
+	CorrelationID string                `json:"correlationId"`
+	Kind          ResponseViolationKind `json:"kind"`
+	Request       Event                 `json:"request"`
+	Response      Event                 `json:"response"`
+	// ConnectorID is the ID of the unrequested connector, for violations of that kind.
+	ConnectorID int32 `json:"connectorId,omitempty"`
+	// Detail describes the violation.
+	Detail string `json:"detail"`
+}
+
+// ValidationLogFilter selects the violations returned by Projection.ValidationLog. Each field which is set narrows the
+// selection, so the zero value selects every violation.
+type ValidationLogFilter struct {
+	// StationID selects the violations of responses from the station.
+	StationID string
+	// Kinds selects the violations of any of the kinds.
+	Kinds []ResponseViolationKind
+	// OccurredFrom selects the violations of responses which occurred at or after the time.
+	OccurredFrom time.Time
+	// OccurredTo selects the violations of responses which occurred before the time.
+	OccurredTo time.Time
+}
+
+// Matches reports whether the violation is selected by the filter.
+func (f ValidationLogFilter) Matches(violation ResponseViolation) bool {
+	if f.StationID != "" && violation.StationID != f.StationID {
+		return false
+	}
+	if len(f.Kinds) > 0 {
+		var ok bool
+		for _, kind := range f.Kinds {
+			ok = ok || kind == violation.Kind
+		}
+		if !ok {
+			return false
+		}
+	}
+	if !f.OccurredFrom.IsZero() && violation.Response.OccurredAt.Before(f.OccurredFrom) {
+		return false
+	}
+	if !f.OccurredTo.IsZero() && !violation.Response.OccurredAt.Before(f.OccurredTo) {
+		return false
+	}
+
+	return true
+}
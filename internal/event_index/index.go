@@ -0,0 +1,161 @@
+// Package eventindex keeps the IDs of an event source's events in the event source's ordering, so that a query can
+// find where its page starts by binary search and stop once the page is full, instead of sorting and scanning every
+// event which may match it.
+package eventindex
+
+import (
+	"sort"
+	"time"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// maxChunkSize is the number of IDs a chunk of an index holds before it is split in two.
+const maxChunkSize = 512
+
+// entry is an event's ID with the fields it is ordered by.
+type entry struct {
+	id         string
+	occurredAt time.Time
+	sequence   uint64
+}
+
+// Index is a list of event IDs in an event ordering. It is split into chunks, so that inserting an event which
+// doesn't come after every other event only moves the IDs of its chunk, and appending one takes constant time.
+type Index struct {
+	ordering domain.EventOrdering
+	chunks   [][]entry
+	len      int
+}
+
+// NewIndex returns an empty index in the ordering.
+func NewIndex(ordering domain.EventOrdering) *Index {
+	return &Index{ordering: ordering}
+}
+
+// Len returns the number of events in the index. A nil index is empty.
+func (ix *Index) Len() int {
+	if ix == nil {
+		return 0
+	}
+
+	return ix.len
+}
+
+// Insert adds the event at its position in the ordering.
+func (ix *Index) Insert(event domain.Event) {
+	e := entry{id: event.ID, occurredAt: event.OccurredAt, sequence: event.Sequence}
+	ix.len++
+	if len(ix.chunks) == 0 {
+		ix.chunks = [][]entry{{e}}
+		return
+	}
+
+	// The event goes in the first chunk with an event which comes after it, or at the end of the last chunk.
+	c := sort.Search(len(ix.chunks), func(i int) bool {
+		chunk := ix.chunks[i]
+		return ix.before(e, chunk[len(chunk)-1])
+	})
+	if c == len(ix.chunks) {
+		c--
+	}
+	chunk := ix.chunks[c]
+	i := sort.Search(len(chunk), func(i int) bool {
+		return ix.before(e, chunk[i])
+	})
+	chunk = append(chunk, entry{})
+	copy(chunk[i+1:], chunk[i:])
+	chunk[i] = e
+	ix.chunks[c] = chunk
+	if len(chunk) <= maxChunkSize {
+		return
+	}
+
+	half := len(chunk) / 2
+	right := append(make([]entry, 0, maxChunkSize), chunk[half:]...)
+	ix.chunks[c] = chunk[:half]
+	ix.chunks = append(ix.chunks, nil)
+	copy(ix.chunks[c+2:], ix.chunks[c+1:])
+	ix.chunks[c+1] = right
+}
+
+// IDs returns the IDs of the events in the index, in its ordering.
+func (ix *Index) IDs() []string {
+	if ix == nil {
+		return nil
+	}
+
+	ids := make([]string, 0, ix.len)
+	for _, chunk := range ix.chunks {
+		for _, e := range chunk {
+			ids = append(ids, e.id)
+		}
+	}
+
+	return ids
+}
+
+// before reports whether the entry comes before the other entry in the index's ordering.
+func (ix *Index) before(e, other entry) bool {
+	if ix.ordering == domain.OrderByOccurredAt && !e.occurredAt.Equal(other.occurredAt) {
+		return e.occurredAt.Before(other.occurredAt)
+	}
+
+	return e.sequence < other.sequence
+}
+
+// position is the position of an entry in an index, as the chunk it is in and its offset in the chunk.
+type position struct {
+	chunk  int
+	offset int
+}
+
+// search returns the position of the first entry for which f is true, where f is false for every entry before some
+// position in the index and true from it on, or the end of the index if there is none.
+func (ix *Index) search(f func(e entry) bool) position {
+	c := sort.Search(len(ix.chunks), func(i int) bool {
+		chunk := ix.chunks[i]
+		return f(chunk[len(chunk)-1])
+	})
+	if c == len(ix.chunks) {
+		return position{chunk: c}
+	}
+	chunk := ix.chunks[c]
+
+	return position{chunk: c, offset: sort.Search(len(chunk), func(i int) bool {
+		return f(chunk[i])
+	})}
+}
+
+// rank returns the number of entries before the position.
+func (ix *Index) rank(p position) int {
+	n := p.offset
+	for _, chunk := range ix.chunks[:p.chunk] {
+		n += len(chunk)
+	}
+
+	return n
+}
+
+// iterator reads an index's entries in order from a position.
+type iterator struct {
+	ix *Index
+	p  position
+}
+
+// peek returns the entry at the iterator's position, or false if it is at the end of the index.
+func (it *iterator) peek() (entry, bool) {
+	for it.p.chunk < len(it.ix.chunks) && it.p.offset >= len(it.ix.chunks[it.p.chunk]) {
+		it.p = position{chunk: it.p.chunk + 1}
+	}
+	if it.p.chunk >= len(it.ix.chunks) {
+		return entry{}, false
+	}
+
+	return it.ix.chunks[it.p.chunk][it.p.offset], true
+}
+
+// next moves the iterator past the entry at its position.
+func (it *iterator) next() {
+	it.p.offset++
+}
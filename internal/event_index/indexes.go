@@ -0,0 +1,222 @@
+package eventindex
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// Indexes are the indexes of an event source's events by the fields they can be queried by, each in the event
+// source's ordering.
+type Indexes struct {
+	ordering        domain.EventOrdering
+	all             *Index
+	byCorrelationID map[string]*Index
+	byMessageType   map[string]*Index
+	byStationID     map[string]*Index
+	// byOccurredAt orders the events by when they occurred, to select a time window from if the event source is
+	// ordered by sequence. Otherwise all does.
+	byOccurredAt *Index
+}
+
+// NewIndexes returns empty indexes in the ordering.
+func NewIndexes(ordering domain.EventOrdering) *Indexes {
+	x := &Indexes{
+		ordering:        ordering,
+		all:             NewIndex(ordering),
+		byCorrelationID: make(map[string]*Index),
+		byMessageType:   make(map[string]*Index),
+		byStationID:     make(map[string]*Index),
+	}
+	if ordering == domain.OrderByOccurredAt {
+		x.byOccurredAt = x.all
+	} else {
+		x.byOccurredAt = NewIndex(domain.OrderByOccurredAt)
+	}
+
+	return x
+}
+
+// Add indexes the event, including under its station if it is attributed to one.
+func (x *Indexes) Add(event domain.Event) {
+	x.all.Insert(event)
+	if x.byOccurredAt != x.all {
+		x.byOccurredAt.Insert(event)
+	}
+	insertInto(x.byCorrelationID, event.CorrelationID, event, x.ordering)
+	insertInto(x.byMessageType, event.MessageType, event, x.ordering)
+	if event.StationID != "" {
+		x.AddToStation(event)
+	}
+}
+
+// AddToStation indexes the event under the station it is attributed to, for events which are attributed after they
+// were added.
+func (x *Indexes) AddToStation(event domain.Event) {
+	insertInto(x.byStationID, event.StationID, event, x.ordering)
+}
+
+// insertInto inserts the event into the index under the key, creating the index if there isn't one.
+func insertInto(indexes map[string]*Index, key string, event domain.Event, ordering domain.EventOrdering) {
+	ix, ok := indexes[key]
+	if !ok {
+		ix = NewIndex(ordering)
+		indexes[key] = ix
+	}
+	ix.Insert(event)
+}
+
+// All returns the IDs of every event, in the ordering.
+func (x *Indexes) All() []string {
+	return x.all.IDs()
+}
+
+// ByCorrelationID returns the IDs of the events with the correlation ID, in the ordering.
+func (x *Indexes) ByCorrelationID(correlationID string) []string {
+	return x.byCorrelationID[correlationID].IDs()
+}
+
+// Query returns the page of the events selected by the filter, looking each event up by its ID. It reads the smallest
+// of the indexes which apply to the filter from the position of the filter's cursor, found by binary search, until
+// the page is full.
+func (x *Indexes) Query(filter domain.Filter, lookup func(id string) domain.Event) (domain.EventPage, error) {
+	if filter.Limit < 0 {
+		return domain.EventPage{}, fmt.Errorf("limit %d: %w", filter.Limit, domain.ErrInvalidFilter)
+	}
+	var cursor *domain.Cursor
+	if filter.Cursor != "" {
+		c, err := domain.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return domain.EventPage{}, err
+		}
+		cursor = &c
+	}
+
+	candidates, size := []*Index{x.all}, x.all.Len()
+	narrow := func(indexes ...*Index) {
+		n := 0
+		var nonEmpty []*Index
+		for _, ix := range indexes {
+			if ix.Len() > 0 {
+				n += ix.Len()
+				nonEmpty = append(nonEmpty, ix)
+			}
+		}
+		if n < size {
+			candidates, size = nonEmpty, n
+		}
+	}
+	if filter.CorrelationID != "" {
+		narrow(x.byCorrelationID[filter.CorrelationID])
+	}
+	if filter.StationID != "" {
+		narrow(x.byStationID[filter.StationID])
+	}
+	if len(filter.MessageTypes) > 0 {
+		var indexes []*Index
+		seen := make(map[string]bool)
+		for _, messageType := range filter.MessageTypes {
+			if !seen[messageType] {
+				seen[messageType] = true
+				indexes = append(indexes, x.byMessageType[messageType])
+			}
+		}
+		narrow(indexes...)
+	}
+
+	// A time window can't be read from an index in sequence order, so if it is the smallest selection, its events are
+	// collected and sorted.
+	if x.ordering != domain.OrderByOccurredAt && (!filter.OccurredFrom.IsZero() || !filter.OccurredTo.IsZero()) {
+		from, to := x.window(filter)
+		if n := x.byOccurredAt.rank(to) - x.byOccurredAt.rank(from); n < size {
+			var events []domain.Event
+			it := &iterator{ix: x.byOccurredAt, p: from}
+			for e, ok := it.peek(); ok && it.p != to; e, ok = it.peek() {
+				if event := lookup(e.id); filter.Matches(event) {
+					events = append(events, event)
+				}
+				it.next()
+			}
+			return domain.PageEvents(events, filter, x.ordering)
+		}
+	}
+
+	return x.page(candidates, filter, cursor, lookup)
+}
+
+// window returns the positions in the index by when the events occurred of the first events which didn't occur
+// before the start and the end of the filter's window.
+func (x *Indexes) window(filter domain.Filter) (position, position) {
+	search := func(t time.Time) position {
+		if t.IsZero() {
+			return position{}
+		}
+		return x.byOccurredAt.search(func(e entry) bool {
+			return !e.occurredAt.Before(t)
+		})
+	}
+	from := search(filter.OccurredFrom)
+	to := position{chunk: len(x.byOccurredAt.chunks)}
+	if !filter.OccurredTo.IsZero() {
+		to = search(filter.OccurredTo)
+	}
+	if x.byOccurredAt.rank(from) > x.byOccurredAt.rank(to) {
+		from = to
+	}
+
+	return from, to
+}
+
+// page reads the page of the events selected by the filter from the indexes, which between them hold each event the
+// filter may select once, merging them in the ordering.
+func (x *Indexes) page(indexes []*Index, filter domain.Filter, cursor *domain.Cursor, lookup func(id string) domain.Event) (domain.EventPage, error) {
+	byOccurredAt := x.ordering == domain.OrderByOccurredAt
+	start := func(e entry) bool {
+		if cursor != nil && !cursor.Precedes(domain.Event{OccurredAt: e.occurredAt, Sequence: e.sequence}, x.ordering) {
+			return false
+		}
+		// Events are ordered by when they occurred, so the window starts at a position too.
+		return !byOccurredAt || filter.OccurredFrom.IsZero() || !e.occurredAt.Before(filter.OccurredFrom)
+	}
+	iterators := make([]*iterator, 0, len(indexes))
+	for _, ix := range indexes {
+		iterators = append(iterators, &iterator{ix: ix, p: ix.search(start)})
+	}
+
+	var events []domain.Event
+	for filter.Limit == 0 || len(events) <= filter.Limit {
+		var earliest *iterator
+		var e entry
+		for _, it := range iterators {
+			if next, ok := it.peek(); ok && (earliest == nil || x.all.before(next, e)) {
+				earliest, e = it, next
+			}
+		}
+		if earliest == nil {
+			break
+		}
+		if byOccurredAt && !filter.OccurredTo.IsZero() && !e.occurredAt.Before(filter.OccurredTo) {
+			break
+		}
+		earliest.next()
+
+		if event := lookup(e.id); filter.Matches(event) {
+			events = append(events, event)
+		}
+	}
+
+	if filter.Limit == 0 || len(events) <= filter.Limit {
+		return domain.EventPage{Events: events}, nil
+	}
+	events = events[:filter.Limit]
+	nextCursor, err := domain.EncodeCursor(events[len(events)-1])
+	if err != nil {
+		return domain.EventPage{}, err
+	}
+
+	return domain.EventPage{
+		Events:     events,
+		NextCursor: nextCursor,
+	}, nil
+}
@@ -0,0 +1,153 @@
+package eventindex
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+func TestIndexes_Query(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	messageTypes := []string{
+		domain.EventTypeHeartbeat,
+		domain.EventTypeMeterValuesRequest,
+		domain.EventTypeMeterValuesResponse,
+		domain.EventTypeAuthorize,
+	}
+	// Enough events to split the chunks of the indexes, with times out of sequence so that the index by when they
+	// occurred is inserted into throughout.
+	var events []domain.Event
+	for i := 0; i < 3000; i++ {
+		events = append(events, domain.Event{
+			ID:            fmt.Sprintf("event-%d", i),
+			CorrelationID: fmt.Sprintf("correlation-%d", i%100),
+			MessageType:   messageTypes[i%len(messageTypes)],
+			OccurredAt:    start.Add(time.Duration(i*7919%1000) * time.Second),
+			StationID:     fmt.Sprintf("station-%d", i%3),
+			Sequence:      uint64(i + 1),
+		})
+	}
+
+	tests := []struct {
+		name   string
+		filter domain.Filter
+	}{
+		{
+			name: "all",
+		},
+		{
+			name:   "by correlation ID",
+			filter: domain.Filter{CorrelationID: "correlation-7"},
+		},
+		{
+			name:   "by station",
+			filter: domain.Filter{StationID: "station-1"},
+		},
+		{
+			name:   "by message types",
+			filter: domain.Filter{MessageTypes: []string{domain.EventTypeMeterValuesResponse, domain.EventTypeHeartbeat}},
+		},
+		{
+			name: "by window",
+			filter: domain.Filter{
+				OccurredFrom: start.Add(100 * time.Second),
+				OccurredTo:   start.Add(150 * time.Second),
+			},
+		},
+		{
+			name: "by station and window",
+			filter: domain.Filter{
+				StationID:    "station-2",
+				OccurredFrom: start.Add(500 * time.Second),
+			},
+		},
+		{
+			name:   "no matches",
+			filter: domain.Filter{CorrelationID: "correlation-unknown"},
+		},
+	}
+
+	for orderingName, ordering := range map[string]domain.EventOrdering{
+		"by sequence":    domain.OrderBySequence,
+		"by occurred at": domain.OrderByOccurredAt,
+	} {
+		// arrange
+		x := NewIndexes(ordering)
+		eventsByID := make(map[string]domain.Event)
+		for _, event := range events {
+			x.Add(event)
+			eventsByID[event.ID] = event
+		}
+		lookup := func(id string) domain.Event {
+			return eventsByID[id]
+		}
+
+		for _, tt := range tests {
+			t.Run(orderingName+"/"+tt.name, func(t *testing.T) {
+				var matching []domain.Event
+				for _, event := range events {
+					if tt.filter.Matches(event) {
+						matching = append(matching, event)
+					}
+				}
+				want, err := domain.PageEvents(matching, tt.filter, ordering)
+				require.NoError(t, err)
+
+				// act
+				var got []domain.Event
+				filter := tt.filter
+				filter.Limit = 50
+				for {
+					page, err := x.Query(filter, lookup)
+					require.NoError(t, err)
+					got = append(got, page.Events...)
+					if page.NextCursor == "" {
+						break
+					}
+					filter.Cursor = page.NextCursor
+				}
+
+				// assert
+				assert.Equal(t, want.Events, got)
+			})
+		}
+	}
+}
+
+func TestIndexes_Query_InvalidFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  domain.Filter
+		wantErr error
+	}{
+		{
+			name:    "negative limit",
+			filter:  domain.Filter{Limit: -1},
+			wantErr: domain.ErrInvalidFilter,
+		},
+		{
+			name:    "invalid cursor",
+			filter:  domain.Filter{Cursor: "not a cursor"},
+			wantErr: domain.ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			x := NewIndexes(domain.OrderBySequence)
+
+			// act
+			_, err := x.Query(tt.filter, func(id string) domain.Event {
+				return domain.Event{}
+			})
+
+			// assert
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
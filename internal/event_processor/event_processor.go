@@ -2,6 +2,7 @@ package processor
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/zucchinho/ocpp/internal/domain"
 )
@@ -19,7 +20,29 @@ func NewEventProcessor(
 }
 
 func (ep *eventProcessor) ProcessEvent(ctx context.Context, event domain.Event) error {
-	ep.eventSource.Create(ctx, event)
+	if err := validateEvent(event); err != nil {
+		return fmt.Errorf("validate event: %w", err)
+	}
+
+	if _, err := ep.eventSource.Create(ctx, event); err != nil {
+		return fmt.Errorf("create event: %w", err)
+	}
+
+	return nil
+}
+
+// validateEvent checks that the event's message type is registered, and that its payload is valid if it can be
+// validated, e.g. that the readings of a meter values event can be parsed, so that only events whose readings can be
+// summed and compared are created.
+func validateEvent(event domain.Event) error {
+	payload, err := domain.EventPayload(event)
+	if err != nil {
+		return fmt.Errorf("event payload: %w", err)
+	}
+
+	if validator, ok := payload.(domain.Validator); ok {
+		return validator.Validate()
+	}
 
 	return nil
 }
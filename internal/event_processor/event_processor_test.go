@@ -0,0 +1,209 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zucchinho/ocpp/internal/domain"
+	"github.com/zucchinho/ocpp/internal/domain/mock"
+)
+
+func TestProcessEvent(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	mockEventSource := mock.NewMockEventSource(ctrl)
+	ep := NewEventProcessor(mockEventSource)
+	event := domain.Event{
+		MessageID:     "message-1",
+		CorrelationID: "correlation-1",
+		MessageType:   domain.EventTypeConnectorListRequest,
+	}
+
+	mockEventSource.EXPECT().Create(gomock.Any(), event).Return("event-1", nil)
+
+	// act
+	err := ep.ProcessEvent(context.Background(), event)
+
+	// assert
+	assert.NoError(t, err)
+}
+
+func TestProcessEvent_CreateError(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	mockEventSource := mock.NewMockEventSource(ctrl)
+	ep := NewEventProcessor(mockEventSource)
+	event := domain.Event{
+		MessageID:     "message-1",
+		CorrelationID: "correlation-1",
+		MessageType:   domain.EventTypeConnectorListRequest,
+	}
+
+	mockEventSource.EXPECT().Create(gomock.Any(), event).Return("", domain.ErrDuplicateConflict)
+
+	// act
+	err := ep.ProcessEvent(context.Background(), event)
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrDuplicateConflict)
+}
+
+func TestProcessEvent_InvalidReading(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	mockEventSource := mock.NewMockEventSource(ctrl)
+	ep := NewEventProcessor(mockEventSource)
+	event := domain.Event{
+		MessageID:     "message-1",
+		CorrelationID: "correlation-1",
+		MessageType:   domain.EventTypeMeterValuesNotification,
+		Payload: domain.MeterValuesNotificationPayload{
+			StationID: "station-1",
+			MeterValues: []domain.MeterValue{
+				domain.NewMeterValue(1, "12.5 kWh"),
+				domain.NewMeterValue(2, "twelve"),
+			},
+		},
+	}
+
+	// act
+	err := ep.ProcessEvent(context.Background(), event)
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrInvalidReading)
+	var validationError *domain.ValidationError
+	if assert.ErrorAs(t, err, &validationError) {
+		assert.Equal(t, "meterValues[1].reading", validationError.Field)
+	}
+}
+
+func TestProcessEvent_NegativeHeartbeatInterval(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	mockEventSource := mock.NewMockEventSource(ctrl)
+	ep := NewEventProcessor(mockEventSource)
+	event := domain.Event{
+		MessageID:     "message-2",
+		CorrelationID: "correlation-1",
+		MessageType:   domain.EventTypeBootNotificationResponse,
+		Payload:       domain.BootNotificationResponsePayload{Status: domain.RegistrationAccepted, Interval: -1},
+	}
+
+	// act
+	err := ep.ProcessEvent(context.Background(), event)
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrInvalidInterval)
+}
+
+func TestProcessEvent_InvalidStatusNotification(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	mockEventSource := mock.NewMockEventSource(ctrl)
+	ep := NewEventProcessor(mockEventSource)
+	event := domain.Event{
+		MessageID:     "message-1",
+		CorrelationID: "correlation-1",
+		MessageType:   domain.EventTypeStatusNotification,
+		Payload: domain.StatusNotificationPayload{
+			StationID:   "station-1",
+			ConnectorID: -1,
+			Status:      "Sleeping",
+			ErrorCode:   domain.NoError,
+		},
+	}
+
+	// act
+	err := ep.ProcessEvent(context.Background(), event)
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrInvalidConnectorID)
+	assert.ErrorIs(t, err, domain.ErrInvalidStatus)
+}
+
+func TestProcessEvent_InvalidStartTransaction(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	mockEventSource := mock.NewMockEventSource(ctrl)
+	ep := NewEventProcessor(mockEventSource)
+	event := domain.Event{
+		MessageID:     "message-1",
+		CorrelationID: "correlation-1",
+		MessageType:   domain.EventTypeStartTransaction,
+		Payload: domain.StartTransactionPayload{
+			StationID:   "station-1",
+			ConnectorID: 0,
+			IDTag:       "tag-1",
+			MeterStart:  -1,
+		},
+	}
+
+	// act
+	err := ep.ProcessEvent(context.Background(), event)
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrInvalidConnectorID)
+	var validationError *domain.ValidationError
+	if assert.ErrorAs(t, err, &validationError) {
+		assert.Equal(t, "connectorId", validationError.Field)
+	}
+	assert.ErrorIs(t, err, domain.ErrInvalidReading)
+}
+
+func TestProcessEvent_AuthorizeWithoutIDTag(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	mockEventSource := mock.NewMockEventSource(ctrl)
+	ep := NewEventProcessor(mockEventSource)
+	event := domain.Event{
+		MessageID:     "message-1",
+		CorrelationID: "correlation-1",
+		MessageType:   domain.EventTypeAuthorize,
+		Payload:       domain.AuthorizePayload{StationID: "station-1"},
+	}
+
+	// act
+	err := ep.ProcessEvent(context.Background(), event)
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrMissingIDTag)
+}
+
+func TestProcessEvent_CallErrorWithoutErrorCode(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	mockEventSource := mock.NewMockEventSource(ctrl)
+	ep := NewEventProcessor(mockEventSource)
+	event := domain.Event{
+		MessageID:     "message-2",
+		CorrelationID: "correlation-1",
+		MessageType:   domain.EventTypeCallError,
+		Payload:       domain.CallErrorPayload{ErrorDescription: "something went wrong"},
+	}
+
+	// act
+	err := ep.ProcessEvent(context.Background(), event)
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrMissingErrorCode)
+}
+
+func TestProcessEvent_UnknownMessageType(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	mockEventSource := mock.NewMockEventSource(ctrl)
+	ep := NewEventProcessor(mockEventSource)
+	event := domain.Event{
+		MessageID:     "message-1",
+		CorrelationID: "correlation-1",
+		MessageType:   "Unknown",
+	}
+
+	// act
+	err := ep.ProcessEvent(context.Background(), event)
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrUnknownMessageType)
+}
@@ -0,0 +1,768 @@
+// Package eventsourceconformance contains the tests which every domain.EventSource implementation must pass.
+package eventsourceconformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// Run runs the conformance tests against event sources created by newEventSource with the given ordering. Each test
+// gets a new, empty event source.
+func Run(t *testing.T, newEventSource func(t *testing.T, ordering domain.EventOrdering) domain.EventSource) {
+	t.Run("Create_NoID", func(t *testing.T) {
+		testCreateNoID(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Create_NoID_GeneratesUniqueIDs", func(t *testing.T) {
+		testCreateNoIDGeneratesUniqueIDs(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Create_AlreadyExists", func(t *testing.T) {
+		testCreateAlreadyExists(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Create_WithID", func(t *testing.T) {
+		testCreateWithID(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Get", func(t *testing.T) {
+		testGet(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Get_NotFound", func(t *testing.T) {
+		testGetNotFound(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("GetByCorrelationID", func(t *testing.T) {
+		testGetByCorrelationID(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("GetAll", func(t *testing.T) {
+		testGetAll(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Subscribe", func(t *testing.T) {
+		testSubscribe(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Subscribe_HandlerError", func(t *testing.T) {
+		testSubscribeHandlerError(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Ordering_Sequence", func(t *testing.T) {
+		testOrderingSequence(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Ordering_OccurredAt", func(t *testing.T) {
+		testOrderingOccurredAt(t, newEventSource(t, domain.OrderByOccurredAt))
+	})
+	t.Run("Create_Duplicate", func(t *testing.T) {
+		testCreateDuplicate(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Create_DuplicateReceivedLater", func(t *testing.T) {
+		testCreateDuplicateReceivedLater(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Create_DuplicateConflict", func(t *testing.T) {
+		testCreateDuplicateConflict(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Create_SameMessageIDDifferentCorrelationID", func(t *testing.T) {
+		testCreateSameMessageIDDifferentCorrelationID(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Query", func(t *testing.T) {
+		testQuery(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Query_Pagination_Sequence", func(t *testing.T) {
+		testQueryPagination(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("Query_Pagination_OccurredAt", func(t *testing.T) {
+		testQueryPagination(t, newEventSource(t, domain.OrderByOccurredAt))
+	})
+	t.Run("Query_InvalidCursor", func(t *testing.T) {
+		testQueryInvalidCursor(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("StationID", func(t *testing.T) {
+		testStationID(t, newEventSource(t, domain.OrderBySequence))
+	})
+	t.Run("StationID_ResponseBeforeRequest", func(t *testing.T) {
+		testStationIDResponseBeforeRequest(t, newEventSource(t, domain.OrderBySequence))
+	})
+}
+
+func testCreateNoID(t *testing.T, eventSource domain.EventSource) {
+	// act
+	id, err := eventSource.Create(context.Background(), domain.Event{
+		MessageID:     "1",
+		MessageType:   "MessageType",
+		CorrelationID: "12345",
+	})
+
+	// assert
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+	event, err := eventSource.Get(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Event{
+		ID:            id,
+		MessageID:     "1",
+		MessageType:   "MessageType",
+		CorrelationID: "12345",
+		Sequence:      1,
+	}, event)
+}
+
+func testCreateNoIDGeneratesUniqueIDs(t *testing.T, eventSource domain.EventSource) {
+	// act
+	var ids []string
+	for i := 1; i <= 100; i++ {
+		id, err := eventSource.Create(context.Background(), domain.Event{
+			MessageID:     fmt.Sprint(i),
+			MessageType:   "MessageType",
+			CorrelationID: "12345",
+		})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	// assert
+	assert.True(t, sort.StringsAreSorted(ids), "IDs should be generated in increasing order")
+	for i := 1; i < len(ids); i++ {
+		assert.NotEqual(t, ids[i-1], ids[i])
+	}
+}
+
+func testCreateAlreadyExists(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	_, err := eventSource.Create(context.Background(), domain.Event{
+		ID:            "event-3",
+		MessageID:     "1",
+		MessageType:   "MessageType",
+		CorrelationID: "12345",
+	})
+	require.NoError(t, err)
+
+	// act
+	_, err = eventSource.Create(context.Background(), domain.Event{
+		ID:            "event-3",
+		MessageID:     "2",
+		MessageType:   "MessageType",
+		CorrelationID: "67890",
+	})
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrEventAlreadyExists)
+	event, err := eventSource.Get(context.Background(), "event-3")
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", event.CorrelationID)
+	assert.Empty(t, eventSource.GetByCorrelationID(context.Background(), "67890"))
+}
+
+func testCreateWithID(t *testing.T, eventSource domain.EventSource) {
+	// act
+	id, err := eventSource.Create(context.Background(), domain.Event{
+		ID:            "event-1",
+		MessageID:     "1",
+		MessageType:   "MessageType",
+		CorrelationID: "12345",
+	})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "event-1", id)
+}
+
+func testGet(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	id, _ := eventSource.Create(context.Background(), domain.Event{
+		ID:            "event-1",
+		MessageID:     "1",
+		MessageType:   "MessageType",
+		CorrelationID: "12345",
+	})
+
+	// act
+	event, err := eventSource.Get(context.Background(), id)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Event{
+		ID:            "event-1",
+		MessageID:     "1",
+		MessageType:   "MessageType",
+		CorrelationID: "12345",
+		Sequence:      1,
+	}, event)
+}
+
+func testGetNotFound(t *testing.T, eventSource domain.EventSource) {
+	// act
+	event, err := eventSource.Get(context.Background(), "event-1")
+
+	// assert
+	assert.Equal(t, domain.Event{}, event)
+	assert.Equal(t, domain.ErrEventNotFound, err)
+}
+
+func testGetByCorrelationID(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	eventSource.Create(context.Background(), domain.Event{
+		ID:            "event-1",
+		MessageID:     "1",
+		MessageType:   "MessageType",
+		CorrelationID: "12345",
+	})
+	eventSource.Create(context.Background(), domain.Event{
+		ID:            "event-2",
+		MessageID:     "2",
+		MessageType:   "MessageType",
+		CorrelationID: "12345",
+	})
+
+	// act
+	events := eventSource.GetByCorrelationID(context.Background(), "12345")
+
+	// assert
+	assert.Equal(t, []domain.Event{
+		{
+			ID:            "event-1",
+			MessageID:     "1",
+			MessageType:   "MessageType",
+			CorrelationID: "12345",
+			Sequence:      1,
+		},
+		{
+			ID:            "event-2",
+			MessageID:     "2",
+			MessageType:   "MessageType",
+			CorrelationID: "12345",
+			Sequence:      2,
+		},
+	}, events)
+}
+
+func testGetAll(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	eventSource.Create(context.Background(), domain.Event{
+		ID:            "event-1",
+		MessageID:     "1",
+		MessageType:   "MessageType",
+		CorrelationID: "12345",
+	})
+	eventSource.Create(context.Background(), domain.Event{
+		ID:            "event-2",
+		MessageID:     "2",
+		MessageType:   "MessageType",
+		CorrelationID: "12345",
+	})
+
+	// act
+	events := eventSource.GetAll(context.Background())
+
+	// assert
+	assert.Equal(t, []domain.Event{
+		{
+			ID:            "event-1",
+			MessageID:     "1",
+			MessageType:   "MessageType",
+			CorrelationID: "12345",
+			Sequence:      1,
+		},
+		{
+			ID:            "event-2",
+			MessageID:     "2",
+			MessageType:   "MessageType",
+			CorrelationID: "12345",
+			Sequence:      2,
+		},
+	}, events)
+}
+
+func testSubscribe(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	handler := &recordingEventHandler{}
+	eventSource.Subscribe(handler)
+
+	// act
+	id, err := eventSource.Create(context.Background(), domain.Event{
+		MessageID:     "1",
+		MessageType:   "MessageType",
+		CorrelationID: "12345",
+	})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Event{
+		{
+			ID:            id,
+			MessageID:     "1",
+			MessageType:   "MessageType",
+			CorrelationID: "12345",
+			Sequence:      1,
+		},
+	}, handler.events)
+}
+
+func testSubscribeHandlerError(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	errHandler := errors.New("handler failed")
+	eventSource.Subscribe(failingEventHandler{err: errHandler})
+	event := domain.Event{
+		MessageID:     "1",
+		MessageType:   "MessageType",
+		CorrelationID: "12345",
+	}
+
+	// act
+	id, err := eventSource.Create(context.Background(), event)
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrSubscriberFailed)
+	assert.ErrorIs(t, err, errHandler)
+	// The event was created, so creating it again is a no-op.
+	_, err = eventSource.Get(context.Background(), id)
+	assert.NoError(t, err)
+	duplicateID, err := eventSource.Create(context.Background(), event)
+	assert.NoError(t, err)
+	assert.Equal(t, id, duplicateID)
+}
+
+func testCreateDuplicate(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	event := domain.Event{
+		MessageID:     "1",
+		MessageType:   domain.EventTypeMeterValuesRequest,
+		CorrelationID: "12345",
+		OccurredAt:    time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Payload: domain.MeterValuesRequestPayload{
+			ConnectorID: 1,
+		},
+	}
+	id, err := eventSource.Create(context.Background(), event)
+	require.NoError(t, err)
+	handler := &recordingEventHandler{}
+	eventSource.Subscribe(handler)
+
+	// act
+	duplicateID, err := eventSource.Create(context.Background(), event)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, id, duplicateID)
+	assert.Len(t, eventSource.GetAll(context.Background()), 1)
+	assert.Empty(t, handler.events)
+}
+
+func testCreateDuplicateReceivedLater(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	event := domain.Event{
+		MessageID:     "1",
+		MessageType:   domain.EventTypeMeterValuesRequest,
+		CorrelationID: "12345",
+		OccurredAt:    time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Payload: domain.MeterValuesRequestPayload{
+			ConnectorID: 1,
+		},
+	}
+	id, err := eventSource.Create(context.Background(), event)
+	require.NoError(t, err)
+
+	// act
+	event.OccurredAt = event.OccurredAt.Add(30 * time.Second)
+	duplicateID, err := eventSource.Create(context.Background(), event)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, id, duplicateID)
+	assert.Len(t, eventSource.GetAll(context.Background()), 1)
+}
+
+func testCreateDuplicateConflict(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	event := domain.Event{
+		MessageID:     "1",
+		MessageType:   domain.EventTypeMeterValuesRequest,
+		CorrelationID: "12345",
+		OccurredAt:    time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Payload: domain.MeterValuesRequestPayload{
+			ConnectorID: 1,
+		},
+	}
+	_, err := eventSource.Create(context.Background(), event)
+	require.NoError(t, err)
+
+	// act
+	event.Payload = domain.MeterValuesRequestPayload{
+		ConnectorID: 2,
+	}
+	_, err = eventSource.Create(context.Background(), event)
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrDuplicateConflict)
+	assert.Len(t, eventSource.GetAll(context.Background()), 1)
+}
+
+func testCreateSameMessageIDDifferentCorrelationID(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	_, err := eventSource.Create(context.Background(), domain.Event{
+		MessageID:     "1",
+		MessageType:   "MessageType",
+		CorrelationID: "12345",
+	})
+	require.NoError(t, err)
+
+	// act
+	_, err = eventSource.Create(context.Background(), domain.Event{
+		MessageID:     "1",
+		MessageType:   "MessageType",
+		CorrelationID: "67890",
+	})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, eventSource.GetAll(context.Background()), 2)
+}
+
+// createUnorderedEvents creates events which occurred out of the order they are created in, two of which occurred at
+// the same time, returning their IDs in the order they are created in.
+func createUnorderedEvents(t *testing.T, eventSource domain.EventSource) []string {
+	t.Helper()
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	for i, minutes := range []int{2, 0, 1, 0} {
+		id, err := eventSource.Create(context.Background(), domain.Event{
+			MessageID:     fmt.Sprint(i + 1),
+			MessageType:   "MessageType",
+			CorrelationID: "12345",
+			OccurredAt:    start.Add(time.Duration(minutes) * time.Minute),
+			// The event source assigns the sequence number, whatever the event is created with.
+			Sequence: 100,
+		})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+func testOrderingSequence(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	ids := createUnorderedEvents(t, eventSource)
+
+	// act
+	events := eventSource.GetAll(context.Background())
+	correlatedEvents := eventSource.GetByCorrelationID(context.Background(), "12345")
+
+	// assert
+	assert.Equal(t, ids, eventIDs(events))
+	assert.Equal(t, ids, eventIDs(correlatedEvents))
+	for i, event := range events {
+		assert.Equal(t, uint64(i+1), event.Sequence)
+	}
+}
+
+func testOrderingOccurredAt(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	ids := createUnorderedEvents(t, eventSource)
+	// The events which occurred at the same time are ordered by the order they were created in.
+	want := []string{ids[1], ids[3], ids[2], ids[0]}
+
+	// act
+	events := eventSource.GetAll(context.Background())
+	correlatedEvents := eventSource.GetByCorrelationID(context.Background(), "12345")
+
+	// assert
+	assert.Equal(t, want, eventIDs(events))
+	assert.Equal(t, want, eventIDs(correlatedEvents))
+}
+
+// createQueryEvents creates events for two stations, returning their IDs in the order they are created in.
+func createQueryEvents(t *testing.T, eventSource domain.EventSource) []string {
+	t.Helper()
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	for i, event := range []domain.Event{
+		{
+			CorrelationID: "correlation-1",
+			MessageType:   domain.EventTypeMeterValuesRequest,
+			OccurredAt:    start,
+			Payload:       domain.MeterValuesRequestPayload{StationID: "station-1"},
+		},
+		{
+			CorrelationID: "correlation-1",
+			MessageType:   domain.EventTypeMeterValuesResponse,
+			OccurredAt:    start.Add(time.Minute),
+			Payload:       domain.MeterValuesResponsePayload{MeterValues: []domain.MeterValue{}},
+		},
+		{
+			CorrelationID: "correlation-2",
+			MessageType:   domain.EventTypeMeterValuesNotification,
+			OccurredAt:    start.Add(2 * time.Minute),
+			Payload:       domain.MeterValuesNotificationPayload{StationID: "station-2"},
+		},
+		{
+			CorrelationID: "correlation-3",
+			MessageType:   domain.EventTypeConnectorListRequest,
+			OccurredAt:    start.Add(3 * time.Minute),
+			Payload:       domain.ConnectorListRequestPayload{StationID: "station-1"},
+		},
+		{
+			CorrelationID: "correlation-4",
+			MessageType:   domain.EventTypeMeterValuesNotification,
+			OccurredAt:    start.Add(4 * time.Minute),
+			Payload:       domain.MeterValuesNotificationPayload{StationID: "station-1"},
+		},
+	} {
+		event.MessageID = fmt.Sprint(i + 1)
+		id, err := eventSource.Create(context.Background(), event)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+func testQuery(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	ids := createQueryEvents(t, eventSource)
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter domain.Filter
+		want   []string
+	}{
+		{
+			name: "no filter",
+			want: ids,
+		},
+		{
+			name:   "message type",
+			filter: domain.Filter{MessageTypes: []string{domain.EventTypeMeterValuesNotification}},
+			want:   []string{ids[2], ids[4]},
+		},
+		{
+			name: "message types",
+			filter: domain.Filter{MessageTypes: []string{
+				domain.EventTypeMeterValuesRequest,
+				domain.EventTypeConnectorListRequest,
+				domain.EventTypeMeterValuesRequest,
+			}},
+			want: []string{ids[0], ids[3]},
+		},
+		{
+			name:   "station ID",
+			filter: domain.Filter{StationID: "station-1"},
+			// The response is attributed to the station through its request.
+			want: []string{ids[0], ids[1], ids[3], ids[4]},
+		},
+		{
+			name:   "correlation ID",
+			filter: domain.Filter{CorrelationID: "correlation-1"},
+			want:   []string{ids[0], ids[1]},
+		},
+		{
+			name:   "occurred from is inclusive",
+			filter: domain.Filter{OccurredFrom: start.Add(3 * time.Minute)},
+			want:   []string{ids[3], ids[4]},
+		},
+		{
+			name:   "occurred to is exclusive",
+			filter: domain.Filter{OccurredTo: start.Add(time.Minute)},
+			want:   []string{ids[0]},
+		},
+		{
+			name:   "occurred window",
+			filter: domain.Filter{OccurredFrom: start.Add(time.Minute), OccurredTo: start.Add(3 * time.Minute)},
+			want:   []string{ids[1], ids[2]},
+		},
+		{
+			name:   "empty occurred window",
+			filter: domain.Filter{OccurredFrom: start.Add(3 * time.Minute), OccurredTo: start.Add(time.Minute)},
+		},
+		{
+			name: "combined",
+			filter: domain.Filter{
+				MessageTypes: []string{domain.EventTypeMeterValuesNotification},
+				StationID:    "station-1",
+				OccurredFrom: start.Add(time.Minute),
+			},
+			want: []string{ids[4]},
+		},
+		{
+			name:   "no matches",
+			filter: domain.Filter{StationID: "station-3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			page, err := eventSource.Query(context.Background(), tt.filter)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, eventIDs(page.Events))
+			assert.Empty(t, page.NextCursor)
+		})
+	}
+}
+
+func testQueryPagination(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	createUnorderedEvents(t, eventSource)
+	createQueryEvents(t, eventSource)
+	want := eventIDs(eventSource.GetAll(context.Background()))
+
+	// act
+	var got []string
+	var numPages int
+	filter := domain.Filter{Limit: 2}
+	for {
+		page, err := eventSource.Query(context.Background(), filter)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(page.Events), filter.Limit)
+		got = append(got, eventIDs(page.Events)...)
+		numPages++
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	// assert
+	assert.Equal(t, want, got)
+	assert.Equal(t, 5, numPages)
+}
+
+func testQueryInvalidCursor(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	createQueryEvents(t, eventSource)
+
+	// act
+	_, err := eventSource.Query(context.Background(), domain.Filter{Cursor: "not a cursor"})
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
+
+func testStationID(t *testing.T, eventSource domain.EventSource) {
+	tests := []struct {
+		name  string
+		event domain.Event
+		want  string
+	}{
+		{
+			name: "named by payload",
+			event: domain.Event{
+				CorrelationID: "correlation-2",
+				MessageType:   domain.EventTypeMeterValuesNotification,
+				Payload:       domain.MeterValuesNotificationPayload{StationID: "station-2"},
+			},
+			want: "station-2",
+		},
+		{
+			name: "payload overrides the station created with",
+			event: domain.Event{
+				CorrelationID: "correlation-3",
+				MessageType:   domain.EventTypeMeterValuesNotification,
+				Payload:       domain.MeterValuesNotificationPayload{StationID: "station-2"},
+				StationID:     "station-3",
+			},
+			want: "station-2",
+		},
+		{
+			name: "created with",
+			event: domain.Event{
+				CorrelationID: "correlation-4",
+				StationID:     "station-3",
+			},
+			want: "station-3",
+		},
+		{
+			name: "correlated",
+			event: domain.Event{
+				CorrelationID: "correlation-1",
+			},
+			want: "station-1",
+		},
+		{
+			name: "not correlated",
+			event: domain.Event{
+				CorrelationID: "correlation-5",
+			},
+		},
+	}
+
+	// arrange
+	_, err := eventSource.Create(context.Background(), domain.Event{
+		CorrelationID: "correlation-1",
+		MessageType:   domain.EventTypeMeterValuesNotification,
+		Payload:       domain.MeterValuesNotificationPayload{StationID: "station-1"},
+	})
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			id, err := eventSource.Create(context.Background(), tt.event)
+
+			// assert
+			require.NoError(t, err)
+			event, err := eventSource.Get(context.Background(), id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, event.StationID)
+		})
+	}
+}
+
+func testStationIDResponseBeforeRequest(t *testing.T, eventSource domain.EventSource) {
+	// arrange
+	responseID, err := eventSource.Create(context.Background(), domain.Event{
+		MessageID:     "2",
+		CorrelationID: "correlation-1",
+		MessageType:   domain.EventTypeConnectorListResponse,
+		Payload:       domain.ConnectorListResponsePayload{NumConnectors: 2},
+	})
+	require.NoError(t, err)
+
+	// act
+	requestID, err := eventSource.Create(context.Background(), domain.Event{
+		MessageID:     "1",
+		CorrelationID: "correlation-1",
+		MessageType:   domain.EventTypeConnectorListRequest,
+		Payload:       domain.ConnectorListRequestPayload{StationID: "station-1"},
+	})
+	require.NoError(t, err)
+
+	// assert
+	response, err := eventSource.Get(context.Background(), responseID)
+	assert.NoError(t, err)
+	assert.Equal(t, "station-1", response.StationID)
+
+	page, err := eventSource.Query(context.Background(), domain.Filter{StationID: "station-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{responseID, requestID}, eventIDs(page.Events))
+}
+
+func eventIDs(events []domain.Event) []string {
+	var ids []string
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+type recordingEventHandler struct {
+	events []domain.Event
+}
+
+func (reh *recordingEventHandler) HandleEvent(ctx context.Context, event domain.Event) error {
+	reh.events = append(reh.events, event)
+	return nil
+}
+
+type failingEventHandler struct {
+	err error
+}
+
+func (feh failingEventHandler) HandleEvent(ctx context.Context, event domain.Event) error {
+	return feh.err
+}
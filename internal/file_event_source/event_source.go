@@ -0,0 +1,467 @@
+package fileeventsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+	eventindex "github.com/zucchinho/ocpp/internal/event_index"
+	idgenerator "github.com/zucchinho/ocpp/internal/id_generator"
+)
+
+// SyncPolicy determines when appended events are synced to disk.
+type SyncPolicy int
+
+const (
+	// SyncAlways syncs each event to disk before Create returns, so that no created event is lost on a crash.
+	SyncAlways SyncPolicy = iota
+	// SyncInterval syncs to disk periodically, so that at most an interval's worth of events is lost on a crash.
+	SyncInterval
+	// SyncNever leaves syncing to the operating system.
+	SyncNever
+)
+
+const (
+	defaultSyncInterval   = time.Second
+	defaultMaxSegmentSize = 64 << 20
+)
+
+// Options configures a FileEventSource. The zero value syncs every event, with the default segment size and ULIDs.
+type Options struct {
+	// IDGenerator generates the IDs of events created without one, defaulting to ULIDs.
+	IDGenerator domain.IDGenerator
+	// Ordering is the order events are returned in, defaulting to the order they were created in.
+	Ordering   domain.EventOrdering
+	SyncPolicy SyncPolicy
+	// SyncInterval is how often to sync with the SyncInterval policy, defaulting to a second.
+	SyncInterval time.Duration
+	// MaxSegmentSize is the size in bytes after which a new segment file is started, defaulting to 64 MiB.
+	MaxSegmentSize int64
+}
+
+// FileEventSource is an event source backed by an append-only log of segment files in a directory. Each event is
+// appended as a length prefixed, checksummed record. The events are also held in memory, and indexed by ID and, in
+// the event source's ordering, by correlation ID, message type and station ID; the indexes are rebuilt from the log
+// when it is opened.
+type FileEventSource struct {
+	mu      sync.Mutex
+	dir     string
+	options Options
+	// segment is the file of the last segment, which events are appended to.
+	segment     *os.File
+	segmentSize int64
+	// numRecords is the number of records in the log.
+	numRecords int
+	// sequence is the sequence number of the last event in the log.
+	sequence uint64
+
+	events          map[string]domain.Event
+	indexes         *eventindex.Indexes
+	idsByMessageKey map[domain.MessageKey]string
+	handlers        []domain.EventHandler
+	// stationIDsByCorrelationID is the station the events with each correlation ID are attributed to, and
+	// unattributedIDsByCorrelationID the events waiting for a correlated event to name their station.
+	stationIDsByCorrelationID      map[string]string
+	unattributedIDsByCorrelationID map[string][]string
+
+	stopSync  chan struct{}
+	syncDone  chan struct{}
+	closeOnce sync.Once
+	errClose  error
+}
+
+var _ domain.EventSource = &FileEventSource{}
+
+// NewFileEventSource opens the event log in the directory, creating it if it doesn't exist. If the last record in
+// the log was torn by a crash mid write, it is truncated. Any other record which can't be read fails with
+// ErrCorruptSegment, rather than the records after it being dropped.
+func NewFileEventSource(dir string, options Options) (*FileEventSource, error) {
+	if options.SyncInterval <= 0 {
+		options.SyncInterval = defaultSyncInterval
+	}
+	if options.MaxSegmentSize <= 0 {
+		options.MaxSegmentSize = defaultMaxSegmentSize
+	}
+	if options.IDGenerator == nil {
+		options.IDGenerator = idgenerator.NewULIDGenerator()
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create directory: %w", err)
+	}
+
+	fes := &FileEventSource{
+		dir:             dir,
+		options:         options,
+		events:          make(map[string]domain.Event),
+		indexes:         eventindex.NewIndexes(options.Ordering),
+		idsByMessageKey: make(map[domain.MessageKey]string),
+
+		stationIDsByCorrelationID:      make(map[string]string),
+		unattributedIDsByCorrelationID: make(map[string][]string),
+	}
+
+	if err := fes.load(); err != nil {
+		return nil, err
+	}
+
+	if options.SyncPolicy == SyncInterval {
+		fes.stopSync = make(chan struct{})
+		fes.syncDone = make(chan struct{})
+		go fes.syncPeriodically()
+	}
+
+	return fes, nil
+}
+
+// load replays the segments in the directory into the indexes, and opens the last segment for appending.
+func (fes *FileEventSource) load() error {
+	paths, err := segmentFilePaths(fes.dir)
+	if err != nil {
+		return fmt.Errorf("list segments: %w", err)
+	}
+
+	for i, path := range paths {
+		isLastSegment := i == len(paths)-1
+
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0)
+		if err != nil {
+			return fmt.Errorf("open segment %s: %w", path, err)
+		}
+
+		var errDuplicate error
+		size, err := readSegment(file, func(event domain.Event) {
+			if _, ok := fes.events[event.ID]; ok {
+				errDuplicate = errors.Join(errDuplicate, fmt.Errorf("event %s: %w", event.ID, domain.ErrEventAlreadyExists))
+			}
+			// Events recorded before a correlated event named their station are attributed again, as they were
+			// when they were created.
+			event.StationID = fes.resolveStationID(event)
+			fes.index(event)
+			fes.numRecords++
+		})
+		if errDuplicate != nil {
+			file.Close()
+			return fmt.Errorf("read segment %s: %w", path, errDuplicate)
+		}
+		if errors.Is(err, errTornRecord) && isLastSegment {
+			err = truncate(file, size)
+		}
+		if err != nil {
+			file.Close()
+			if errors.Is(err, errTornRecord) {
+				return fmt.Errorf("read segment %s: %w", path, ErrCorruptSegment)
+			}
+			return fmt.Errorf("read segment %s: %w", path, err)
+		}
+
+		if !isLastSegment {
+			file.Close()
+			continue
+		}
+
+		fes.segment = file
+		fes.segmentSize = size
+	}
+
+	if fes.segment == nil {
+		if err := fes.startSegment(); err != nil {
+			return fmt.Errorf("start segment: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (fes *FileEventSource) Create(ctx context.Context, event domain.Event) (string, error) {
+	fes.mu.Lock()
+
+	// If the message has already been received, e.g. because the station retransmitted it, don't create it again.
+	messageKey, hasMessageKey := event.MessageKey()
+	if existingID, ok := fes.idsByMessageKey[messageKey]; hasMessageKey && ok {
+		defer fes.mu.Unlock()
+		if !fes.events[existingID].SameMessage(event) {
+			return "", fmt.Errorf("event %s: %w", existingID, domain.ErrDuplicateConflict)
+		}
+		return existingID, nil
+	}
+
+	if event.ID == "" {
+		id, err := fes.options.IDGenerator.NewID()
+		if err != nil {
+			fes.mu.Unlock()
+			return "", fmt.Errorf("generate ID: %w", err)
+		}
+		event.ID = id
+	}
+
+	if _, ok := fes.events[event.ID]; ok {
+		fes.mu.Unlock()
+		return "", fmt.Errorf("event %s: %w", event.ID, domain.ErrEventAlreadyExists)
+	}
+
+	event.Sequence = fes.sequence + 1
+	event.StationID = fes.resolveStationID(event)
+
+	if err := fes.append(event); err != nil {
+		fes.mu.Unlock()
+		return "", fmt.Errorf("append event: %w", err)
+	}
+	fes.index(event)
+	handlers := fes.handlers
+
+	fes.mu.Unlock()
+
+	// Notify the subscribers outside of the lock, so that they are free to query the event source.
+	var errHandlers error
+	for _, handler := range handlers {
+		if err := handler.HandleEvent(ctx, event); err != nil {
+			errHandlers = errors.Join(errHandlers, err)
+		}
+	}
+	if errHandlers != nil {
+		return event.ID, fmt.Errorf("event %s: %w: %w", event.ID, domain.ErrSubscriberFailed, errHandlers)
+	}
+
+	return event.ID, nil
+}
+
+func (fes *FileEventSource) Get(ctx context.Context, id string) (domain.Event, error) {
+	fes.mu.Lock()
+	defer fes.mu.Unlock()
+
+	event, ok := fes.events[id]
+	if !ok {
+		return domain.Event{}, domain.ErrEventNotFound
+	}
+
+	return event, nil
+}
+
+func (fes *FileEventSource) GetByCorrelationID(ctx context.Context, correlationID string) []domain.Event {
+	fes.mu.Lock()
+	defer fes.mu.Unlock()
+
+	return fes.eventsByID(fes.indexes.ByCorrelationID(correlationID))
+}
+
+func (fes *FileEventSource) GetAll(ctx context.Context) []domain.Event {
+	fes.mu.Lock()
+	defer fes.mu.Unlock()
+
+	return fes.eventsByID(fes.indexes.All())
+}
+
+func (fes *FileEventSource) Query(ctx context.Context, filter domain.Filter) (domain.EventPage, error) {
+	fes.mu.Lock()
+	defer fes.mu.Unlock()
+
+	return fes.indexes.Query(filter, func(id string) domain.Event {
+		return fes.events[id]
+	})
+}
+
+func (fes *FileEventSource) Subscribe(handler domain.EventHandler) {
+	fes.mu.Lock()
+	defer fes.mu.Unlock()
+
+	fes.handlers = append(fes.handlers, handler)
+}
+
+// Close syncs and closes the log. The event source must not be used afterwards. Closing it again has no effect.
+func (fes *FileEventSource) Close() error {
+	fes.closeOnce.Do(func() {
+		fes.errClose = fes.close()
+	})
+
+	return fes.errClose
+}
+
+func (fes *FileEventSource) close() error {
+	if fes.stopSync != nil {
+		close(fes.stopSync)
+		<-fes.syncDone
+	}
+
+	fes.mu.Lock()
+	defer fes.mu.Unlock()
+
+	if err := fes.segment.Sync(); err != nil {
+		fes.segment.Close()
+		return fmt.Errorf("sync segment: %w", err)
+	}
+	if err := fes.segment.Close(); err != nil {
+		return fmt.Errorf("close segment: %w", err)
+	}
+
+	return nil
+}
+
+// append writes the event to the end of the log, starting a new segment if the last one is full. The caller must
+// hold the lock.
+func (fes *FileEventSource) append(event domain.Event) error {
+	record, err := encodeRecord(event)
+	if err != nil {
+		return fmt.Errorf("encode record: %w", err)
+	}
+
+	if fes.segmentSize > 0 && fes.segmentSize+int64(len(record)) > fes.options.MaxSegmentSize {
+		if err := fes.startSegment(); err != nil {
+			return fmt.Errorf("start segment: %w", err)
+		}
+	}
+
+	if _, err := fes.segment.Write(record); err != nil {
+		// Don't leave a partial record behind for the next record to be appended after.
+		return errors.Join(fmt.Errorf("write record: %w", err), truncate(fes.segment, fes.segmentSize))
+	}
+	fes.segmentSize += int64(len(record))
+	fes.numRecords++
+
+	if fes.options.SyncPolicy == SyncAlways {
+		if err := fes.segment.Sync(); err != nil {
+			return fmt.Errorf("sync segment: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// startSegment syncs and closes the last segment, if any, and creates a new one to append to. The caller must hold the lock.
+func (fes *FileEventSource) startSegment() error {
+	if fes.segment != nil {
+		if err := fes.segment.Sync(); err != nil {
+			return fmt.Errorf("sync segment: %w", err)
+		}
+		if err := fes.segment.Close(); err != nil {
+			return fmt.Errorf("close segment: %w", err)
+		}
+		fes.segment = nil
+	}
+
+	file, err := os.OpenFile(filepath.Join(fes.dir, segmentFileName(fes.numRecords)), os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("create segment: %w", err)
+	}
+
+	// Sync the directory, so that the new segment itself is durable.
+	if err := syncDir(fes.dir); err != nil {
+		file.Close()
+		return err
+	}
+
+	fes.segment = file
+	fes.segmentSize = 0
+
+	return nil
+}
+
+// index adds the event to the indexes. The caller must hold the lock.
+func (fes *FileEventSource) index(event domain.Event) {
+	fes.sequence = event.Sequence
+	fes.events[event.ID] = event
+	fes.indexes.Add(event)
+	if messageKey, ok := event.MessageKey(); ok {
+		fes.idsByMessageKey[messageKey] = event.ID
+	}
+	fes.attribute(event)
+}
+
+// resolveStationID returns the station the event is attributed to: the one named by its payload, else the one it was
+// created with, else the one of the events it is correlated with. The caller must hold the lock.
+func (fes *FileEventSource) resolveStationID(event domain.Event) string {
+	if stationID := event.PayloadStationID(); stationID != "" {
+		return stationID
+	}
+	if event.StationID != "" || event.CorrelationID == "" {
+		return event.StationID
+	}
+
+	return fes.stationIDsByCorrelationID[event.CorrelationID]
+}
+
+// attribute records the station the indexed event is attributed to. If it is the first event with its correlation ID to be
+// attributed to a station, the events with the correlation ID which arrived before it are attributed to the same
+// station. Their records aren't rewritten, as they are attributed again when the log is loaded. The caller must hold
+// the lock.
+func (fes *FileEventSource) attribute(event domain.Event) {
+	if event.StationID == "" {
+		if event.CorrelationID != "" {
+			fes.unattributedIDsByCorrelationID[event.CorrelationID] = append(fes.unattributedIDsByCorrelationID[event.CorrelationID], event.ID)
+		}
+		return
+	}
+
+	if _, ok := fes.stationIDsByCorrelationID[event.CorrelationID]; ok || event.CorrelationID == "" {
+		return
+	}
+	fes.stationIDsByCorrelationID[event.CorrelationID] = event.StationID
+
+	for _, id := range fes.unattributedIDsByCorrelationID[event.CorrelationID] {
+		unattributedEvent := fes.events[id]
+		unattributedEvent.StationID = event.StationID
+		fes.events[id] = unattributedEvent
+		fes.indexes.AddToStation(unattributedEvent)
+	}
+	delete(fes.unattributedIDsByCorrelationID, event.CorrelationID)
+}
+
+// eventsByID returns the events with the IDs, in the same order. The caller must hold the lock.
+func (fes *FileEventSource) eventsByID(ids []string) []domain.Event {
+	var events []domain.Event
+	for _, id := range ids {
+		events = append(events, fes.events[id])
+	}
+
+	return events
+}
+
+func (fes *FileEventSource) syncPeriodically() {
+	defer close(fes.syncDone)
+
+	ticker := time.NewTicker(fes.options.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-fes.stopSync:
+			return
+		case <-ticker.C:
+			fes.mu.Lock()
+			// A failed sync will be retried on the next tick, or when the log is closed.
+			_ = fes.segment.Sync()
+			fes.mu.Unlock()
+		}
+	}
+}
+
+// truncate truncates the file to the size, and syncs it.
+func truncate(file *os.File, size int64) error {
+	if err := file.Truncate(size); err != nil {
+		return fmt.Errorf("truncate segment: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("sync segment: %w", err)
+	}
+
+	return nil
+}
+
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("open directory: %w", err)
+	}
+	defer file.Close()
+
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("sync directory: %w", err)
+	}
+
+	return nil
+}
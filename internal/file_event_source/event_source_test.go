@@ -0,0 +1,296 @@
+package fileeventsource
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+	eventsourceconformance "github.com/zucchinho/ocpp/internal/event_source_conformance"
+)
+
+func TestFileEventSource(t *testing.T) {
+	for name, options := range map[string]Options{
+		"sync always":   {SyncPolicy: SyncAlways},
+		"sync interval": {SyncPolicy: SyncInterval, SyncInterval: time.Millisecond},
+		"sync never":    {SyncPolicy: SyncNever},
+	} {
+		t.Run(name, func(t *testing.T) {
+			eventsourceconformance.Run(t, func(t *testing.T, ordering domain.EventOrdering) domain.EventSource {
+				options := options
+				options.Ordering = ordering
+				return newFileEventSource(t, t.TempDir(), options)
+			})
+		})
+	}
+}
+
+func TestFileEventSource_Reopen(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	fes := newFileEventSource(t, dir, Options{})
+	events := createEvents(t, fes, 3)
+	require.NoError(t, fes.Close())
+
+	// act
+	reopened := newFileEventSource(t, dir, Options{})
+
+	// assert
+	assert.Equal(t, events, reopened.GetAll(context.Background()))
+	assert.Equal(t, events[1:2], reopened.GetByCorrelationID(context.Background(), "correlation-2"))
+	event, err := reopened.Get(context.Background(), "event-3")
+	assert.NoError(t, err)
+	assert.Equal(t, events[2], event)
+
+	// New events are appended after the existing ones.
+	id, err := reopened.Create(context.Background(), domain.Event{CorrelationID: "correlation-4"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, id)
+	assert.Len(t, reopened.GetAll(context.Background()), 4)
+}
+
+func TestFileEventSource_Reopen_Duplicate(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	fes := newFileEventSource(t, dir, Options{})
+	event := newEvent(1)
+	event.Payload = domain.MeterValuesNotificationPayload{
+		StationID:   "station-1",
+		MeterValues: []domain.MeterValue{domain.NewMeterValue(1, "100")},
+	}
+	_, err := fes.Create(context.Background(), event)
+	require.NoError(t, err)
+	require.NoError(t, fes.Close())
+	reopened := newFileEventSource(t, dir, Options{})
+
+	// act
+	event.ID = ""
+	id, err := reopened.Create(context.Background(), event)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "event-1", id)
+	assert.Len(t, reopened.GetAll(context.Background()), 1)
+}
+
+func TestFileEventSource_Reopen_StationID(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	fes := newFileEventSource(t, dir, Options{})
+	// The response is created before its request names the station, so its record has no station ID.
+	response := domain.Event{ID: "event-1", CorrelationID: "correlation-2", MessageType: domain.EventTypeMeterValuesResponse}
+	_, err := fes.Create(context.Background(), response)
+	require.NoError(t, err)
+	_, err = fes.Create(context.Background(), newEvent(2))
+	require.NoError(t, err)
+	_, err = fes.Create(context.Background(), domain.Event{ID: "event-3", CorrelationID: "correlation-2", MessageType: domain.EventTypeMeterValuesResponse})
+	require.NoError(t, err)
+	require.NoError(t, fes.Close())
+
+	// act
+	reopened := newFileEventSource(t, dir, Options{})
+
+	// assert
+	page, err := reopened.Query(context.Background(), domain.Filter{StationID: "station-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"event-1", "event-2", "event-3"}, eventIDs(page.Events))
+}
+
+func TestFileEventSource_Reopen_TornWrite(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	fes := newFileEventSource(t, dir, Options{})
+	events := createEvents(t, fes, 2)
+	require.NoError(t, fes.Close())
+
+	// Simulate a crash midway through appending a record.
+	record, err := encodeRecord(newEvent(3))
+	require.NoError(t, err)
+	segmentPath := filepath.Join(dir, segmentFileName(0))
+	segment, err := os.OpenFile(segmentPath, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = segment.Write(record[:len(record)/2])
+	require.NoError(t, err)
+	require.NoError(t, segment.Close())
+	sizeBeforeTornWrite := fileSize(t, segmentPath) - int64(len(record)/2)
+
+	// act
+	reopened := newFileEventSource(t, dir, Options{})
+
+	// assert
+	assert.Equal(t, events, reopened.GetAll(context.Background()))
+	assert.Equal(t, sizeBeforeTornWrite, fileSize(t, segmentPath))
+
+	_, err = reopened.Create(context.Background(), newEvent(3))
+	assert.NoError(t, err)
+	require.NoError(t, reopened.Close())
+	assert.Len(t, newFileEventSource(t, dir, Options{}).GetAll(context.Background()), 3)
+}
+
+func TestFileEventSource_Reopen_ChecksumMismatch(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	fes := newFileEventSource(t, dir, Options{})
+	events := createEvents(t, fes, 2)
+	require.NoError(t, fes.Close())
+
+	// Flip the last byte of the last record.
+	segmentPath := filepath.Join(dir, segmentFileName(0))
+	data, err := os.ReadFile(segmentPath)
+	require.NoError(t, err)
+	data[len(data)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(segmentPath, data, 0o644))
+
+	// act
+	reopened := newFileEventSource(t, dir, Options{})
+
+	// assert
+	assert.Equal(t, events[:1], reopened.GetAll(context.Background()))
+}
+
+func TestFileEventSource_Reopen_ChecksumMismatchBeforeLastRecord(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	fes := newFileEventSource(t, dir, Options{})
+	createEvents(t, fes, 2)
+	require.NoError(t, fes.Close())
+
+	// Flip the last byte of the first record, which can't have been torn by a crash as a record follows it.
+	record, err := encodeRecord(newEvent(1))
+	require.NoError(t, err)
+	segmentPath := filepath.Join(dir, segmentFileName(0))
+	data, err := os.ReadFile(segmentPath)
+	require.NoError(t, err)
+	data[len(record)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(segmentPath, data, 0o644))
+
+	// act
+	_, err = NewFileEventSource(dir, Options{})
+
+	// assert
+	assert.ErrorIs(t, err, ErrCorruptSegment)
+	assert.Equal(t, int64(len(data)), fileSize(t, segmentPath))
+}
+
+func TestFileEventSource_Segments(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	record, err := encodeRecord(newEvent(1))
+	require.NoError(t, err)
+	// Fit two records in each segment.
+	options := Options{MaxSegmentSize: int64(len(record)) * 2}
+	fes := newFileEventSource(t, dir, options)
+
+	// act
+	events := createEvents(t, fes, 5)
+	require.NoError(t, fes.Close())
+
+	// assert
+	paths, err := segmentFilePaths(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, segmentFileName(0)),
+		filepath.Join(dir, segmentFileName(2)),
+		filepath.Join(dir, segmentFileName(4)),
+	}, paths)
+	assert.Equal(t, events, newFileEventSource(t, dir, options).GetAll(context.Background()))
+}
+
+func TestFileEventSource_Reopen_CorruptSegment(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	record, err := encodeRecord(newEvent(1))
+	require.NoError(t, err)
+	options := Options{MaxSegmentSize: int64(len(record))}
+	fes := newFileEventSource(t, dir, options)
+	createEvents(t, fes, 2)
+	require.NoError(t, fes.Close())
+
+	// Corrupt the first segment, which can't have been torn by a crash as it isn't the last.
+	segmentPath := filepath.Join(dir, segmentFileName(0))
+	require.NoError(t, os.Truncate(segmentPath, fileSize(t, segmentPath)-1))
+
+	// act
+	_, err = NewFileEventSource(dir, options)
+
+	// assert
+	assert.ErrorIs(t, err, ErrCorruptSegment)
+}
+
+func TestFileEventSource_Close_Twice(t *testing.T) {
+	// arrange
+	fes := newFileEventSource(t, t.TempDir(), Options{SyncPolicy: SyncInterval, SyncInterval: time.Millisecond})
+	require.NoError(t, fes.Close())
+
+	// act
+	err := fes.Close()
+
+	// assert
+	assert.NoError(t, err)
+}
+
+func newFileEventSource(t *testing.T, dir string, options Options) *FileEventSource {
+	t.Helper()
+
+	fes, err := NewFileEventSource(dir, options)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		// The event source may already have been closed by the test.
+		_ = fes.Close()
+	})
+
+	return fes
+}
+
+func createEvents(t *testing.T, eventSource domain.EventSource, n int) []domain.Event {
+	t.Helper()
+
+	var events []domain.Event
+	for i := 1; i <= n; i++ {
+		event := newEvent(i)
+		_, err := eventSource.Create(context.Background(), event)
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func newEvent(i int) domain.Event {
+	return domain.Event{
+		ID:            fmt.Sprintf("event-%d", i),
+		MessageID:     fmt.Sprint(i),
+		CorrelationID: fmt.Sprintf("correlation-%d", i),
+		MessageType:   domain.EventTypeMeterValuesNotification,
+		OccurredAt:    time.Date(2022, 1, 1, 0, i, 0, 0, time.UTC),
+		Payload: domain.MeterValuesNotificationPayload{
+			StationID: "station-1",
+		},
+		// The sequence number the event is assigned when it is the i-th event created.
+		Sequence:  uint64(i),
+		StationID: "station-1",
+	}
+}
+
+func eventIDs(events []domain.Event) []string {
+	var ids []string
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+
+	return ids
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	return info.Size()
+}
@@ -0,0 +1,147 @@
+package fileeventsource
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+const (
+	segmentFileExtension = ".log"
+	// recordHeaderSize is the size of the header preceding each record: the length of the record's data, followed by
+	// the CRC-32C checksum of the data, both big endian uint32s.
+	recordHeaderSize = 8
+	// maxRecordSize is the maximum size of a record's data, so that a corrupt length can't cause a huge allocation.
+	maxRecordSize = 16 << 20
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrCorruptSegment is returned when a segment holds a record which can't be read and which can't be the result of a
+// torn write, as it isn't the last record of the last segment.
+var ErrCorruptSegment = errors.New("corrupt segment")
+
+// errTornRecord is returned when the last record of a segment is incomplete, or fails its checksum.
+var errTornRecord = errors.New("torn record")
+
+// segmentFileName returns the name of the segment file whose first record is the record with the given index in the log.
+func segmentFileName(firstIndex int) string {
+	return fmt.Sprintf("%020d%s", firstIndex, segmentFileExtension)
+}
+
+// segmentFilePaths returns the paths of the segment files in the directory, in log order.
+func segmentFilePaths(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+
+	type segmentFile struct {
+		path       string
+		firstIndex int
+	}
+	var segmentFiles []segmentFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentFileExtension) {
+			continue
+		}
+		firstIndex, err := strconv.Atoi(strings.TrimSuffix(name, segmentFileExtension))
+		if err != nil {
+			continue
+		}
+		segmentFiles = append(segmentFiles, segmentFile{
+			path:       filepath.Join(dir, name),
+			firstIndex: firstIndex,
+		})
+	}
+
+	sort.Slice(segmentFiles, func(i, j int) bool {
+		return segmentFiles[i].firstIndex < segmentFiles[j].firstIndex
+	})
+
+	paths := make([]string, 0, len(segmentFiles))
+	for _, segmentFile := range segmentFiles {
+		paths = append(paths, segmentFile.path)
+	}
+
+	return paths, nil
+}
+
+// encodeRecord encodes the event as a length prefixed, checksummed record.
+func encodeRecord(event domain.Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event: %w", err)
+	}
+
+	if len(data) > maxRecordSize {
+		return nil, fmt.Errorf("event is %d bytes, exceeding the maximum of %d bytes", len(data), maxRecordSize)
+	}
+
+	record := make([]byte, recordHeaderSize+len(data))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.Checksum(data, crc32cTable))
+	copy(record[recordHeaderSize:], data)
+
+	return record, nil
+}
+
+// readSegment calls fn with each of the events in the segment, in order. It returns the offset of the end of the last
+// complete record, and errTornRecord if the segment ends with an incomplete or corrupt record. A corrupt record followed
+// by more of the segment, or with a length no record could have, wasn't torn, and ErrCorruptSegment is returned.
+func readSegment(r io.Reader, fn func(event domain.Event)) (int64, error) {
+	reader := bufio.NewReader(r)
+	var offset int64
+	header := make([]byte, recordHeaderSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return offset, nil
+			}
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return offset, errTornRecord
+			}
+			return offset, fmt.Errorf("read record header: %w", err)
+		}
+
+		size := binary.BigEndian.Uint32(header[0:4])
+		if size > maxRecordSize {
+			return offset, fmt.Errorf("record at offset %d is %d bytes: %w", offset, size, ErrCorruptSegment)
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return offset, errTornRecord
+			}
+			return offset, fmt.Errorf("read record data: %w", err)
+		}
+
+		if crc32.Checksum(data, crc32cTable) != binary.BigEndian.Uint32(header[4:8]) {
+			if _, err := reader.Peek(1); errors.Is(err, io.EOF) {
+				return offset, errTornRecord
+			}
+			return offset, fmt.Errorf("checksum of record at offset %d: %w", offset, ErrCorruptSegment)
+		}
+
+		var event domain.Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return offset, fmt.Errorf("unmarshal event at offset %d: %w", offset, err)
+		}
+
+		fn(event)
+		offset += int64(recordHeaderSize + len(data))
+	}
+}
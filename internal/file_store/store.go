@@ -0,0 +1,203 @@
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+	inmemorystore "github.com/zucchinho/ocpp/internal/in_memory_store"
+)
+
+// FileStore is a store which keeps the charging stations in memory, and persists them to a local JSON file on
+// every change. Changes are written to a temporary file which replaces the previous file once it has been synced,
+// so the file always holds a complete snapshot.
+type FileStore struct {
+	mu    sync.Mutex
+	path  string
+	store *inmemorystore.InMemoryStore
+}
+
+var _ domain.Store = &FileStore{}
+
+// NewFileStore opens the store persisted at the given path, creating an empty store if the file doesn't exist.
+func NewFileStore(path string) (*FileStore, error) {
+	store, err := load(path)
+	if err != nil {
+		return nil, fmt.Errorf("load store: %w", err)
+	}
+
+	return &FileStore{
+		path:  path,
+		store: store,
+	}, nil
+}
+
+func (fs *FileStore) UpsertConnector(ctx context.Context, connector domain.Connector) (string, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	previous, err := fs.store.GetChargingStations(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get charging stations: %w", err)
+	}
+
+	id, err := fs.store.UpsertConnector(ctx, connector)
+	if err != nil {
+		return "", err
+	}
+
+	if err := fs.persist(ctx, previous); errors.Is(err, domain.ErrNotDurable) {
+		return id, fmt.Errorf("persist store: %w", err)
+	} else if err != nil {
+		return "", fmt.Errorf("persist store: %w", err)
+	}
+
+	return id, nil
+}
+
+func (fs *FileStore) UpsertChargingStation(ctx context.Context, chargingStation domain.ChargingStation) (string, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	previous, err := fs.store.GetChargingStations(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get charging stations: %w", err)
+	}
+
+	id, err := fs.store.UpsertChargingStation(ctx, chargingStation)
+	if err != nil {
+		return "", err
+	}
+
+	if err := fs.persist(ctx, previous); errors.Is(err, domain.ErrNotDurable) {
+		return id, fmt.Errorf("persist store: %w", err)
+	} else if err != nil {
+		return "", fmt.Errorf("persist store: %w", err)
+	}
+
+	return id, nil
+}
+
+func (fs *FileStore) GetChargingStation(ctx context.Context, id string) (domain.ChargingStation, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return fs.store.GetChargingStation(ctx, id)
+}
+
+func (fs *FileStore) GetChargingStations(ctx context.Context) ([]domain.ChargingStation, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return fs.store.GetChargingStations(ctx)
+}
+
+// persist writes the in memory state to the file. If it fails, the in memory state is rolled back to the previous
+// charging stations, so that the store never serves changes which haven't been persisted. If the file was replaced but
+// the replacement couldn't be synced, the change is kept, as the file already holds it, and ErrNotDurable is returned.
+// The caller must hold the lock.
+func (fs *FileStore) persist(ctx context.Context, previous []domain.ChargingStation) error {
+	chargingStations, err := fs.store.GetChargingStations(ctx)
+	if err != nil {
+		return fmt.Errorf("get charging stations: %w", err)
+	}
+
+	err = writeFile(fs.path, chargingStations)
+	if errors.Is(err, domain.ErrNotDurable) {
+		return err
+	}
+	if err != nil {
+		store, errRollback := newInMemoryStore(previous)
+		if errRollback != nil {
+			return errors.Join(err, fmt.Errorf("roll back: %w", errRollback))
+		}
+		fs.store = store
+		return err
+	}
+
+	return nil
+}
+
+// load reads the charging stations persisted at the path into a new in memory store.
+func load(path string) (*inmemorystore.InMemoryStore, error) {
+	jsonBytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return inmemorystore.NewInMemoryStore(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	var chargingStations []domain.ChargingStation
+	if err := json.Unmarshal(jsonBytes, &chargingStations); err != nil {
+		return nil, fmt.Errorf("unmarshal json: %w", err)
+	}
+
+	return newInMemoryStore(chargingStations)
+}
+
+// newInMemoryStore creates an in memory store holding the charging stations.
+func newInMemoryStore(chargingStations []domain.ChargingStation) (*inmemorystore.InMemoryStore, error) {
+	store := inmemorystore.NewInMemoryStore()
+	for _, chargingStation := range chargingStations {
+		if _, err := store.UpsertChargingStation(context.Background(), chargingStation); err != nil {
+			return nil, fmt.Errorf("upsert charging station %s: %w", chargingStation.ID, err)
+		}
+	}
+
+	return store, nil
+}
+
+// writeFile atomically replaces the file at the path with the charging stations. It returns ErrNotDurable if the file
+// was replaced, but the replacement couldn't be synced.
+func writeFile(path string, chargingStations []domain.ChargingStation) error {
+	jsonBytes, err := json.Marshal(chargingStations)
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temporary file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(jsonBytes); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("write temporary file: %w", err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("sync temporary file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("rename temporary file: %w", err)
+	}
+
+	// Sync the directory, so that the rename itself is durable. The file has been replaced whether or not it can be.
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("sync directory: %w: %w", domain.ErrNotDurable, err)
+	}
+
+	return nil
+}
+
+// syncDir syncs the directory, so that the entries renamed in it are durable. It's a variable so that tests can fail it.
+var syncDir = func(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open directory: %w", err)
+	}
+	defer dir.Close()
+
+	return dir.Sync()
+}
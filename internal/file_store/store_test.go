@@ -0,0 +1,131 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+	storeconformance "github.com/zucchinho/ocpp/internal/store_conformance"
+)
+
+func TestFileStore(t *testing.T) {
+	storeconformance.Run(t, func(t *testing.T) domain.Store {
+		fs, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"))
+		require.NoError(t, err)
+		return fs
+	})
+}
+
+func TestFileStore_Reopen(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "store.json")
+	fs, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	updatedAt := time.Date(2022, 1, 1, 0, 1, 0, 0, time.UTC)
+	_, err = fs.UpsertChargingStation(context.Background(), domain.ChargingStation{
+		ID:        "station-1",
+		UpdatedAt: updatedAt,
+	})
+	require.NoError(t, err)
+	_, err = fs.UpsertConnector(context.Background(), domain.Connector{
+		ID:                1,
+		ChargingStationID: "station-1",
+		Reading:           "100",
+		UpdatedAt:         updatedAt,
+	})
+	require.NoError(t, err)
+
+	// act
+	reopened, err := NewFileStore(path)
+
+	// assert
+	require.NoError(t, err)
+	got, err := reopened.GetChargingStation(context.Background(), "station-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChargingStation{
+		ID:            "station-1",
+		NumConnectors: 1,
+		Connectors: []domain.Connector{
+			{
+				ID:                1,
+				ChargingStationID: "station-1",
+				Reading:           "100",
+				UpdatedAt:         updatedAt,
+			},
+		},
+		UpdatedAt: updatedAt,
+	}, got)
+}
+
+func TestFileStore_CorruptFile(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "store.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	// act
+	_, err := NewFileStore(path)
+
+	// assert
+	assert.Error(t, err)
+}
+
+func TestFileStore_PersistFailureRollsBack(t *testing.T) {
+	// arrange
+	dir := filepath.Join(t.TempDir(), "store")
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	fs, err := NewFileStore(filepath.Join(dir, "store.json"))
+	require.NoError(t, err)
+	_, err = fs.UpsertChargingStation(context.Background(), domain.ChargingStation{ID: "station-1"})
+	require.NoError(t, err)
+
+	// Replace the directory with a file, so that the temporary file can't be created.
+	require.NoError(t, os.RemoveAll(dir))
+	require.NoError(t, os.WriteFile(dir, nil, 0o600))
+
+	// act
+	_, err = fs.UpsertChargingStation(context.Background(), domain.ChargingStation{ID: "station-2"})
+
+	// assert
+	assert.Error(t, err)
+	_, err = fs.GetChargingStation(context.Background(), "station-2")
+	assert.ErrorIs(t, err, domain.ErrChargingStationNotFound)
+}
+
+func TestFileStore_DirectorySyncFailureKeepsChange(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "store.json")
+	fs, err := NewFileStore(path)
+	require.NoError(t, err)
+	_, err = fs.UpsertChargingStation(context.Background(), domain.ChargingStation{ID: "station-1"})
+	require.NoError(t, err)
+
+	errSync := errors.New("sync failed")
+	syncDirBefore := syncDir
+	syncDir = func(string) error {
+		return errSync
+	}
+	t.Cleanup(func() {
+		syncDir = syncDirBefore
+	})
+
+	// act
+	id, err := fs.UpsertChargingStation(context.Background(), domain.ChargingStation{ID: "station-2"})
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrNotDurable)
+	assert.ErrorIs(t, err, errSync)
+	assert.Equal(t, "station-2", id)
+	_, err = fs.GetChargingStation(context.Background(), "station-2")
+	assert.NoError(t, err)
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+	_, err = reopened.GetChargingStation(context.Background(), "station-2")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,87 @@
+package idgenerator
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// crockfordBase32 is the alphabet ULIDs are encoded with, which sorts in the same order as the values it encodes.
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator generates ULIDs: 26 character IDs made up of a millisecond timestamp followed by 80 random bits,
+// which sort lexicographically in the order they were generated. IDs generated within the same millisecond increment
+// the random bits of the previous ID rather than drawing new ones, so that they are strictly increasing.
+type ULIDGenerator struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	entropy io.Reader
+	// last is the last ID generated, as a millisecond timestamp followed by the random bits.
+	last [16]byte
+}
+
+var _ domain.IDGenerator = &ULIDGenerator{}
+
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{
+		now:     time.Now,
+		entropy: rand.Reader,
+	}
+}
+
+func (g *ULIDGenerator) NewID() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(g.now().UnixMilli())
+	lastMs := binary.BigEndian.Uint64(g.last[:8]) >> 16
+
+	var id [16]byte
+	if ms <= lastMs {
+		// Either in the same millisecond as the last ID, or the clock has gone backwards: increment the last ID.
+		id = g.last
+		if !increment(id[:]) {
+			return "", fmt.Errorf("ID space exhausted")
+		}
+	} else {
+		binary.BigEndian.PutUint64(id[:8], ms<<16)
+		if _, err := io.ReadFull(g.entropy, id[6:]); err != nil {
+			return "", fmt.Errorf("read entropy: %w", err)
+		}
+	}
+	g.last = id
+
+	return encode(id), nil
+}
+
+// increment adds one to the big endian number, returning false if it overflowed.
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encode encodes the 128 bit ID as 26 characters of 5 bits each, the first character holding the 3 leading bits.
+func encode(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	var encoded [26]byte
+	for i := len(encoded) - 1; i >= 0; i-- {
+		encoded[i] = crockfordBase32[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(encoded[:])
+}
@@ -0,0 +1,102 @@
+package idgenerator
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestULIDGenerator_NewID(t *testing.T) {
+	// arrange
+	g := &ULIDGenerator{
+		now:     func() time.Time { return time.UnixMilli(1469918176385) },
+		entropy: bytes.NewReader(bytes.Repeat([]byte{0x00}, 10)),
+	}
+
+	// act
+	id, err := g.NewID()
+
+	// assert
+	assert.NoError(t, err)
+	// The timestamp from the ULID spec's example, followed by zeroed random bits.
+	assert.Equal(t, "01ARYZ6S41"+strings.Repeat("0", 16), id)
+}
+
+func TestULIDGenerator_NewID_SameMillisecond(t *testing.T) {
+	// arrange
+	g := &ULIDGenerator{
+		now:     func() time.Time { return time.UnixMilli(1469918176385) },
+		entropy: bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)),
+	}
+	first, err := g.NewID()
+	require.NoError(t, err)
+
+	// act
+	second, err := g.NewID()
+
+	// assert
+	assert.NoError(t, err)
+	// Incrementing the saturated random bits carries into the timestamp.
+	assert.Equal(t, "01ARYZ6S41ZZZZZZZZZZZZZZZZ", first)
+	assert.Equal(t, "01ARYZ6S42"+strings.Repeat("0", 16), second)
+	assert.Less(t, first, second)
+}
+
+func TestULIDGenerator_NewID_Sortable(t *testing.T) {
+	// arrange
+	now := time.UnixMilli(1469918176385)
+	g := &ULIDGenerator{
+		now:     func() time.Time { return now },
+		entropy: bytes.NewReader(bytes.Repeat([]byte{0x80}, 10*4)),
+	}
+
+	// act
+	var ids []string
+	for _, step := range []time.Duration{0, 0, time.Millisecond, -time.Second, time.Hour} {
+		now = now.Add(step)
+		id, err := g.NewID()
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	// assert
+	for i := 1; i < len(ids); i++ {
+		assert.Less(t, ids[i-1], ids[i], "IDs should increase, even when the clock goes backwards")
+	}
+}
+
+func TestULIDGenerator_NewID_EntropyError(t *testing.T) {
+	// arrange
+	errEntropy := errors.New("entropy")
+	g := &ULIDGenerator{
+		now:     time.Now,
+		entropy: iotest.ErrReader(errEntropy),
+	}
+
+	// act
+	_, err := g.NewID()
+
+	// assert
+	assert.ErrorIs(t, err, errEntropy)
+}
+
+func TestNewULIDGenerator(t *testing.T) {
+	// arrange
+	g := NewULIDGenerator()
+
+	// act
+	id, err := g.NewID()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, id, 26)
+	for _, c := range id {
+		assert.Contains(t, crockfordBase32, string(c))
+	}
+}
@@ -2,38 +2,102 @@ package inmemoryeventsource
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 
 	"github.com/zucchinho/ocpp/internal/domain"
+	eventindex "github.com/zucchinho/ocpp/internal/event_index"
 )
 
+// InMemoryEventSource holds events in memory. Besides by ID, the events are indexed in its ordering by correlation ID,
+// message type and station ID, so that queries only visit the events which may be on their page.
 type InMemoryEventSource struct {
-	mu     sync.Mutex
-	events map[string]domain.Event
+	mu          sync.Mutex
+	idGenerator domain.IDGenerator
+	ordering    domain.EventOrdering
+	sequence    uint64
+	events      map[string]domain.Event
+	indexes     *eventindex.Indexes
+	// stationIDsByCorrelationID is the station the events with each correlation ID are attributed to, and
+	// unattributedIDsByCorrelationID the events waiting for a correlated event to name their station.
+	stationIDsByCorrelationID      map[string]string
+	unattributedIDsByCorrelationID map[string][]string
+	idsByMessageKey                map[domain.MessageKey]string
+	handlers                       []domain.EventHandler
 }
 
 var _ domain.EventSource = &InMemoryEventSource{}
 
-func NewInMemoryEventSource() *InMemoryEventSource {
+func NewInMemoryEventSource(idGenerator domain.IDGenerator, ordering domain.EventOrdering) *InMemoryEventSource {
 	return &InMemoryEventSource{
-		events: make(map[string]domain.Event),
+		idGenerator:     idGenerator,
+		ordering:        ordering,
+		events:          make(map[string]domain.Event),
+		indexes:         eventindex.NewIndexes(ordering),
+		idsByMessageKey: make(map[domain.MessageKey]string),
+
+		stationIDsByCorrelationID:      make(map[string]string),
+		unattributedIDsByCorrelationID: make(map[string][]string),
 	}
 }
 
 func (ies *InMemoryEventSource) Create(ctx context.Context, event domain.Event) (string, error) {
 	ies.mu.Lock()
-	defer ies.mu.Unlock()
+
+	// If the message has already been received, e.g. because the station retransmitted it, don't create it again.
+	messageKey, hasMessageKey := event.MessageKey()
+	if existingID, ok := ies.idsByMessageKey[messageKey]; hasMessageKey && ok {
+		defer ies.mu.Unlock()
+		if !ies.events[existingID].SameMessage(event) {
+			return "", fmt.Errorf("event %s: %w", existingID, domain.ErrDuplicateConflict)
+		}
+		return existingID, nil
+	}
 
 	if event.ID == "" {
-		event.ID = "event-" + fmt.Sprint(len(ies.events)+1)
+		id, err := ies.idGenerator.NewID()
+		if err != nil {
+			ies.mu.Unlock()
+			return "", fmt.Errorf("generate ID: %w", err)
+		}
+		event.ID = id
 	}
 
-	ies.events[event.ID] = event
+	if _, ok := ies.events[event.ID]; ok {
+		ies.mu.Unlock()
+		return "", fmt.Errorf("event %s: %w", event.ID, domain.ErrEventAlreadyExists)
+	}
+
+	ies.sequence++
+	event.Sequence = ies.sequence
+	event.StationID = ies.resolveStationID(event)
+	ies.index(event)
+	handlers := ies.handlers
+
+	ies.mu.Unlock()
+
+	// Notify the subscribers outside of the lock, so that they are free to query the event source.
+	var errHandlers error
+	for _, handler := range handlers {
+		if err := handler.HandleEvent(ctx, event); err != nil {
+			errHandlers = errors.Join(errHandlers, err)
+		}
+	}
+	if errHandlers != nil {
+		return event.ID, fmt.Errorf("event %s: %w: %w", event.ID, domain.ErrSubscriberFailed, errHandlers)
+	}
 
 	return event.ID, nil
 }
 
+func (ies *InMemoryEventSource) Subscribe(handler domain.EventHandler) {
+	ies.mu.Lock()
+	defer ies.mu.Unlock()
+
+	ies.handlers = append(ies.handlers, handler)
+}
+
 func (ies *InMemoryEventSource) Get(ctx context.Context, id string) (domain.Event, error) {
 	ies.mu.Lock()
 	defer ies.mu.Unlock()
@@ -50,23 +114,78 @@ func (ies *InMemoryEventSource) GetByCorrelationID(ctx context.Context, correlat
 	ies.mu.Lock()
 	defer ies.mu.Unlock()
 
-	var events []domain.Event
-	for _, event := range ies.events {
-		if event.CorrelationID == correlationID {
-			events = append(events, event)
-		}
-	}
-
-	return events
+	return ies.eventsByID(ies.indexes.ByCorrelationID(correlationID))
 }
 
 func (ies *InMemoryEventSource) GetAll(ctx context.Context) []domain.Event {
 	ies.mu.Lock()
 	defer ies.mu.Unlock()
 
+	return ies.eventsByID(ies.indexes.All())
+}
+
+func (ies *InMemoryEventSource) Query(ctx context.Context, filter domain.Filter) (domain.EventPage, error) {
+	ies.mu.Lock()
+	defer ies.mu.Unlock()
+
+	return ies.indexes.Query(filter, func(id string) domain.Event {
+		return ies.events[id]
+	})
+}
+
+// index adds the event to the indexes. The caller must hold the lock.
+func (ies *InMemoryEventSource) index(event domain.Event) {
+	ies.events[event.ID] = event
+	ies.indexes.Add(event)
+	ies.attribute(event)
+	if messageKey, ok := event.MessageKey(); ok {
+		ies.idsByMessageKey[messageKey] = event.ID
+	}
+}
+
+// resolveStationID returns the station the event is attributed to: the one named by its payload, else the one it was
+// created with, else the one of the events it is correlated with. The caller must hold the lock.
+func (ies *InMemoryEventSource) resolveStationID(event domain.Event) string {
+	if stationID := event.PayloadStationID(); stationID != "" {
+		return stationID
+	}
+	if event.StationID != "" || event.CorrelationID == "" {
+		return event.StationID
+	}
+
+	return ies.stationIDsByCorrelationID[event.CorrelationID]
+}
+
+// attribute records the station the indexed event is attributed to. If it is the first event with its correlation ID to be
+// attributed to a station, the events with the correlation ID which arrived before it are attributed to the same
+// station. The caller must hold the lock.
+func (ies *InMemoryEventSource) attribute(event domain.Event) {
+	if event.StationID == "" {
+		if event.CorrelationID != "" {
+			ies.unattributedIDsByCorrelationID[event.CorrelationID] = append(ies.unattributedIDsByCorrelationID[event.CorrelationID], event.ID)
+		}
+		return
+	}
+
+	if _, ok := ies.stationIDsByCorrelationID[event.CorrelationID]; ok || event.CorrelationID == "" {
+		return
+	}
+	ies.stationIDsByCorrelationID[event.CorrelationID] = event.StationID
+
+	for _, id := range ies.unattributedIDsByCorrelationID[event.CorrelationID] {
+		unattributedEvent := ies.events[id]
+		unattributedEvent.StationID = event.StationID
+		ies.events[id] = unattributedEvent
+		ies.indexes.AddToStation(unattributedEvent)
+	}
+	delete(ies.unattributedIDsByCorrelationID, event.CorrelationID)
+}
+
+// eventsByID returns the events with the IDs, in the same order. The caller must hold the lock.
+func (ies *InMemoryEventSource) eventsByID(ids []string) []domain.Event {
 	var events []domain.Event
-	for _, event := range ies.events {
-		events = append(events, event)
+	for _, id := range ids {
+		events = append(events, ies.events[id])
 	}
 
 	return events
@@ -0,0 +1,116 @@
+package inmemorystore
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+type InMemoryStore struct {
+	mu               sync.RWMutex
+	chargingStations map[string]domain.ChargingStation
+}
+
+var _ domain.Store = &InMemoryStore{}
+
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{
+		chargingStations: make(map[string]domain.ChargingStation),
+	}
+}
+
+// UpsertConnector creates or replaces the connector on its charging station, returning the connector's ID.
+// The charging station must already exist.
+func (ims *InMemoryStore) UpsertConnector(ctx context.Context, connector domain.Connector) (string, error) {
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	chargingStation, ok := ims.chargingStations[connector.ChargingStationID]
+	if !ok {
+		return "", domain.ErrChargingStationNotFound
+	}
+
+	connectors := copyConnectors(chargingStation.Connectors)
+	connectorIdx := -1
+	for i, existingConnector := range connectors {
+		if existingConnector.ID == connector.ID {
+			connectorIdx = i
+			break
+		}
+	}
+	if connectorIdx == -1 {
+		connectors = append(connectors, connector)
+	} else {
+		connectors[connectorIdx] = connector
+	}
+
+	chargingStation.Connectors = connectors
+	if chargingStation.NumConnectors < len(connectors) {
+		chargingStation.NumConnectors = len(connectors)
+	}
+	if connector.UpdatedAt.After(chargingStation.UpdatedAt) {
+		chargingStation.UpdatedAt = connector.UpdatedAt
+	}
+	ims.chargingStations[chargingStation.ID] = chargingStation
+
+	return strconv.FormatInt(int64(connector.ID), 10), nil
+}
+
+// UpsertChargingStation creates or replaces the charging station, including its connectors, returning its ID.
+func (ims *InMemoryStore) UpsertChargingStation(ctx context.Context, chargingStation domain.ChargingStation) (string, error) {
+	if chargingStation.ID == "" {
+		return "", errors.New("charging station ID is required")
+	}
+
+	ims.mu.Lock()
+	defer ims.mu.Unlock()
+
+	chargingStation.Connectors = copyConnectors(chargingStation.Connectors)
+	ims.chargingStations[chargingStation.ID] = chargingStation
+
+	return chargingStation.ID, nil
+}
+
+func (ims *InMemoryStore) GetChargingStation(ctx context.Context, id string) (domain.ChargingStation, error) {
+	ims.mu.RLock()
+	defer ims.mu.RUnlock()
+
+	chargingStation, ok := ims.chargingStations[id]
+	if !ok {
+		return domain.ChargingStation{}, domain.ErrChargingStationNotFound
+	}
+
+	chargingStation.Connectors = copyConnectors(chargingStation.Connectors)
+	return chargingStation, nil
+}
+
+// GetChargingStations returns all of the charging stations, ordered by ID.
+func (ims *InMemoryStore) GetChargingStations(ctx context.Context) ([]domain.ChargingStation, error) {
+	ims.mu.RLock()
+	defer ims.mu.RUnlock()
+
+	chargingStations := make([]domain.ChargingStation, 0, len(ims.chargingStations))
+	for _, chargingStation := range ims.chargingStations {
+		chargingStation.Connectors = copyConnectors(chargingStation.Connectors)
+		chargingStations = append(chargingStations, chargingStation)
+	}
+
+	sort.Slice(chargingStations, func(i, j int) bool {
+		return chargingStations[i].ID < chargingStations[j].ID
+	})
+
+	return chargingStations, nil
+}
+
+// copyConnectors copies the connectors, so that callers can't modify the stored charging stations.
+func copyConnectors(connectors []domain.Connector) []domain.Connector {
+	if connectors == nil {
+		return nil
+	}
+
+	return append(make([]domain.Connector, 0, len(connectors)), connectors...)
+}
@@ -0,0 +1,14 @@
+package inmemorystore
+
+import (
+	"testing"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+	storeconformance "github.com/zucchinho/ocpp/internal/store_conformance"
+)
+
+func TestInMemoryStore(t *testing.T) {
+	storeconformance.Run(t, func(t *testing.T) domain.Store {
+		return NewInMemoryStore()
+	})
+}
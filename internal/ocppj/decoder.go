@@ -0,0 +1,230 @@
+package ocppj
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+const (
+	// actionMeterValues is the action of the CALL a station sends its meter values with, which is converted to a
+	// MeterValuesNotification rather than decoded as it is.
+	actionMeterValues = "MeterValues"
+	// measurandEnergyRegister is the measurand of the sampled values which are readings of a connector's energy
+	// register, and the measurand of sampled values which don't name one.
+	measurandEnergyRegister = "Energy.Active.Import.Register"
+)
+
+// Decode decodes a JSON array of captured frames and converts them to events with Events, returning the events of the
+// frames which could be converted alongside the errors of those which couldn't.
+func Decode(data []byte) ([]domain.Event, error) {
+	var envelopes []Envelope
+	if err := json.Unmarshal(data, &envelopes); err != nil {
+		return nil, fmt.Errorf("failed to decode frames: %w", err)
+	}
+
+	return Events(envelopes)
+}
+
+// Events converts the captured frames to events, in the order they were captured. Each event is correlated by its
+// frame's unique ID scoped by the station it was exchanged with, e.g. station-1/uid-1, occurred when its frame was
+// captured and is attributed to that station, which is also named by the payload of each CALL. The unique ID alone
+// isn't the correlation ID, as it is only unique per station: event sources identify a message by its correlation ID
+// and message ID, so the CALLs of two stations with the same unique ID would be taken for conflicting copies of the
+// same message, and their results would answer each other's CALLs.
+//
+// A CALL's action is the name of its message type, except for MeterValues, which is converted to a
+// MeterValuesNotification of each connector's energy register. A CALLRESULT is of the response type of the CALL with
+// the same unique ID from the same station, and is skipped if that CALL expects no response. A CALLERROR is a
+// CallError, whatever the action of its CALL.
+//
+// It returns the events of the frames which could be converted, with an error for each frame which couldn't, e.g. a
+// CALL of an unknown action or a CALLRESULT without a CALL.
+func Events(envelopes []Envelope) ([]domain.Event, error) {
+	actionsByCall := make(map[call]string)
+	for _, envelope := range envelopes {
+		if envelope.Frame.MessageTypeID == Call {
+			actionsByCall[call{envelope.StationID, envelope.Frame.UniqueID}] = envelope.Frame.Action
+		}
+	}
+
+	var events []domain.Event
+	var errs []error
+	for i, envelope := range envelopes {
+		event, ok, err := eventFromEnvelope(envelope, actionsByCall)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("frame %d (%s): %w", i, envelope.Frame.UniqueID, err))
+			continue
+		}
+		if ok {
+			events = append(events, event)
+		}
+	}
+
+	return events, errors.Join(errs...)
+}
+
+// call identifies a CALL by the station it was exchanged with and its unique ID, which is only unique per station.
+type call struct {
+	stationID string
+	uniqueID  string
+}
+
+// correlationID returns the correlation ID of the CALL's events, which are otherwise correlated with those of another
+// station's CALL with the same unique ID.
+func (c call) correlationID() string {
+	return c.stationID + "/" + c.uniqueID
+}
+
+// eventFromEnvelope converts the captured frame to an event, or returns false if it isn't represented by one.
+func eventFromEnvelope(envelope Envelope, actionsByCall map[call]string) (domain.Event, bool, error) {
+	frame := envelope.Frame
+	event := domain.Event{
+		MessageID:     strconv.Itoa(int(frame.MessageTypeID)),
+		CorrelationID: call{envelope.StationID, frame.UniqueID}.correlationID(),
+		OccurredAt:    envelope.Timestamp,
+		StationID:     envelope.StationID,
+	}
+
+	var err error
+	switch frame.MessageTypeID {
+	case Call:
+		event.MessageType, event.Payload, err = callPayload(envelope.StationID, frame)
+	case CallResult:
+		event.MessageType, err = callResultMessageType(envelope.StationID, frame, actionsByCall)
+		if err == nil && event.MessageType == "" {
+			return domain.Event{}, false, nil
+		}
+		if err == nil {
+			event.Payload, err = domain.DecodePayload(event.MessageType, frame.Payload)
+		}
+	case CallError:
+		event.MessageType = domain.EventTypeCallError
+		event.Payload = domain.CallErrorPayload{
+			ErrorCode:        domain.CallErrorCode(frame.ErrorCode),
+			ErrorDescription: frame.ErrorDescription,
+			ErrorDetails:     frame.ErrorDetails,
+		}
+	default:
+		return domain.Event{}, false, nil
+	}
+	if err != nil {
+		return domain.Event{}, false, err
+	}
+
+	return event, true, nil
+}
+
+// callResultMessageType returns the response type of the CALL the CALLRESULT answers, or an empty string if the CALL
+// expects no response. It returns ErrUnknownCall if there is no CALL with the CALLRESULT's unique ID from the station.
+func callResultMessageType(stationID string, frame Frame, actionsByCall map[call]string) (string, error) {
+	action, ok := actionsByCall[call{stationID, frame.UniqueID}]
+	if !ok {
+		return "", ErrUnknownCall
+	}
+	requestType, _, err := callMessageType(action)
+	if err != nil {
+		return "", err
+	}
+
+	return requestType.ResponseType, nil
+}
+
+// callMessageType returns the message type of the CALLs of the action, and whether they are MeterValues CALLs.
+func callMessageType(action string) (domain.MessageType, bool, error) {
+	name := action
+	if action == actionMeterValues {
+		name = domain.EventTypeMeterValuesNotification
+	}
+	messageType, ok := domain.LookupMessageType(name)
+	if !ok || messageType.Kind == domain.MessageKindResponse {
+		return domain.MessageType{}, false, fmt.Errorf("%w: action %q", domain.ErrUnknownMessageType, action)
+	}
+
+	return messageType, action == actionMeterValues, nil
+}
+
+// callPayload returns the message type and payload of the CALL, naming the station it was exchanged with.
+func callPayload(stationID string, frame Frame) (string, domain.Payload, error) {
+	messageType, meterValues, err := callMessageType(frame.Action)
+	if err != nil {
+		return "", nil, err
+	}
+	if meterValues {
+		payload, err := meterValuesPayload(stationID, frame.Payload)
+		return messageType.Name, payload, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if len(frame.Payload) > 0 {
+		if err := json.Unmarshal(frame.Payload, &fields); err != nil {
+			return "", nil, fmt.Errorf("%w: payload: %v", ErrInvalidFrame, err)
+		}
+	}
+	if fields == nil {
+		fields = make(map[string]json.RawMessage)
+	}
+	fields["stationId"], err = json.Marshal(stationID)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", nil, err
+	}
+	payload, err := domain.DecodePayload(messageType.Name, data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return messageType.Name, payload, nil
+}
+
+// meterValuesRequest is the payload of a MeterValues CALL.
+type meterValuesRequest struct {
+	ConnectorID int32 `json:"connectorId"`
+	MeterValue  []struct {
+		SampledValue []sampledValue `json:"sampledValue"`
+	} `json:"meterValue"`
+}
+
+// sampledValue is a value sampled by a meter.
+type sampledValue struct {
+	Value     string `json:"value"`
+	Unit      string `json:"unit,omitempty"`
+	Measurand string `json:"measurand,omitempty"`
+	Phase     string `json:"phase,omitempty"`
+}
+
+// meterValuesPayload converts the payload of a MeterValues CALL to a MeterValuesNotification of the connector's
+// latest reading of its energy register, or of no readings if it has none.
+func meterValuesPayload(stationID string, data []byte) (domain.MeterValuesNotificationPayload, error) {
+	var request meterValuesRequest
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &request); err != nil {
+			return domain.MeterValuesNotificationPayload{}, fmt.Errorf("%w: payload: %v", ErrInvalidFrame, err)
+		}
+	}
+
+	payload := domain.MeterValuesNotificationPayload{StationID: stationID}
+	var reading string
+	for _, meterValue := range request.MeterValue {
+		for _, sample := range meterValue.SampledValue {
+			if sample.Phase != "" || (sample.Measurand != "" && sample.Measurand != measurandEnergyRegister) {
+				continue
+			}
+			reading = sample.Value
+			if sample.Unit != "" {
+				reading += " " + sample.Unit
+			}
+		}
+	}
+	if reading != "" {
+		payload.MeterValues = []domain.MeterValue{domain.NewMeterValue(request.ConnectorID, reading)}
+	}
+
+	return payload, nil
+}
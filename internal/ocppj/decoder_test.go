@@ -0,0 +1,313 @@
+package ocppj
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+	idgenerator "github.com/zucchinho/ocpp/internal/id_generator"
+	inmemoryeventsource "github.com/zucchinho/ocpp/internal/in_memory_event_source"
+)
+
+func TestFrame_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Frame
+		wantErr error
+	}{
+		{
+			name: "call",
+			data: `[2, "uid-1", "Heartbeat", {}]`,
+			want: Frame{MessageTypeID: Call, UniqueID: "uid-1", Action: "Heartbeat", Payload: json.RawMessage(`{}`)},
+		},
+		{
+			name: "call result",
+			data: `[3, "uid-1", {"currentTime": "2022-01-01T00:00:00Z"}]`,
+			want: Frame{MessageTypeID: CallResult, UniqueID: "uid-1", Payload: json.RawMessage(`{"currentTime": "2022-01-01T00:00:00Z"}`)},
+		},
+		{
+			name: "call error",
+			data: `[4, "uid-1", "NotImplemented", "no such action", {"action": "DataTransfer"}]`,
+			want: Frame{
+				MessageTypeID:    CallError,
+				UniqueID:         "uid-1",
+				ErrorCode:        "NotImplemented",
+				ErrorDescription: "no such action",
+				ErrorDetails:     json.RawMessage(`{"action": "DataTransfer"}`),
+			},
+		},
+		{
+			name:    "not an array",
+			data:    `{"uniqueId": "uid-1"}`,
+			wantErr: ErrInvalidFrame,
+		},
+		{
+			name:    "unknown message type ID",
+			data:    `[5, "uid-1", {}]`,
+			wantErr: ErrInvalidFrame,
+		},
+		{
+			name:    "call without payload",
+			data:    `[2, "uid-1", "Heartbeat"]`,
+			wantErr: ErrInvalidFrame,
+		},
+		{
+			name:    "numeric unique ID",
+			data:    `[3, 1, {}]`,
+			wantErr: ErrInvalidFrame,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			var got Frame
+			err := json.Unmarshal([]byte(tt.data), &got)
+
+			// assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	at := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		data    string
+		want    []domain.Event
+		wantErr error
+	}{
+		{
+			name: "call and its result",
+			data: `[
+				{"stationId": "station-1", "timestamp": "2022-01-01T00:00:00Z", "frame": [2, "uid-1", "Authorize", {"idTag": "tag-1"}]},
+				{"stationId": "station-1", "timestamp": "2022-01-01T00:00:01Z", "frame": [3, "uid-1", {"idTagInfo": {"status": "Accepted"}}]}
+			]`,
+			want: []domain.Event{
+				{
+					MessageID:     "2",
+					CorrelationID: "station-1/uid-1",
+					MessageType:   domain.EventTypeAuthorize,
+					OccurredAt:    at,
+					StationID:     "station-1",
+					Payload:       domain.AuthorizePayload{StationID: "station-1", IDTag: "tag-1"},
+				},
+				{
+					MessageID:     "3",
+					CorrelationID: "station-1/uid-1",
+					MessageType:   domain.EventTypeAuthorizeResponse,
+					OccurredAt:    at.Add(time.Second),
+					StationID:     "station-1",
+					Payload:       domain.AuthorizeResponsePayload{IDTagInfo: domain.IDTagInfo{Status: domain.AuthorizationAccepted}},
+				},
+			},
+		},
+		{
+			name: "result captured before its call",
+			data: `[
+				{"stationId": "station-1", "timestamp": "2022-01-01T00:00:01Z", "frame": [3, "uid-1", {"currentTime": "2022-01-01T00:00:01Z"}]},
+				{"stationId": "station-1", "timestamp": "2022-01-01T00:00:00Z", "frame": [2, "uid-1", "Heartbeat", {}]}
+			]`,
+			want: []domain.Event{
+				{
+					MessageID:     "3",
+					CorrelationID: "station-1/uid-1",
+					MessageType:   domain.EventTypeHeartbeatResponse,
+					OccurredAt:    at.Add(time.Second),
+					StationID:     "station-1",
+					Payload:       domain.HeartbeatResponsePayload{CurrentTime: at.Add(time.Second)},
+				},
+				{
+					MessageID:     "2",
+					CorrelationID: "station-1/uid-1",
+					MessageType:   domain.EventTypeHeartbeat,
+					OccurredAt:    at,
+					StationID:     "station-1",
+					Payload:       domain.HeartbeatPayload{StationID: "station-1"},
+				},
+			},
+		},
+		{
+			name: "meter values",
+			data: `[
+				{"stationId": "station-1", "timestamp": "2022-01-01T00:00:00Z", "frame": [2, "uid-1", "MeterValues", {
+					"connectorId": 2,
+					"meterValue": [
+						{"timestamp": "2022-01-01T00:00:00Z", "sampledValue": [{"value": "1.5", "unit": "kWh"}]},
+						{"timestamp": "2022-01-01T00:00:00Z", "sampledValue": [
+							{"value": "1.6", "unit": "kWh", "measurand": "Energy.Active.Import.Register"},
+							{"value": "0.5", "unit": "kWh", "measurand": "Energy.Active.Import.Register", "phase": "L1"},
+							{"value": "7200", "unit": "W", "measurand": "Power.Active.Import"}
+						]}
+					]
+				}]},
+				{"stationId": "station-1", "timestamp": "2022-01-01T00:00:00Z", "frame": [3, "uid-1", {}]}
+			]`,
+			want: []domain.Event{
+				{
+					MessageID:     "2",
+					CorrelationID: "station-1/uid-1",
+					MessageType:   domain.EventTypeMeterValuesNotification,
+					OccurredAt:    at,
+					StationID:     "station-1",
+					Payload: domain.MeterValuesNotificationPayload{
+						StationID:   "station-1",
+						MeterValues: []domain.MeterValue{domain.NewMeterValue(2, "1.6 kWh")},
+					},
+				},
+			},
+		},
+		{
+			name: "call error",
+			data: `[
+				{"stationId": "station-1", "timestamp": "2022-01-01T00:00:00Z", "frame": [2, "uid-1", "MeterValues", {"connectorId": 1, "meterValue": []}]},
+				{"stationId": "station-1", "timestamp": "2022-01-01T00:00:01Z", "frame": [4, "uid-1", "InternalError", "meter unavailable", {"retryAfter": 60}]}
+			]`,
+			want: []domain.Event{
+				{
+					MessageID:     "2",
+					CorrelationID: "station-1/uid-1",
+					MessageType:   domain.EventTypeMeterValuesNotification,
+					OccurredAt:    at,
+					StationID:     "station-1",
+					Payload:       domain.MeterValuesNotificationPayload{StationID: "station-1"},
+				},
+				{
+					MessageID:     "4",
+					CorrelationID: "station-1/uid-1",
+					MessageType:   domain.EventTypeCallError,
+					OccurredAt:    at.Add(time.Second),
+					StationID:     "station-1",
+					Payload: domain.CallErrorPayload{
+						ErrorCode:        domain.CallErrorInternalError,
+						ErrorDescription: "meter unavailable",
+						ErrorDetails:     json.RawMessage(`{"retryAfter": 60}`),
+					},
+				},
+			},
+		},
+		{
+			name: "unknown action",
+			data: `[
+				{"stationId": "station-1", "timestamp": "2022-01-01T00:00:00Z", "frame": [2, "uid-1", "DataTransfer", {}]}
+			]`,
+			wantErr: domain.ErrUnknownMessageType,
+		},
+		{
+			name: "result from another station",
+			data: `[
+				{"stationId": "station-1", "timestamp": "2022-01-01T00:00:00Z", "frame": [2, "uid-1", "Heartbeat", {}]},
+				{"stationId": "station-2", "timestamp": "2022-01-01T00:00:01Z", "frame": [3, "uid-1", {}]}
+			]`,
+			want: []domain.Event{
+				{
+					MessageID:     "2",
+					CorrelationID: "station-1/uid-1",
+					MessageType:   domain.EventTypeHeartbeat,
+					OccurredAt:    at,
+					StationID:     "station-1",
+					Payload:       domain.HeartbeatPayload{StationID: "station-1"},
+				},
+			},
+			wantErr: ErrUnknownCall,
+		},
+		{
+			name: "unique ID reused by another station",
+			data: `[
+				{"stationId": "station-1", "timestamp": "2022-01-01T00:00:00Z", "frame": [2, "1", "Authorize", {"idTag": "tag-1"}]},
+				{"stationId": "station-2", "timestamp": "2022-01-01T00:00:00Z", "frame": [2, "1", "Authorize", {"idTag": "tag-2"}]},
+				{"stationId": "station-2", "timestamp": "2022-01-01T00:00:01Z", "frame": [3, "1", {"idTagInfo": {"status": "Blocked"}}]},
+				{"stationId": "station-1", "timestamp": "2022-01-01T00:00:01Z", "frame": [3, "1", {"idTagInfo": {"status": "Accepted"}}]}
+			]`,
+			want: []domain.Event{
+				{
+					MessageID:     "2",
+					CorrelationID: "station-1/1",
+					MessageType:   domain.EventTypeAuthorize,
+					OccurredAt:    at,
+					StationID:     "station-1",
+					Payload:       domain.AuthorizePayload{StationID: "station-1", IDTag: "tag-1"},
+				},
+				{
+					MessageID:     "2",
+					CorrelationID: "station-2/1",
+					MessageType:   domain.EventTypeAuthorize,
+					OccurredAt:    at,
+					StationID:     "station-2",
+					Payload:       domain.AuthorizePayload{StationID: "station-2", IDTag: "tag-2"},
+				},
+				{
+					MessageID:     "3",
+					CorrelationID: "station-2/1",
+					MessageType:   domain.EventTypeAuthorizeResponse,
+					OccurredAt:    at.Add(time.Second),
+					StationID:     "station-2",
+					Payload:       domain.AuthorizeResponsePayload{IDTagInfo: domain.IDTagInfo{Status: domain.AuthorizationBlocked}},
+				},
+				{
+					MessageID:     "3",
+					CorrelationID: "station-1/1",
+					MessageType:   domain.EventTypeAuthorizeResponse,
+					OccurredAt:    at.Add(time.Second),
+					StationID:     "station-1",
+					Payload:       domain.AuthorizeResponsePayload{IDTagInfo: domain.IDTagInfo{Status: domain.AuthorizationAccepted}},
+				},
+			},
+		},
+		{
+			name:    "invalid frame",
+			data:    `[{"stationId": "station-1", "timestamp": "2022-01-01T00:00:00Z", "frame": [2, "uid-1"]}]`,
+			wantErr: ErrInvalidFrame,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got, err := Decode([]byte(tt.data))
+
+			// assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDecode_UniqueIDReusedByAnotherStation_NotDuplicate(t *testing.T) {
+	// arrange
+	events, err := Decode([]byte(`[
+		{"stationId": "station-1", "timestamp": "2022-01-01T00:00:00Z", "frame": [2, "1", "Heartbeat", {}]},
+		{"stationId": "station-2", "timestamp": "2022-01-01T00:00:00Z", "frame": [2, "1", "Heartbeat", {}]}
+	]`))
+	require.NoError(t, err)
+	ctx := context.Background()
+	eventSource := inmemoryeventsource.NewInMemoryEventSource(idgenerator.NewULIDGenerator(), domain.OrderBySequence)
+
+	// act
+	var ids []string
+	for _, event := range events {
+		id, err := eventSource.Create(ctx, event)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	// assert
+	require.Len(t, ids, 2)
+	assert.NotEqual(t, ids[0], ids[1])
+}
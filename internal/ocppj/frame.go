@@ -0,0 +1,94 @@
+package ocppj
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MessageTypeID is the kind of an OCPP-J frame, its first element.
+type MessageTypeID int
+
+const (
+	// Call is a request, [2, uniqueId, action, payload].
+	Call MessageTypeID = 2
+	// CallResult is a response to a Call with the same unique ID, [3, uniqueId, payload].
+	CallResult MessageTypeID = 3
+	// CallError is an error in response to a Call with the same unique ID,
+	// [4, uniqueId, errorCode, errorDescription, errorDetails].
+	CallError MessageTypeID = 4
+)
+
+var (
+	// ErrInvalidFrame is returned when a frame isn't a well-formed CALL, CALLRESULT or CALLERROR.
+	ErrInvalidFrame = errors.New("invalid OCPP-J frame")
+	// ErrUnknownCall is returned when there is no CALL for a CALLRESULT to answer.
+	ErrUnknownCall = errors.New("no CALL with the unique ID")
+	// ErrUnsupportedFrame is returned when a frame can't be represented as an event.
+	ErrUnsupportedFrame = errors.New("unsupported OCPP-J frame")
+)
+
+// Frame is an OCPP-J frame.
+type Frame struct {
+	MessageTypeID MessageTypeID
+	UniqueID      string
+	// Action is the action of a Call.
+	Action string
+	// Payload is the payload of a Call or CallResult.
+	Payload json.RawMessage
+	// ErrorCode, ErrorDescription and ErrorDetails describe the error of a CallError.
+	ErrorCode        string
+	ErrorDescription string
+	ErrorDetails     json.RawMessage
+}
+
+// UnmarshalJSON decodes the frame from its JSON array. It returns ErrInvalidFrame if the array isn't a CALL,
+// CALLRESULT or CALLERROR with the elements they have.
+func (f *Frame) UnmarshalJSON(data []byte) error {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFrame, err)
+	}
+	if len(elements) < 2 {
+		return fmt.Errorf("%w: %d elements", ErrInvalidFrame, len(elements))
+	}
+
+	var frame Frame
+	if err := json.Unmarshal(elements[0], &frame.MessageTypeID); err != nil {
+		return fmt.Errorf("%w: message type ID: %v", ErrInvalidFrame, err)
+	}
+	if err := json.Unmarshal(elements[1], &frame.UniqueID); err != nil {
+		return fmt.Errorf("%w: unique ID: %v", ErrInvalidFrame, err)
+	}
+
+	var fields []any
+	switch frame.MessageTypeID {
+	case Call:
+		fields = []any{&frame.Action, &frame.Payload}
+	case CallResult:
+		fields = []any{&frame.Payload}
+	case CallError:
+		fields = []any{&frame.ErrorCode, &frame.ErrorDescription, &frame.ErrorDetails}
+	default:
+		return fmt.Errorf("%w: message type ID %d", ErrInvalidFrame, frame.MessageTypeID)
+	}
+	if len(elements) != 2+len(fields) {
+		return fmt.Errorf("%w: %d elements for message type ID %d", ErrInvalidFrame, len(elements), frame.MessageTypeID)
+	}
+	for i, field := range fields {
+		if err := json.Unmarshal(elements[2+i], field); err != nil {
+			return fmt.Errorf("%w: element %d: %v", ErrInvalidFrame, 2+i, err)
+		}
+	}
+
+	*f = frame
+	return nil
+}
+
+// Envelope is a frame as captured by the gateway, with the station it was exchanged with and when.
+type Envelope struct {
+	StationID string    `json:"stationId"`
+	Timestamp time.Time `json:"timestamp"`
+	Frame     Frame     `json:"frame"`
+}
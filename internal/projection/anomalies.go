@@ -0,0 +1,116 @@
+package projection
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// maxPlausiblePowerW is the highest average power in W a connector is taken to be able to deliver between two
+// readings, above which the increase between them is reported as a jump. It is that of the fastest DC chargers.
+const maxPlausiblePowerW = 350_000
+
+// readingAnomalies returns the anomalies in the readings of each of the station's connectors, from the readings of the
+// station's connectors in the order their events were created in.
+func readingAnomalies(readings []domain.ConnectorReading) []domain.ReadingAnomaly {
+	var anomalies []domain.ReadingAnomaly
+	for _, connectorID := range readingConnectorIDs(readings) {
+		anomalies = append(anomalies, connectorReadingAnomalies(readings, connectorID)...)
+	}
+
+	return anomalies
+}
+
+// connectorReadingAnomalies returns the anomalies in the connector's readings, ordered by when the anomalous readings
+// occurred, from the readings of the station's connectors in the order their events were created in.
+func connectorReadingAnomalies(readings []domain.ConnectorReading, connectorID int32) []domain.ReadingAnomaly {
+	var anomalies []domain.ReadingAnomaly
+
+	// A reading received after a later one isn't a fault of the meter, but means that the latest reading received
+	// isn't the latest reading.
+	var latest *domain.ConnectorReading
+	for _, reading := range readings {
+		reading := reading
+		if reading.ConnectorID != connectorID {
+			continue
+		}
+		if latest != nil && reading.OccurredAt.Before(latest.OccurredAt) {
+			anomalies = append(anomalies, domain.ReadingAnomaly{
+				Kind:     domain.ReadingAnomalyOutOfOrder,
+				Reading:  reading,
+				Previous: *latest,
+				Detail:   fmt.Sprintf("received after the reading at %s", latest.OccurredAt.Format(time.RFC3339)),
+			})
+			continue
+		}
+		latest = &reading
+	}
+
+	// The meter's readings are only comparable in the order they occurred in.
+	var previous *domain.ConnectorReading
+	for _, reading := range selectConnectorReadings(readings, connectorID, time.Time{}, time.Time{}) {
+		reading := reading
+		if reading.ReadingValue == nil {
+			continue
+		}
+		if previous != nil {
+			if kind, detail, ok := readingAnomaly(*previous, reading); ok {
+				anomalies = append(anomalies, domain.ReadingAnomaly{
+					Kind:     kind,
+					Reading:  reading,
+					Previous: *previous,
+					Detail:   detail,
+				})
+			}
+		}
+		previous = &reading
+	}
+
+	for i := range anomalies {
+		anomalies[i].StationID = anomalies[i].Reading.StationID
+		anomalies[i].ConnectorID = connectorID
+	}
+	sortReadingAnomalies(anomalies)
+
+	return anomalies
+}
+
+// readingAnomaly returns the kind of anomaly the reading is in relation to the reading before it, if any.
+func readingAnomaly(previous, reading domain.ConnectorReading) (domain.ReadingAnomalyKind, string, bool) {
+	previousWh, wh := previous.ReadingValue.Wh(), reading.ReadingValue.Wh()
+	difference := wh.Sub(previousWh)
+
+	if difference.Sign() < 0 {
+		if nearRegisterCapacity(previousWh) {
+			return domain.ReadingAnomalyRollover, fmt.Sprintf("fell from %s Wh to %s Wh, near the capacity of a register with as many digits", previousWh, wh), true
+		}
+		if wh.Cmp(previousWh.MulRatio(1, 10, previousWh.Scale())) < 0 {
+			return domain.ReadingAnomalyReset, fmt.Sprintf("fell from %s Wh to %s Wh", previousWh, wh), true
+		}
+		return domain.ReadingAnomalyDecrease, fmt.Sprintf("fell by %s Wh", previousWh.Sub(wh)), true
+	}
+
+	if difference.Sign() == 0 {
+		return "", "", false
+	}
+
+	elapsed := reading.OccurredAt.Sub(previous.OccurredAt)
+	if elapsed <= 0 {
+		return domain.ReadingAnomalyJump, fmt.Sprintf("rose by %s Wh at the same time", difference), true
+	}
+	powerW := difference.MulRatio(int64(time.Hour), int64(elapsed), 0)
+	if powerW.Cmp(domain.MustParseDecimal(fmt.Sprint(maxPlausiblePowerW))) > 0 {
+		return domain.ReadingAnomalyJump, fmt.Sprintf("rose by %s Wh in %s, an average of %s W", difference, elapsed, powerW), true
+	}
+
+	return "", "", false
+}
+
+// sortReadingAnomalies sorts the anomalies of a connector by when the anomalous readings occurred.
+func sortReadingAnomalies(anomalies []domain.ReadingAnomaly) {
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return anomalies[i].Reading.OccurredAt.Before(anomalies[j].Reading.OccurredAt)
+	})
+}
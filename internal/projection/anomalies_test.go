@@ -0,0 +1,161 @@
+package projection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+func TestReadingAnomalies(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	minutes := func(n int) time.Time {
+		return start.Add(time.Duration(n) * time.Minute)
+	}
+	newReading := func(connectorID int32, reading string, occurredAt time.Time) domain.ConnectorReading {
+		return domain.ConnectorReading{
+			StationID:    "station-1",
+			ConnectorID:  connectorID,
+			Reading:      reading,
+			ReadingValue: readingValue(reading),
+			OccurredAt:   occurredAt,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		readings  []domain.ConnectorReading
+		wantKinds []domain.ReadingAnomalyKind
+	}{
+		{
+			name: "increasing",
+			readings: []domain.ConnectorReading{
+				newReading(1, "100", minutes(0)),
+				newReading(1, "200", minutes(10)),
+				newReading(1, "200", minutes(20)),
+			},
+		},
+		{
+			name: "decrease",
+			readings: []domain.ConnectorReading{
+				newReading(1, "1000", minutes(0)),
+				newReading(1, "900", minutes(10)),
+			},
+			wantKinds: []domain.ReadingAnomalyKind{domain.ReadingAnomalyDecrease},
+		},
+		{
+			name: "reset",
+			readings: []domain.ConnectorReading{
+				newReading(1, "1000", minutes(0)),
+				newReading(1, "10", minutes(10)),
+			},
+			wantKinds: []domain.ReadingAnomalyKind{domain.ReadingAnomalyReset},
+		},
+		{
+			name: "rollover",
+			readings: []domain.ConnectorReading{
+				newReading(1, "99950", minutes(0)),
+				newReading(1, "50", minutes(10)),
+			},
+			wantKinds: []domain.ReadingAnomalyKind{domain.ReadingAnomalyRollover},
+		},
+		{
+			name: "jump",
+			readings: []domain.ConnectorReading{
+				newReading(1, "100", minutes(0)),
+				newReading(1, "100 kWh", minutes(10)),
+			},
+			wantKinds: []domain.ReadingAnomalyKind{domain.ReadingAnomalyJump},
+		},
+		{
+			name: "plausible power",
+			readings: []domain.ConnectorReading{
+				newReading(1, "0", minutes(0)),
+				newReading(1, "50 kWh", minutes(10)),
+			},
+		},
+		{
+			name: "out of order",
+			readings: []domain.ConnectorReading{
+				newReading(1, "100", minutes(0)),
+				newReading(1, "300", minutes(20)),
+				newReading(1, "200", minutes(10)),
+			},
+			wantKinds: []domain.ReadingAnomalyKind{domain.ReadingAnomalyOutOfOrder},
+		},
+		{
+			name: "ordered by connector, then by when the readings occurred",
+			readings: []domain.ConnectorReading{
+				newReading(2, "1000", minutes(0)),
+				newReading(1, "1000", minutes(0)),
+				newReading(2, "900", minutes(10)),
+				newReading(1, "99950", minutes(10)),
+				newReading(1, "1", minutes(20)),
+			},
+			wantKinds: []domain.ReadingAnomalyKind{
+				domain.ReadingAnomalyJump,
+				domain.ReadingAnomalyRollover,
+				domain.ReadingAnomalyDecrease,
+			},
+		},
+		{
+			name: "unparseable readings are skipped",
+			readings: []domain.ConnectorReading{
+				newReading(1, "100", minutes(0)),
+				newReading(1, "not a reading", minutes(10)),
+				newReading(1, "200", minutes(20)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			anomalies := readingAnomalies(tt.readings)
+
+			// assert
+			var gotKinds []domain.ReadingAnomalyKind
+			for _, anomaly := range anomalies {
+				gotKinds = append(gotKinds, anomaly.Kind)
+				assert.Equal(t, "station-1", anomaly.StationID)
+				assert.Equal(t, anomaly.Reading.ConnectorID, anomaly.ConnectorID)
+				assert.NotEmpty(t, anomaly.Detail)
+			}
+			assert.Equal(t, tt.wantKinds, gotKinds)
+		})
+	}
+}
+
+func TestReadingAnomalies_OutOfOrder(t *testing.T) {
+	// arrange
+	later := domain.ConnectorReading{
+		StationID:    "station-1",
+		ConnectorID:  1,
+		Reading:      "300",
+		ReadingValue: readingValue("300"),
+		OccurredAt:   time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	earlier := domain.ConnectorReading{
+		StationID:    "station-1",
+		ConnectorID:  1,
+		Reading:      "200",
+		ReadingValue: readingValue("200"),
+		OccurredAt:   time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// act
+	anomalies := readingAnomalies([]domain.ConnectorReading{later, earlier})
+
+	// assert
+	assert.Equal(t, []domain.ReadingAnomaly{
+		{
+			StationID:   "station-1",
+			ConnectorID: 1,
+			Kind:        domain.ReadingAnomalyOutOfOrder,
+			Reading:     earlier,
+			Previous:    later,
+			Detail:      "received after the reading at 2022-01-02T00:00:00Z",
+		},
+	}, anomalies)
+}
@@ -0,0 +1,76 @@
+package projection
+
+import (
+	"context"
+	"time"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// asOfEventSource is a view of an event source which only holds the events which occurred at or before an instant.
+// Events are created in, and subscribed to from, the underlying event source.
+type asOfEventSource struct {
+	domain.EventSource
+	at time.Time
+}
+
+// newAsOfEventSource returns a view of the event source as of the instant. A view of a view holds the events as of
+// the earlier of the two instants.
+func newAsOfEventSource(eventSource domain.EventSource, at time.Time) *asOfEventSource {
+	if asOf, ok := eventSource.(*asOfEventSource); ok {
+		if asOf.at.Before(at) {
+			at = asOf.at
+		}
+		eventSource = asOf.EventSource
+	}
+
+	return &asOfEventSource{
+		EventSource: eventSource,
+		at:          at,
+	}
+}
+
+func (aes *asOfEventSource) Get(ctx context.Context, id string) (domain.Event, error) {
+	event, err := aes.EventSource.Get(ctx, id)
+	if err != nil {
+		return domain.Event{}, err
+	}
+	if !aes.includes(event) {
+		return domain.Event{}, domain.ErrEventNotFound
+	}
+
+	return event, nil
+}
+
+func (aes *asOfEventSource) GetByCorrelationID(ctx context.Context, correlationID string) []domain.Event {
+	return aes.filter(aes.EventSource.GetByCorrelationID(ctx, correlationID))
+}
+
+func (aes *asOfEventSource) GetAll(ctx context.Context) []domain.Event {
+	return aes.filter(aes.EventSource.GetAll(ctx))
+}
+
+func (aes *asOfEventSource) Query(ctx context.Context, filter domain.Filter) (domain.EventPage, error) {
+	// The filter's window excludes its end, so end it just after the instant.
+	end := aes.at.Add(time.Nanosecond)
+	if filter.OccurredTo.IsZero() || filter.OccurredTo.After(end) {
+		filter.OccurredTo = end
+	}
+
+	return aes.EventSource.Query(ctx, filter)
+}
+
+func (aes *asOfEventSource) includes(event domain.Event) bool {
+	return !event.OccurredAt.After(aes.at)
+}
+
+func (aes *asOfEventSource) filter(events []domain.Event) []domain.Event {
+	var filtered []domain.Event
+	for _, event := range events {
+		if aes.includes(event) {
+			filtered = append(filtered, event)
+		}
+	}
+
+	return filtered
+}
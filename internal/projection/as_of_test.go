@@ -0,0 +1,50 @@
+package projection
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+	idgenerator "github.com/zucchinho/ocpp/internal/id_generator"
+	inmemoryeventsource "github.com/zucchinho/ocpp/internal/in_memory_event_source"
+)
+
+func TestAsOfEventSource(t *testing.T) {
+	// arrange
+	ctx := context.Background()
+	eventSource := inmemoryeventsource.NewInMemoryEventSource(idgenerator.NewULIDGenerator(), domain.OrderBySequence)
+	for i, occurredAt := range []time.Time{twoMinutesAgo, oneMinuteAgo, now} {
+		_, err := eventSource.Create(ctx, domain.Event{
+			ID:            fmt.Sprintf("event-%d", i+1),
+			CorrelationID: "correlation-1",
+			OccurredAt:    occurredAt,
+		})
+		require.NoError(t, err)
+	}
+	want := []string{"event-1", "event-2"}
+
+	// act
+	asOf := newAsOfEventSource(newAsOfEventSource(eventSource, now), oneMinuteAgo)
+
+	// assert
+	assert.Equal(t, want, eventIDs(asOf.GetAll(ctx)))
+	assert.Equal(t, want, eventIDs(asOf.GetByCorrelationID(ctx, "correlation-1")))
+	page, err := asOf.Query(ctx, domain.Filter{})
+	assert.NoError(t, err)
+	assert.Equal(t, want, eventIDs(page.Events))
+	_, err = asOf.Get(ctx, "event-3")
+	assert.ErrorIs(t, err, domain.ErrEventNotFound)
+}
+
+func eventIDs(events []domain.Event) []string {
+	var ids []string
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+
+	return ids
+}
@@ -2,11 +2,9 @@ package projection
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
-	"github.com/mitchellh/mapstructure"
 	"github.com/zucchinho/ocpp/internal/domain"
 )
 
@@ -32,24 +30,293 @@ func (bp *BasicProjection) NumChargingStations(ctx context.Context) (int, error)
 
 func (bp *BasicProjection) NumConnectors(ctx context.Context, stationID string) (int, error) {
 	// Iterate through all events and find the latest event for the given stationID.
-	latestEvents, err := bp.getLatestEventsForStationID(ctx, stationID)
+	stationEvents, err := bp.getStationEvents(ctx, stationID)
 	if err != nil {
-		return 0, fmt.Errorf("get latest events for station ID: %w", err)
+		return 0, fmt.Errorf("get station events: %w", err)
 	}
 
 	// If there are no events for the stationID, return an error.
-	if len(latestEvents) == 0 {
-		return 0, errors.New("charging station not found")
+	if len(stationEvents.latest) == 0 {
+		return 0, domain.ErrChargingStationNotFound
 	}
 
+	return numConnectorsFromLatestEvents(stationEvents.latest)
+}
+
+func (bp *BasicProjection) ChargingStation(ctx context.Context, stationID string) (domain.ChargingStation, error) {
+	stationEvents, err := bp.getStationEvents(ctx, stationID)
+	if err != nil {
+		return domain.ChargingStation{}, fmt.Errorf("get station events: %w", err)
+	}
+
+	// If there are no events for the stationID, return an error.
+	if len(stationEvents.latest) == 0 {
+		return domain.ChargingStation{}, domain.ErrChargingStationNotFound
+	}
+
+	return chargingStationFromStationEvents(stationID, stationEvents)
+}
+
+func (bp *BasicProjection) ChargingStations(ctx context.Context) ([]domain.ChargingStation, error) {
+	var chargingStations []domain.ChargingStation
+
+	stationIDs, err := bp.getStationIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get station IDs: %w", err)
+	}
+
+	for _, stationID := range stationIDs {
+		chargingStation, err := bp.ChargingStation(ctx, stationID)
+		if err == nil {
+			chargingStations = append(chargingStations, chargingStation)
+		}
+	}
+
+	return chargingStations, nil
+}
+
+func (bp *BasicProjection) ConnectorConsistency(ctx context.Context, stationID string) (domain.ConnectorConsistency, error) {
+	stationEvents, err := bp.getStationEvents(ctx, stationID)
+	if err != nil {
+		return domain.ConnectorConsistency{}, fmt.Errorf("get station events: %w", err)
+	}
+
+	// If there are no events for the stationID, return an error.
+	if len(stationEvents.latest) == 0 {
+		return domain.ConnectorConsistency{}, domain.ErrChargingStationNotFound
+	}
+
+	return connectorConsistencyFromLatestEvents(stationID, stationEvents.latest)
+}
+
+func (bp *BasicProjection) ConnectorReadings(ctx context.Context, stationID string, connectorID int32, from, to time.Time) ([]domain.ConnectorReading, error) {
+	readings, err := bp.stationReadings(ctx, stationID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectConnectorReadings(readings, connectorID, from, to), nil
+}
+
+func (bp *BasicProjection) EnergyDelivered(ctx context.Context, stationID string, connectorID int32, from, to time.Time) (domain.EnergyDelivered, error) {
+	// The readings either side of the period are needed to find the meter readings at its boundaries.
+	readings, err := bp.stationReadings(ctx, stationID, time.Time{}, time.Time{})
+	if err != nil {
+		return domain.EnergyDelivered{}, err
+	}
+
+	return energyDelivered(readings, stationID, connectorID, from, to)
+}
+
+func (bp *BasicProjection) StationEnergyDelivered(ctx context.Context, stationID string, from, to time.Time) (domain.StationEnergyDelivered, error) {
+	readings, err := bp.stationReadings(ctx, stationID, time.Time{}, time.Time{})
+	if err != nil {
+		return domain.StationEnergyDelivered{}, err
+	}
+
+	return stationEnergyDelivered(readings, stationID, from, to)
+}
+
+func (bp *BasicProjection) ReadingAnomalies(ctx context.Context, stationID string) ([]domain.ReadingAnomaly, error) {
+	readings, err := bp.stationReadings(ctx, stationID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	return readingAnomalies(readings), nil
+}
+
+func (bp *BasicProjection) Correlations(ctx context.Context, at time.Time, deadline time.Duration) (domain.CorrelationReport, error) {
+	return correlationReport(bp.eventSource.GetAll(ctx), at, deadline)
+}
+
+func (bp *BasicProjection) RequestLatencies(ctx context.Context) ([]domain.StationLatencies, error) {
+	return requestLatencies(bp.eventSource.GetAll(ctx)), nil
+}
+
+func (bp *BasicProjection) ValidationLog(ctx context.Context, filter domain.ValidationLogFilter) ([]domain.ResponseViolation, error) {
+	return validationLog(bp.eventSource.GetAll(ctx), filter)
+}
+
+func (bp *BasicProjection) ConnectorStatusTransitions(ctx context.Context, stationID string) ([]domain.ConnectorStatusTransition, error) {
+	page, err := bp.eventSource.Query(ctx, domain.Filter{
+		MessageTypes: []string{domain.EventTypeStatusNotification},
+		StationID:    stationID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query events: %w", err)
+	}
+
+	_, transitions, err := connectorStatuses(stationID, page.Events)
+	return transitions, err
+}
+
+func (bp *BasicProjection) Sessions(ctx context.Context, stationID string) ([]domain.Session, error) {
+	// Responses are attributed to the station of their request by the event source, so can be selected by station.
+	page, err := bp.eventSource.Query(ctx, domain.Filter{
+		MessageTypes: transactionEventTypes,
+		StationID:    stationID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query events: %w", err)
+	}
+	readings, err := bp.stationReadings(ctx, stationID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions(page.Events, readings, stationID)
+}
+
+func (bp *BasicProjection) IDTags(ctx context.Context) ([]domain.IDTagUsage, error) {
+	return idTagUsage(bp.eventSource.GetAll(ctx))
+}
+
+func (bp *BasicProjection) Authorizations(ctx context.Context) ([]domain.StationAuthorizations, error) {
+	return authorizations(bp.eventSource.GetAll(ctx))
+}
+
+func (bp *BasicProjection) Failures(ctx context.Context) ([]domain.RequestFailures, error) {
+	return failures(bp.eventSource.GetAll(ctx))
+}
+
+// stationReadings returns the readings of all of the station's connectors which occurred in the window, in the order
+// their events were created in.
+func (bp *BasicProjection) stationReadings(ctx context.Context, stationID string, from, to time.Time) ([]domain.ConnectorReading, error) {
+	// Responses are attributed to the station of their request by the event source, so can be selected by station.
+	page, err := bp.eventSource.Query(ctx, domain.Filter{
+		MessageTypes: meterValuesEventTypes,
+		StationID:    stationID,
+		OccurredFrom: from,
+		OccurredTo:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query events: %w", err)
+	}
+
+	var readings []domain.ConnectorReading
+	for _, event := range page.Events {
+		var request *domain.Event
+		if event.MessageType == domain.EventTypeMeterValuesResponse {
+			request = meterValuesRequest(bp.eventSource.GetByCorrelationID(ctx, event.CorrelationID))
+		}
+		eventReadings, err := readingsFromEvent(stationID, event, request)
+		if err != nil {
+			return nil, fmt.Errorf("readings from event %s: %w", event.ID, err)
+		}
+		readings = append(readings, eventReadings...)
+	}
+
+	return readings, nil
+}
+
+// AsOf returns a view of the projection over the events which occurred at or before the instant. As with the
+// projection itself, events created in the event source afterwards are taken into account.
+func (bp *BasicProjection) AsOf(at time.Time) domain.Projection {
+	return NewBasicProjection(newAsOfEventSource(bp.eventSource, at))
+}
+
+func (bp *BasicProjection) getStationIDs(ctx context.Context) ([]string, error) {
+	stationIDsMap := make(map[string]bool)
+	stationIDs := make([]string, 0)
+
+	for _, event := range bp.eventSource.GetAll(ctx) {
+		eventStationID, err := stationIDFromEvent(event)
+		if err != nil {
+			return nil, fmt.Errorf("station ID from event: %w", err)
+		}
+
+		// If the stationID is not in the map, add it.
+		if !stationIDsMap[eventStationID] && eventStationID != "" {
+			stationIDsMap[eventStationID] = true
+			stationIDs = append(stationIDs, eventStationID)
+		}
+	}
+
+	return stationIDs, nil
+}
+
+// getStationEvents returns the events of the station its read model is built from.
+func (bp *BasicProjection) getStationEvents(ctx context.Context, stationID string) (stationEvents, error) {
+	latestEvents := make(map[string]domain.Event, 0)
+	var statusNotifications []domain.Event
+	var latestOccurredAt time.Time
+
+	// Iterate through all events and find the latest event for the given stationID.
+	for _, event := range bp.eventSource.GetAll(ctx) {
+		eventStationID, err := stationIDFromEvent(event)
+		if err != nil {
+			return stationEvents{}, fmt.Errorf("station ID from event: %w", err)
+		}
+
+		if event.OccurredAt.After(latestOccurredAt) {
+			latestOccurredAt = event.OccurredAt
+		}
+
+		if eventStationID == stationID {
+			if existingEventForType, ok := latestEvents[event.MessageType]; !ok || event.After(existingEventForType) {
+				latestEvents[event.MessageType] = event
+			}
+			if event.MessageType == domain.EventTypeStatusNotification {
+				statusNotifications = append(statusNotifications, event)
+			}
+		}
+	}
+
+	// For each of the latest requests, find the latest corresponding response event (with the same correlation ID).
+	for _, requestType := range domain.MessageTypeNames(domain.MessageKindRequest) {
+		requestEvent, ok := latestEvents[requestType]
+		if !ok {
+			continue
+		}
+		requestMessageType, _ := domain.LookupMessageType(requestType)
+
+		var latestCorrespondingResponseEvent *domain.Event
+		for _, event := range bp.eventSource.GetByCorrelationID(ctx, requestEvent.CorrelationID) {
+			event := event
+			if event.MessageType == requestMessageType.ResponseType && (latestCorrespondingResponseEvent == nil || event.After(*latestCorrespondingResponseEvent)) {
+				latestCorrespondingResponseEvent = &event
+			}
+		}
+
+		if latestCorrespondingResponseEvent != nil {
+			latestEvents[requestMessageType.ResponseType] = *latestCorrespondingResponseEvent
+		}
+	}
+
+	return stationEvents{
+		latest:              latestEvents,
+		statusNotifications: statusNotifications,
+		now:                 latestOccurredAt,
+	}, nil
+}
+
+// stationIDFromEvent returns the station ID carried by the event's payload, or an empty string if the event
+// does not carry one (e.g. responses, which are attributed to a station through their request). It returns
+// domain.ErrUnknownMessageType if the event's message type isn't registered.
+func stationIDFromEvent(event domain.Event) (string, error) {
+	payload, err := domain.EventPayload(event)
+	if err != nil {
+		return "", fmt.Errorf("event payload: %w", err)
+	}
+	messageType, _ := domain.LookupMessageType(event.MessageType)
+
+	return messageType.StationID(payload), nil
+}
+
+// numConnectorsFromLatestEvents returns the number of connectors claimed by the most recent of the given events. A
+// MeterValuesResponse claims the connectors of all of its meter values, even those its request didn't ask for, which
+// are only left out of the connectors' readings.
+func numConnectorsFromLatestEvents(latestEvents map[string]domain.Event) (int, error) {
 	var latestRelevantEvent *domain.Event
 	var numConnectors int
 	for _, event := range latestEvents {
+		event := event
 		// If the event is newer than the latest event, update the latest event.
-		if latestRelevantEvent == nil || event.OccurredAt.After(latestRelevantEvent.OccurredAt) {
-			payload, err := convertEventPayload(event)
+		if latestRelevantEvent == nil || event.After(*latestRelevantEvent) {
+			payload, err := domain.EventPayload(event)
 			if err != nil {
-				return 0, fmt.Errorf("failed to convert event payload: %w", err)
+				return 0, fmt.Errorf("failed to get event payload: %w", err)
 			}
 			switch event.MessageType {
 			case domain.EventTypeConnectorListResponse:
@@ -68,32 +335,40 @@ func (bp *BasicProjection) NumConnectors(ctx context.Context, stationID string)
 	return numConnectors, nil
 }
 
-func (bp *BasicProjection) ChargingStation(ctx context.Context, stationID string) (domain.ChargingStation, error) {
-	latestEvents, err := bp.getLatestEventsForStationID(ctx, stationID)
-	if err != nil {
-		return domain.ChargingStation{}, fmt.Errorf("get latest events for station ID: %w", err)
-	}
-
-	// If there are no events for the stationID, return an error.
-	if len(latestEvents) == 0 {
-		return domain.ChargingStation{}, errors.New("charging station not found")
-	}
+// stationEvents are the events of a station its read model is built from.
+type stationEvents struct {
+	// latest holds the latest event of each message type for the station, including the latest response to each of
+	// its latest requests.
+	latest map[string]domain.Event
+	// statusNotifications holds the station's StatusNotification events.
+	statusNotifications []domain.Event
+	// now is when the latest event of any station occurred, the instant the station is judged online at.
+	now time.Time
+}
 
-	// If there are events for the stationID, create a charging station from the latest events.
+// chargingStationFromStationEvents creates a charging station from the station's events.
+func chargingStationFromStationEvents(stationID string, stationEvents stationEvents) (domain.ChargingStation, error) {
+	latestEvents := stationEvents.latest
 	var connectors []domain.Connector
 	var latestEventTime time.Time
+	// meterValueConnectorIDs are the connectors with meter values, including those a MeterValuesResponse's request
+	// didn't ask for, which are only left out of the connectors' readings.
+	meterValueConnectorIDs := make(map[int32]bool)
 
 	// If there is a MeterValuesNotification event, use it to create the connectors.
-	if meterValuesNotificationEvent, ok := latestEvents[domain.EventTypeMeterValuesNotification]; ok {
-		payload, err := convertEventPayload(meterValuesNotificationEvent)
+	meterValuesNotificationEvent, hasMeterValuesNotificationEvent := latestEvents[domain.EventTypeMeterValuesNotification]
+	if hasMeterValuesNotificationEvent {
+		payload, err := domain.EventPayload(meterValuesNotificationEvent)
 		if err != nil {
-			return domain.ChargingStation{}, fmt.Errorf("failed to convert event payload: %w", err)
+			return domain.ChargingStation{}, fmt.Errorf("failed to get event payload: %w", err)
 		}
 		for _, meterValue := range payload.(domain.MeterValuesNotificationPayload).MeterValues {
+			meterValueConnectorIDs[meterValue.ConnectorID] = true
 			connectors = append(connectors, domain.Connector{
 				ID:                meterValue.ConnectorID,
 				ChargingStationID: stationID,
 				Reading:           meterValue.Reading,
+				ReadingValue:      meterValue.Value,
 				UpdatedAt:         meterValuesNotificationEvent.OccurredAt,
 			})
 		}
@@ -103,34 +378,48 @@ func (bp *BasicProjection) ChargingStation(ctx context.Context, stationID string
 
 	// If there is a MeterValuesResponse event, use it to create/update the connectors.
 	if meterValuesResponseEvent, ok := latestEvents[domain.EventTypeMeterValuesResponse]; ok {
-		payload, err := convertEventPayload(meterValuesResponseEvent)
+		payload, err := domain.EventPayload(meterValuesResponseEvent)
 		if err != nil {
-			return domain.ChargingStation{}, fmt.Errorf("failed to convert event payload: %w", err)
+			return domain.ChargingStation{}, fmt.Errorf("failed to get event payload: %w", err)
 		}
 		for _, meterValue := range payload.(domain.MeterValuesResponsePayload).MeterValues {
-			var existingConnector *domain.Connector
-			var connectorIdx int
+			meterValueConnectorIDs[meterValue.ConnectorID] = true
+		}
+		// Only the meter values for the connector which was asked for are trusted.
+		var request *domain.Event
+		if meterValuesRequestEvent, ok := latestEvents[domain.EventTypeMeterValuesRequest]; ok {
+			request = &meterValuesRequestEvent
+		}
+		meterValues, err := requestedMeterValues(request, payload.(domain.MeterValuesResponsePayload))
+		if err != nil {
+			return domain.ChargingStation{}, fmt.Errorf("failed to get requested meter values: %w", err)
+		}
+		for _, meterValue := range meterValues {
+			connectorIdx := -1
 			for i, connector := range connectors {
 				if connector.ID == meterValue.ConnectorID {
-					existingConnector = &connector
 					connectorIdx = i
 					break
 				}
 			}
 
-			// If the connector exists and the MeterValuesResponse event is newer, update the connector.
-			if existingConnector != nil && existingConnector.UpdatedAt.Before(meterValuesResponseEvent.OccurredAt) {
-				existingConnector.Reading = meterValue.Reading
-				existingConnector.UpdatedAt = meterValuesResponseEvent.OccurredAt
-
-				connectors[connectorIdx] = *existingConnector
-			} else {
+			if connectorIdx == -1 {
 				connectors = append(connectors, domain.Connector{
 					ID:                meterValue.ConnectorID,
 					ChargingStationID: stationID,
 					Reading:           meterValue.Reading,
+					ReadingValue:      meterValue.Value,
 					UpdatedAt:         meterValuesResponseEvent.OccurredAt,
 				})
+				continue
+			}
+
+			// If the connector exists and the MeterValuesResponse event is newer than the MeterValuesNotification event
+			// it was created from, update the connector.
+			if meterValuesResponseEvent.After(meterValuesNotificationEvent) {
+				connectors[connectorIdx].Reading = meterValue.Reading
+				connectors[connectorIdx].ReadingValue = meterValue.Value
+				connectors[connectorIdx].UpdatedAt = meterValuesResponseEvent.OccurredAt
 			}
 		}
 
@@ -141,11 +430,11 @@ func (bp *BasicProjection) ChargingStation(ctx context.Context, stationID string
 
 	// If there is a ConnectorListResponse event, use it to get the number of connectors.
 	var numConnectors int
-	if len(connectors) == 0 {
+	if len(meterValueConnectorIDs) == 0 {
 		if connectorListResponseEvent, ok := latestEvents[domain.EventTypeConnectorListResponse]; ok {
-			payload, err := convertEventPayload(connectorListResponseEvent)
+			payload, err := domain.EventPayload(connectorListResponseEvent)
 			if err != nil {
-				return domain.ChargingStation{}, fmt.Errorf("failed to convert event payload: %w", err)
+				return domain.ChargingStation{}, fmt.Errorf("failed to get event payload: %w", err)
 			}
 			numConnectors = payload.(domain.ConnectorListResponsePayload).NumConnectors
 
@@ -154,160 +443,21 @@ func (bp *BasicProjection) ChargingStation(ctx context.Context, stationID string
 			}
 		}
 	} else {
-		numConnectors = len(connectors)
+		numConnectors = len(meterValueConnectorIDs)
 	}
 
-	return domain.ChargingStation{
+	chargingStation := domain.ChargingStation{
 		ID:            stationID,
 		NumConnectors: numConnectors,
 		Connectors:    connectors,
 		UpdatedAt:     latestEventTime,
-	}, nil
-}
-
-func (bp *BasicProjection) ChargingStations(ctx context.Context) ([]domain.ChargingStation, error) {
-	var chargingStations []domain.ChargingStation
-
-	stationIDs, err := bp.getStationIDs(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("get station IDs: %w", err)
-	}
-
-	for _, stationID := range stationIDs {
-		chargingStation, err := bp.ChargingStation(ctx, stationID)
-		if err == nil {
-			chargingStations = append(chargingStations, chargingStation)
-		}
-	}
-
-	return chargingStations, nil
-}
-
-func (bp *BasicProjection) getStationIDs(ctx context.Context) ([]string, error) {
-	stationIDsMap := make(map[string]bool)
-	stationIDs := make([]string, 0)
-
-	for _, event := range bp.eventSource.GetAll(ctx) {
-		payload, err := convertEventPayload(event)
-		if err != nil {
-			return nil, fmt.Errorf("convert event payload: %w", err)
-		}
-
-		var stationIDFromEvent string
-		switch event.MessageType {
-		case domain.EventTypeConnectorListRequest:
-			stationIDFromEvent = payload.(domain.ConnectorListRequestPayload).StationID
-		case domain.EventTypeMeterValuesNotification:
-			stationIDFromEvent = payload.(domain.MeterValuesNotificationPayload).StationID
-		case domain.EventTypeMeterValuesRequest:
-			stationIDFromEvent = payload.(domain.MeterValuesRequestPayload).StationID
-		}
-
-		// If the stationID is not in the map, add it.
-		if !stationIDsMap[stationIDFromEvent] && stationIDFromEvent != "" {
-			stationIDsMap[stationIDFromEvent] = true
-			stationIDs = append(stationIDs, stationIDFromEvent)
-		}
 	}
-
-	return stationIDs, nil
-}
-
-func (bp *BasicProjection) getLatestEventsForStationID(ctx context.Context, stationID string) (map[string]domain.Event, error) {
-	latestEvents := make(map[string]domain.Event, 0)
-
-	// Iterate through all events and find the latest event for the given stationID.
-	for _, event := range bp.eventSource.GetAll(ctx) {
-		payload, err := convertEventPayload(event)
-		if err != nil {
-			return nil, fmt.Errorf("convert event payload: %w", err)
-		}
-
-		var eventIsForStationID bool
-		switch event.MessageType {
-		case domain.EventTypeConnectorListRequest:
-			eventIsForStationID = payload.(domain.ConnectorListRequestPayload).StationID == stationID
-		case domain.EventTypeMeterValuesNotification:
-			eventIsForStationID = payload.(domain.MeterValuesNotificationPayload).StationID == stationID
-		case domain.EventTypeMeterValuesRequest:
-			eventIsForStationID = payload.(domain.MeterValuesRequestPayload).StationID == stationID
-		}
-
-		if eventIsForStationID {
-			if existingEventForType, ok := latestEvents[domain.EventTypeConnectorListRequest]; !ok || event.OccurredAt.After(existingEventForType.OccurredAt) {
-				latestEvents[event.MessageType] = event
-			}
-		}
+	if err := stationLifecycleFromLatestEvents(&chargingStation, latestEvents, stationEvents.now); err != nil {
+		return domain.ChargingStation{}, err
 	}
-
-	if len(latestEvents) > 0 {
-		// If there is a connector list request event, find the latest corresponding response event (with the same correlation ID).
-		if connectorListRequestEvent, ok := latestEvents[domain.EventTypeConnectorListRequest]; ok {
-			// Find the latest corresponding response event (with the same correlation ID).
-			var latestCorrespondingResponseEvent *domain.Event
-			for _, event := range bp.eventSource.GetByCorrelationID(ctx, connectorListRequestEvent.CorrelationID) {
-				if event.MessageType == domain.EventTypeConnectorListResponse && (latestCorrespondingResponseEvent == nil || event.OccurredAt.After(latestCorrespondingResponseEvent.OccurredAt)) {
-					latestCorrespondingResponseEvent = &event
-				}
-			}
-
-			if latestCorrespondingResponseEvent != nil {
-				latestEvents[domain.EventTypeConnectorListResponse] = *latestCorrespondingResponseEvent
-			}
-		}
-
-		// If there is a meter values request event, find the latest corresponding response event (with the same correlation ID).
-		if meterValuesRequestEvent, ok := latestEvents[domain.EventTypeMeterValuesRequest]; ok {
-			// Find the latest corresponding response event (with the same correlation ID).
-			var latestCorrespondingResponseEvent *domain.Event
-			for _, event := range bp.eventSource.GetByCorrelationID(ctx, meterValuesRequestEvent.CorrelationID) {
-				if event.MessageType == domain.EventTypeMeterValuesResponse && (latestCorrespondingResponseEvent == nil || event.OccurredAt.After(latestCorrespondingResponseEvent.OccurredAt)) {
-					latestCorrespondingResponseEvent = &event
-				}
-			}
-
-			if latestCorrespondingResponseEvent != nil {
-				latestEvents[domain.EventTypeMeterValuesResponse] = *latestCorrespondingResponseEvent
-			}
-		}
+	if err := applyConnectorStatuses(&chargingStation, stationEvents.statusNotifications); err != nil {
+		return domain.ChargingStation{}, err
 	}
 
-	return latestEvents, nil
-}
-
-func convertEventPayload(event domain.Event) (any, error) {
-	switch event.MessageType {
-	case domain.EventTypeMeterValuesRequest:
-		payload := domain.MeterValuesRequestPayload{}
-		if err := mapstructure.Decode(event.Payload, &payload); err != nil {
-			return nil, err
-		}
-		return payload, nil
-	case domain.EventTypeMeterValuesResponse:
-		payload := domain.MeterValuesResponsePayload{}
-		if err := mapstructure.Decode(event.Payload, &payload); err != nil {
-			return nil, err
-		}
-		return payload, nil
-	case domain.EventTypeMeterValuesNotification:
-		payload := domain.MeterValuesNotificationPayload{}
-		if err := mapstructure.Decode(event.Payload, &payload); err != nil {
-			return nil, err
-		}
-		return payload, nil
-	case domain.EventTypeConnectorListRequest:
-		payload := domain.ConnectorListRequestPayload{}
-		if err := mapstructure.Decode(event.Payload, &payload); err != nil {
-			return nil, err
-		}
-		return payload, nil
-	case domain.EventTypeConnectorListResponse:
-		payload := domain.ConnectorListResponsePayload{}
-		if err := mapstructure.Decode(event.Payload, &payload); err != nil {
-			return nil, err
-		}
-		return payload, nil
-	default:
-		return nil, fmt.Errorf("unknown event type: %s", event.MessageType)
-	}
+	return chargingStation, nil
 }
@@ -15,6 +15,19 @@ var now = time.Now().Round(0)
 var oneMinuteAgo = now.Add(-1 * time.Minute)
 var twoMinutesAgo = now.Add(-2 * time.Minute)
 
+// wh returns the reading of the value in Wh.
+func wh(value string) *domain.Reading {
+	return &domain.Reading{
+		Value: domain.MustParseDecimal(value),
+		Unit:  domain.UnitWh,
+	}
+}
+
+// readingValue returns the parsed reading, or nil if it can't be parsed.
+func readingValue(reading string) *domain.Reading {
+	return domain.NewMeterValue(0, reading).Value
+}
+
 func TestNewBasicProjection(t *testing.T) {
 	// arrange
 	ctrl := gomock.NewController(t)
@@ -49,8 +62,8 @@ func TestNumChargingStations(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeConnectorListRequest,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-1",
+					Payload: domain.ConnectorListRequestPayload{
+						StationID: "station-1",
 					},
 				},
 				{
@@ -59,8 +72,8 @@ func TestNumChargingStations(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeConnectorListResponse,
 					OccurredAt:    now,
-					Payload: map[string]any{
-						"NumConnectors": 2,
+					Payload: domain.ConnectorListResponsePayload{
+						NumConnectors: 2,
 					},
 				},
 				{
@@ -69,8 +82,8 @@ func TestNumChargingStations(t *testing.T) {
 					CorrelationID: "correlation-2",
 					MessageType:   domain.EventTypeConnectorListRequest,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-2",
+					Payload: domain.ConnectorListRequestPayload{
+						StationID: "station-2",
 					},
 				},
 				{
@@ -79,8 +92,8 @@ func TestNumChargingStations(t *testing.T) {
 					CorrelationID: "correlation-2",
 					MessageType:   domain.EventTypeConnectorListResponse,
 					OccurredAt:    now,
-					Payload: map[string]any{
-						"NumConnectors": 2,
+					Payload: domain.ConnectorListResponsePayload{
+						NumConnectors: 2,
 					},
 				},
 			},
@@ -95,8 +108,8 @@ func TestNumChargingStations(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeMeterValuesNotification,
 					OccurredAt:    now,
-					Payload: map[string]any{
-						"StationID": "station-1",
+					Payload: domain.MeterValuesNotificationPayload{
+						StationID: "station-1",
 					},
 				},
 			},
@@ -111,8 +124,8 @@ func TestNumChargingStations(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeMeterValuesRequest,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-1",
+					Payload: domain.MeterValuesRequestPayload{
+						StationID: "station-1",
 					},
 				},
 				{
@@ -121,8 +134,8 @@ func TestNumChargingStations(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeMeterValuesResponse,
 					OccurredAt:    now,
-					Payload: map[string]any{
-						"MeterValues": []domain.MeterValue{},
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{},
 					},
 				},
 			},
@@ -172,8 +185,8 @@ func TestNumConnectors(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeConnectorListRequest,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-1",
+					Payload: domain.ConnectorListRequestPayload{
+						StationID: "station-1",
 					},
 				},
 			},
@@ -191,8 +204,8 @@ func TestNumConnectors(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeConnectorListRequest,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-1",
+					Payload: domain.ConnectorListRequestPayload{
+						StationID: "station-1",
 					},
 				},
 				{
@@ -201,8 +214,8 @@ func TestNumConnectors(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeConnectorListResponse,
 					OccurredAt:    now,
-					Payload: map[string]any{
-						"NumConnectors": 2,
+					Payload: domain.ConnectorListResponsePayload{
+						NumConnectors: 2,
 					},
 				},
 			},
@@ -219,21 +232,12 @@ func TestNumConnectors(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeMeterValuesNotification,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-1",
-						"MeterValues": []domain.MeterValue{
-							{
-								ConnectorID: 1,
-								Reading:     "100",
-							},
-							{
-								ConnectorID: 2,
-								Reading:     "200",
-							},
-							{
-								ConnectorID: 3,
-								Reading:     "300",
-							},
+					Payload: domain.MeterValuesNotificationPayload{
+						StationID: "station-1",
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
+							domain.NewMeterValue(2, "200"),
+							domain.NewMeterValue(3, "300"),
 						},
 					},
 				},
@@ -250,8 +254,8 @@ func TestNumConnectors(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeMeterValuesRequest,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-1",
+					Payload: domain.MeterValuesRequestPayload{
+						StationID: "station-1",
 					},
 				},
 				{
@@ -260,12 +264,9 @@ func TestNumConnectors(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeMeterValuesResponse,
 					OccurredAt:    now,
-					Payload: map[string]any{
-						"MeterValues": []domain.MeterValue{
-							{
-								ConnectorID: 1,
-								Reading:     "100",
-							},
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
 						},
 					},
 				},
@@ -274,6 +275,38 @@ func TestNumConnectors(t *testing.T) {
 			correlationID: "correlation-1",
 			want:          1,
 		},
+		{
+			name: "meter values response to a request for one connector",
+			events: []domain.Event{
+				{
+					ID:            "event-1",
+					MessageID:     "message-1",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeMeterValuesRequest,
+					OccurredAt:    oneMinuteAgo,
+					Payload: domain.MeterValuesRequestPayload{
+						StationID:   "station-1",
+						ConnectorID: 1,
+					},
+				},
+				{
+					ID:            "event-2",
+					MessageID:     "message-2",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeMeterValuesResponse,
+					OccurredAt:    now,
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
+							domain.NewMeterValue(2, "200"),
+						},
+					},
+				},
+			},
+			stationID:     "station-1",
+			correlationID: "correlation-1",
+			want:          2,
+		},
 	}
 
 	for _, tt := range tests {
@@ -322,8 +355,8 @@ func TestChargingStation(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeMeterValuesRequest,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-1",
+					Payload: domain.MeterValuesRequestPayload{
+						StationID: "station-1",
 					},
 				},
 				{
@@ -332,12 +365,9 @@ func TestChargingStation(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeMeterValuesResponse,
 					OccurredAt:    now,
-					Payload: map[string]any{
-						"MeterValues": []domain.MeterValue{
-							{
-								ConnectorID: 1,
-								Reading:     "100",
-							},
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
 						},
 					},
 				},
@@ -356,8 +386,8 @@ func TestChargingStation(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeConnectorListRequest,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-1",
+					Payload: domain.ConnectorListRequestPayload{
+						StationID: "station-1",
 					},
 				},
 				{
@@ -366,8 +396,8 @@ func TestChargingStation(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeConnectorListResponse,
 					OccurredAt:    now,
-					Payload: map[string]any{
-						"NumConnectors": 2,
+					Payload: domain.ConnectorListResponsePayload{
+						NumConnectors: 2,
 					},
 				},
 			},
@@ -388,21 +418,12 @@ func TestChargingStation(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeMeterValuesNotification,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-1",
-						"MeterValues": []domain.MeterValue{
-							{
-								ConnectorID: 1,
-								Reading:     "100",
-							},
-							{
-								ConnectorID: 2,
-								Reading:     "200",
-							},
-							{
-								ConnectorID: 3,
-								Reading:     "300",
-							},
+					Payload: domain.MeterValuesNotificationPayload{
+						StationID: "station-1",
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
+							domain.NewMeterValue(2, "200"),
+							domain.NewMeterValue(3, "300"),
 						},
 					},
 				},
@@ -418,18 +439,21 @@ func TestChargingStation(t *testing.T) {
 						ID:                1,
 						ChargingStationID: "station-1",
 						Reading:           "100",
+						ReadingValue:      wh("100"),
 						UpdatedAt:         oneMinuteAgo,
 					},
 					{
 						ID:                2,
 						ChargingStationID: "station-1",
 						Reading:           "200",
+						ReadingValue:      wh("200"),
 						UpdatedAt:         oneMinuteAgo,
 					},
 					{
 						ID:                3,
 						ChargingStationID: "station-1",
 						Reading:           "300",
+						ReadingValue:      wh("300"),
 						UpdatedAt:         oneMinuteAgo,
 					},
 				},
@@ -444,8 +468,8 @@ func TestChargingStation(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeMeterValuesRequest,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-1",
+					Payload: domain.MeterValuesRequestPayload{
+						StationID: "station-1",
 					},
 				},
 				{
@@ -454,12 +478,9 @@ func TestChargingStation(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeMeterValuesResponse,
 					OccurredAt:    now,
-					Payload: map[string]any{
-						"MeterValues": []domain.MeterValue{
-							{
-								ConnectorID: 1,
-								Reading:     "100",
-							},
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
 						},
 					},
 				},
@@ -475,6 +496,7 @@ func TestChargingStation(t *testing.T) {
 						ID:                1,
 						ChargingStationID: "station-1",
 						Reading:           "100",
+						ReadingValue:      wh("100"),
 						UpdatedAt:         now,
 					},
 				},
@@ -489,21 +511,12 @@ func TestChargingStation(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeMeterValuesNotification,
 					OccurredAt:    twoMinutesAgo,
-					Payload: map[string]any{
-						"StationID": "station-1",
-						"MeterValues": []domain.MeterValue{
-							{
-								ConnectorID: 1,
-								Reading:     "100",
-							},
-							{
-								ConnectorID: 2,
-								Reading:     "200",
-							},
-							{
-								ConnectorID: 3,
-								Reading:     "300",
-							},
+					Payload: domain.MeterValuesNotificationPayload{
+						StationID: "station-1",
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
+							domain.NewMeterValue(2, "200"),
+							domain.NewMeterValue(3, "300"),
 						},
 					},
 				},
@@ -513,8 +526,8 @@ func TestChargingStation(t *testing.T) {
 					CorrelationID: "correlation-2",
 					MessageType:   domain.EventTypeMeterValuesRequest,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-1",
+					Payload: domain.MeterValuesRequestPayload{
+						StationID: "station-1",
 					},
 				},
 				{
@@ -523,12 +536,9 @@ func TestChargingStation(t *testing.T) {
 					CorrelationID: "correlation-2",
 					MessageType:   domain.EventTypeMeterValuesResponse,
 					OccurredAt:    now,
-					Payload: map[string]any{
-						"MeterValues": []domain.MeterValue{
-							{
-								ConnectorID: 1,
-								Reading:     "120",
-							},
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "120"),
 						},
 					},
 				},
@@ -544,24 +554,291 @@ func TestChargingStation(t *testing.T) {
 						ID:                1,
 						ChargingStationID: "station-1",
 						// The reading from the later MeterValuesResponse event should be used.
-						Reading:   "120",
-						UpdatedAt: now,
+						Reading:      "120",
+						ReadingValue: wh("120"),
+						UpdatedAt:    now,
 					},
 					{
 						ID:                2,
 						ChargingStationID: "station-1",
 						Reading:           "200",
+						ReadingValue:      wh("200"),
 						UpdatedAt:         twoMinutesAgo,
 					},
 					{
 						ID:                3,
 						ChargingStationID: "station-1",
 						Reading:           "300",
+						ReadingValue:      wh("300"),
 						UpdatedAt:         twoMinutesAgo,
 					},
 				},
 			},
 		},
+		{
+			name: "meter values notification and meter values request/response: connector reading not updated by older response",
+			events: []domain.Event{
+				{
+					ID:            "event-1",
+					MessageID:     "message-1",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeMeterValuesNotification,
+					OccurredAt:    now,
+					Payload: domain.MeterValuesNotificationPayload{
+						StationID: "station-1",
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "120"),
+							domain.NewMeterValue(2, "200"),
+						},
+					},
+				},
+				{
+					ID:            "event-2",
+					MessageID:     "message-2",
+					CorrelationID: "correlation-2",
+					MessageType:   domain.EventTypeMeterValuesRequest,
+					OccurredAt:    twoMinutesAgo,
+					Payload: domain.MeterValuesRequestPayload{
+						StationID: "station-1",
+					},
+				},
+				{
+					ID:            "event-3",
+					MessageID:     "message-3",
+					CorrelationID: "correlation-2",
+					MessageType:   domain.EventTypeMeterValuesResponse,
+					OccurredAt:    oneMinuteAgo,
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
+						},
+					},
+				},
+			},
+			stationID:     "station-1",
+			correlationID: "correlation-2",
+			want: domain.ChargingStation{
+				ID:            "station-1",
+				NumConnectors: 2,
+				UpdatedAt:     now,
+				Connectors: []domain.Connector{
+					{
+						ID:                1,
+						ChargingStationID: "station-1",
+						// The reading from the later MeterValuesNotification event should be kept, without duplicating the connector.
+						Reading:      "120",
+						ReadingValue: wh("120"),
+						UpdatedAt:    now,
+					},
+					{
+						ID:                2,
+						ChargingStationID: "station-1",
+						Reading:           "200",
+						ReadingValue:      wh("200"),
+						UpdatedAt:         now,
+					},
+				},
+			},
+		},
+		{
+			name: "meter values notification and meter values request/response at the same time: later sequence wins",
+			events: []domain.Event{
+				{
+					ID:            "event-1",
+					MessageID:     "message-1",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeMeterValuesNotification,
+					OccurredAt:    now,
+					Sequence:      1,
+					Payload: domain.MeterValuesNotificationPayload{
+						StationID: "station-1",
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
+						},
+					},
+				},
+				{
+					ID:            "event-2",
+					MessageID:     "message-2",
+					CorrelationID: "correlation-2",
+					MessageType:   domain.EventTypeMeterValuesRequest,
+					OccurredAt:    oneMinuteAgo,
+					Sequence:      2,
+					Payload: domain.MeterValuesRequestPayload{
+						StationID: "station-1",
+					},
+				},
+				{
+					ID:            "event-3",
+					MessageID:     "message-3",
+					CorrelationID: "correlation-2",
+					MessageType:   domain.EventTypeMeterValuesResponse,
+					OccurredAt:    now,
+					Sequence:      3,
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "120"),
+						},
+					},
+				},
+			},
+			stationID:     "station-1",
+			correlationID: "correlation-2",
+			want: domain.ChargingStation{
+				ID:            "station-1",
+				NumConnectors: 1,
+				UpdatedAt:     now,
+				Connectors: []domain.Connector{
+					{
+						ID:                1,
+						ChargingStationID: "station-1",
+						Reading:           "120",
+						ReadingValue:      wh("120"),
+						UpdatedAt:         now,
+					},
+				},
+			},
+		},
+		{
+			name: "meter values request/response: unrequested connectors counted, but their readings ignored",
+			events: []domain.Event{
+				{
+					ID:            "event-1",
+					MessageID:     "message-1",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeMeterValuesRequest,
+					OccurredAt:    oneMinuteAgo,
+					Payload: domain.MeterValuesRequestPayload{
+						StationID:   "station-1",
+						ConnectorID: 2,
+					},
+				},
+				{
+					ID:            "event-2",
+					MessageID:     "message-2",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeMeterValuesResponse,
+					OccurredAt:    now,
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
+							domain.NewMeterValue(2, "200"),
+						},
+					},
+				},
+			},
+			stationID:     "station-1",
+			correlationID: "correlation-1",
+			want: domain.ChargingStation{
+				ID:            "station-1",
+				NumConnectors: 2,
+				UpdatedAt:     now,
+				Connectors: []domain.Connector{
+					{
+						ID:                2,
+						ChargingStationID: "station-1",
+						Reading:           "200",
+						ReadingValue:      wh("200"),
+						UpdatedAt:         now,
+					},
+				},
+			},
+		},
+		{
+			// The latest event of each message type used to be chosen by comparing against the latest
+			// ConnectorListRequest, so the last event in the event source's order won when there wasn't one.
+			name: "meter values notifications out of order: latest notification kept",
+			events: []domain.Event{
+				{
+					ID:            "event-1",
+					MessageID:     "message-1",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeMeterValuesNotification,
+					OccurredAt:    now,
+					Payload: domain.MeterValuesNotificationPayload{
+						StationID:   "station-1",
+						MeterValues: []domain.MeterValue{domain.NewMeterValue(1, "200")},
+					},
+				},
+				{
+					ID:            "event-2",
+					MessageID:     "message-2",
+					CorrelationID: "correlation-2",
+					MessageType:   domain.EventTypeMeterValuesNotification,
+					OccurredAt:    oneMinuteAgo,
+					Payload: domain.MeterValuesNotificationPayload{
+						StationID:   "station-1",
+						MeterValues: []domain.MeterValue{domain.NewMeterValue(1, "100")},
+					},
+				},
+			},
+			stationID: "station-1",
+			want: domain.ChargingStation{
+				ID:            "station-1",
+				NumConnectors: 1,
+				UpdatedAt:     now,
+				Connectors: []domain.Connector{
+					{
+						ID:                1,
+						ChargingStationID: "station-1",
+						Reading:           "200",
+						ReadingValue:      wh("200"),
+						UpdatedAt:         now,
+					},
+				},
+			},
+		},
+		{
+			// The latest response used to be taken by the address of the loop variable, so it was the last response
+			// in the event source's order.
+			name: "meter values request/responses out of order: latest response kept",
+			events: []domain.Event{
+				{
+					ID:            "event-1",
+					MessageID:     "message-1",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeMeterValuesRequest,
+					OccurredAt:    twoMinutesAgo,
+					Payload:       domain.MeterValuesRequestPayload{StationID: "station-1"},
+				},
+				{
+					ID:            "event-2",
+					MessageID:     "message-3",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeMeterValuesResponse,
+					OccurredAt:    now,
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{domain.NewMeterValue(1, "200")},
+					},
+				},
+				{
+					ID:            "event-3",
+					MessageID:     "message-2",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeMeterValuesResponse,
+					OccurredAt:    oneMinuteAgo,
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{domain.NewMeterValue(1, "100")},
+					},
+				},
+			},
+			stationID:     "station-1",
+			correlationID: "correlation-1",
+			want: domain.ChargingStation{
+				ID:            "station-1",
+				NumConnectors: 1,
+				UpdatedAt:     now,
+				Connectors: []domain.Connector{
+					{
+						ID:                1,
+						ChargingStationID: "station-1",
+						Reading:           "200",
+						ReadingValue:      wh("200"),
+						UpdatedAt:         now,
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -614,8 +891,8 @@ func TestChargingStations(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeConnectorListRequest,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-1",
+					Payload: domain.ConnectorListRequestPayload{
+						StationID: "station-1",
 					},
 				},
 				{
@@ -624,8 +901,8 @@ func TestChargingStations(t *testing.T) {
 					CorrelationID: "correlation-1",
 					MessageType:   domain.EventTypeConnectorListResponse,
 					OccurredAt:    now,
-					Payload: map[string]any{
-						"NumConnectors": 2,
+					Payload: domain.ConnectorListResponsePayload{
+						NumConnectors: 2,
 					},
 				},
 				{
@@ -634,8 +911,8 @@ func TestChargingStations(t *testing.T) {
 					CorrelationID: "correlation-2",
 					MessageType:   domain.EventTypeConnectorListRequest,
 					OccurredAt:    oneMinuteAgo,
-					Payload: map[string]any{
-						"StationID": "station-2",
+					Payload: domain.ConnectorListRequestPayload{
+						StationID: "station-2",
 					},
 				},
 				{
@@ -644,8 +921,8 @@ func TestChargingStations(t *testing.T) {
 					CorrelationID: "correlation-2",
 					MessageType:   domain.EventTypeConnectorListResponse,
 					OccurredAt:    now,
-					Payload: map[string]any{
-						"NumConnectors": 2,
+					Payload: domain.ConnectorListResponsePayload{
+						NumConnectors: 2,
 					},
 				},
 			},
@@ -702,3 +979,203 @@ func getCorrelatedEvents(events []domain.Event, correlationID string) []domain.E
 	}
 	return correlatedEvents
 }
+
+func TestAsOf(t *testing.T) {
+	events := []domain.Event{
+		{
+			ID:            "event-1",
+			MessageID:     "message-1",
+			CorrelationID: "correlation-1",
+			MessageType:   domain.EventTypeMeterValuesNotification,
+			OccurredAt:    twoMinutesAgo,
+			Payload: domain.MeterValuesNotificationPayload{
+				StationID: "station-1",
+				MeterValues: []domain.MeterValue{
+					domain.NewMeterValue(1, "100"),
+				},
+			},
+		},
+		{
+			ID:            "event-2",
+			MessageID:     "message-2",
+			CorrelationID: "correlation-2",
+			MessageType:   domain.EventTypeMeterValuesNotification,
+			OccurredAt:    now,
+			Payload: domain.MeterValuesNotificationPayload{
+				StationID: "station-1",
+				MeterValues: []domain.MeterValue{
+					domain.NewMeterValue(1, "120"),
+				},
+			},
+		},
+		{
+			ID:            "event-3",
+			MessageID:     "message-3",
+			CorrelationID: "correlation-3",
+			MessageType:   domain.EventTypeMeterValuesNotification,
+			OccurredAt:    now,
+			Payload: domain.MeterValuesNotificationPayload{
+				StationID: "station-2",
+				MeterValues: []domain.MeterValue{
+					domain.NewMeterValue(1, "200"),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		stationID string
+		want      domain.ChargingStation
+		wantErr   error
+	}{
+		{
+			name:      "before the latest event",
+			at:        oneMinuteAgo,
+			stationID: "station-1",
+			want: domain.ChargingStation{
+				ID:            "station-1",
+				NumConnectors: 1,
+				UpdatedAt:     twoMinutesAgo,
+				Connectors: []domain.Connector{
+					{
+						ID:                1,
+						ChargingStationID: "station-1",
+						Reading:           "100",
+						ReadingValue:      wh("100"),
+						UpdatedAt:         twoMinutesAgo,
+					},
+				},
+			},
+		},
+		{
+			name:      "at the latest event",
+			at:        now,
+			stationID: "station-1",
+			want: domain.ChargingStation{
+				ID:            "station-1",
+				NumConnectors: 1,
+				UpdatedAt:     now,
+				Connectors: []domain.Connector{
+					{
+						ID:                1,
+						ChargingStationID: "station-1",
+						Reading:           "120",
+						ReadingValue:      wh("120"),
+						UpdatedAt:         now,
+					},
+				},
+			},
+		},
+		{
+			name:      "before the first event",
+			at:        oneMinuteAgo,
+			stationID: "station-2",
+			wantErr:   domain.ErrChargingStationNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			ctrl := gomock.NewController(t)
+			mockEventSource := mock.NewMockEventSource(ctrl)
+			bp := NewBasicProjection(mockEventSource)
+
+			mockEventSource.EXPECT().GetAll(gomock.Any()).Return(events)
+
+			// act
+			got, err := bp.AsOf(tt.at).ChargingStation(context.Background(), tt.stationID)
+
+			// assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConnectorReadings(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	mockEventSource := mock.NewMockEventSource(ctrl)
+	bp := NewBasicProjection(mockEventSource)
+
+	mockEventSource.EXPECT().Query(gomock.Any(), domain.Filter{
+		MessageTypes: []string{domain.EventTypeMeterValuesNotification, domain.EventTypeMeterValuesResponse},
+		StationID:    "station-1",
+		OccurredFrom: twoMinutesAgo,
+	}).Return(domain.EventPage{
+		Events: []domain.Event{
+			{
+				ID:            "event-1",
+				MessageID:     "message-1",
+				CorrelationID: "correlation-1",
+				MessageType:   domain.EventTypeMeterValuesNotification,
+				OccurredAt:    now,
+				StationID:     "station-1",
+				Payload: domain.MeterValuesNotificationPayload{
+					StationID: "station-1",
+					MeterValues: []domain.MeterValue{
+						domain.NewMeterValue(1, "120"),
+						domain.NewMeterValue(2, "200"),
+					},
+				},
+			},
+			{
+				ID:            "event-3",
+				MessageID:     "message-3",
+				CorrelationID: "correlation-2",
+				MessageType:   domain.EventTypeMeterValuesResponse,
+				OccurredAt:    oneMinuteAgo,
+				StationID:     "station-1",
+				Payload: domain.MeterValuesResponsePayload{
+					MeterValues: []domain.MeterValue{
+						domain.NewMeterValue(1, "100"),
+					},
+				},
+			},
+		},
+	}, nil)
+	mockEventSource.EXPECT().GetByCorrelationID(gomock.Any(), "correlation-2").Return([]domain.Event{
+		{
+			ID:            "event-2",
+			MessageID:     "message-2",
+			CorrelationID: "correlation-2",
+			MessageType:   domain.EventTypeMeterValuesRequest,
+			OccurredAt:    oneMinuteAgo,
+			StationID:     "station-1",
+			Payload:       domain.MeterValuesRequestPayload{StationID: "station-1", ConnectorID: 1},
+		},
+	})
+
+	// act
+	got, err := bp.ConnectorReadings(context.Background(), "station-1", 1, twoMinutesAgo, time.Time{})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.ConnectorReading{
+		{
+			StationID:    "station-1",
+			ConnectorID:  1,
+			Reading:      "100",
+			ReadingValue: wh("100"),
+			OccurredAt:   oneMinuteAgo,
+			MessageType:  domain.EventTypeMeterValuesResponse,
+			EventID:      "event-3",
+		},
+		{
+			StationID:    "station-1",
+			ConnectorID:  1,
+			Reading:      "120",
+			ReadingValue: wh("120"),
+			OccurredAt:   now,
+			MessageType:  domain.EventTypeMeterValuesNotification,
+			EventID:      "event-1",
+		},
+	}, got)
+}
@@ -0,0 +1,118 @@
+package projection
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// connectorConsistencyFromLatestEvents reports whether each of the latest events for the station agrees with the
+// current view of the station's number of connectors. Every meter value is evidence of its connector, including those
+// of a MeterValuesResponse its request didn't ask for.
+func connectorConsistencyFromLatestEvents(stationID string, latestEvents map[string]domain.Event) (domain.ConnectorConsistency, error) {
+	report := domain.ConnectorConsistency{
+		StationID: stationID,
+	}
+
+	meterValueConnectorIDs := make(map[int32]bool)
+	for _, messageType := range []string{
+		domain.EventTypeConnectorListResponse,
+		domain.EventTypeMeterValuesNotification,
+		domain.EventTypeMeterValuesResponse,
+	} {
+		event, ok := latestEvents[messageType]
+		if !ok {
+			continue
+		}
+
+		payload, err := domain.EventPayload(event)
+		if err != nil {
+			return domain.ConnectorConsistency{}, fmt.Errorf("failed to get event payload: %w", err)
+		}
+
+		var numConnectors int
+		switch payload := payload.(type) {
+		case domain.ConnectorListResponsePayload:
+			numConnectors = payload.NumConnectors
+			report.DeclaredNumConnectors = &numConnectors
+		case domain.MeterValuesNotificationPayload:
+			numConnectors = len(payload.MeterValues)
+			for _, meterValue := range payload.MeterValues {
+				meterValueConnectorIDs[meterValue.ConnectorID] = true
+			}
+		case domain.MeterValuesResponsePayload:
+			numConnectors = len(payload.MeterValues)
+			for _, meterValue := range payload.MeterValues {
+				meterValueConnectorIDs[meterValue.ConnectorID] = true
+			}
+		}
+
+		report.Claims = append(report.Claims, domain.ConnectorCountClaim{
+			MessageType:   messageType,
+			EventID:       event.ID,
+			NumConnectors: numConnectors,
+			OccurredAt:    event.OccurredAt,
+		})
+	}
+
+	for connectorID := range meterValueConnectorIDs {
+		report.MeterValueConnectorIDs = append(report.MeterValueConnectorIDs, connectorID)
+		if report.DeclaredNumConnectors != nil && int(connectorID) > *report.DeclaredNumConnectors {
+			report.UndeclaredConnectorIDs = append(report.UndeclaredConnectorIDs, connectorID)
+		}
+	}
+	sortConnectorIDs(report.MeterValueConnectorIDs)
+	sortConnectorIDs(report.UndeclaredConnectorIDs)
+
+	// Order the claims from the latest to the oldest, the first being the current view.
+	sort.SliceStable(report.Claims, func(i, j int) bool {
+		return latestEvents[report.Claims[i].MessageType].After(latestEvents[report.Claims[j].MessageType])
+	})
+
+	report.Verdict = connectorCountVerdict(report.Claims, report.MeterValueConnectorIDs)
+	if len(report.Claims) > 0 {
+		report.NumConnectors = report.Claims[0].NumConnectors
+	}
+
+	return report, nil
+}
+
+// connectorCountVerdict judges the claims, which must be ordered from the latest to the oldest.
+func connectorCountVerdict(claims []domain.ConnectorCountClaim, meterValueConnectorIDs []int32) domain.ConnectorCountVerdict {
+	if len(claims) == 0 {
+		return domain.ConnectorCountConsistent
+	}
+
+	latest := claims[0]
+	var disagreement bool
+	for _, claim := range claims[1:] {
+		if claim.NumConnectors == latest.NumConnectors {
+			continue
+		}
+		// A disagreeing claim which is as recent as the latest can't be explained by it being out of date.
+		if claim.OccurredAt.Equal(latest.OccurredAt) {
+			return domain.ConnectorCountConflicting
+		}
+		disagreement = true
+	}
+
+	// The current view can't be right if meter values have been seen for connectors beyond it.
+	for _, connectorID := range meterValueConnectorIDs {
+		if int(connectorID) > latest.NumConnectors {
+			return domain.ConnectorCountConflicting
+		}
+	}
+
+	if disagreement {
+		return domain.ConnectorCountStale
+	}
+
+	return domain.ConnectorCountConsistent
+}
+
+func sortConnectorIDs(connectorIDs []int32) {
+	sort.Slice(connectorIDs, func(i, j int) bool {
+		return connectorIDs[i] < connectorIDs[j]
+	})
+}
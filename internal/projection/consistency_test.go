@@ -0,0 +1,260 @@
+package projection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/zucchinho/ocpp/internal/domain"
+	"github.com/zucchinho/ocpp/internal/domain/mock"
+)
+
+func TestConnectorConsistencyFromLatestEvents(t *testing.T) {
+	numConnectors := func(n int) *int { return &n }
+
+	tests := []struct {
+		name         string
+		latestEvents map[string]domain.Event
+		want         domain.ConnectorConsistency
+	}{
+		{
+			name: "all sources agree",
+			latestEvents: map[string]domain.Event{
+				domain.EventTypeConnectorListResponse: {
+					ID:          "event-1",
+					MessageType: domain.EventTypeConnectorListResponse,
+					OccurredAt:  twoMinutesAgo,
+					Payload: domain.ConnectorListResponsePayload{
+						NumConnectors: 2,
+					},
+				},
+				domain.EventTypeMeterValuesNotification: {
+					ID:          "event-2",
+					MessageType: domain.EventTypeMeterValuesNotification,
+					OccurredAt:  now,
+					Payload: domain.MeterValuesNotificationPayload{
+						StationID: "station-1",
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
+							domain.NewMeterValue(2, "200"),
+						},
+					},
+				},
+			},
+			want: domain.ConnectorConsistency{
+				StationID:     "station-1",
+				NumConnectors: 2,
+				Claims: []domain.ConnectorCountClaim{
+					{MessageType: domain.EventTypeMeterValuesNotification, EventID: "event-2", NumConnectors: 2, OccurredAt: now},
+					{MessageType: domain.EventTypeConnectorListResponse, EventID: "event-1", NumConnectors: 2, OccurredAt: twoMinutesAgo},
+				},
+				DeclaredNumConnectors:  numConnectors(2),
+				MeterValueConnectorIDs: []int32{1, 2},
+				Verdict:                domain.ConnectorCountConsistent,
+			},
+		},
+		{
+			name: "older connector list response disagrees",
+			latestEvents: map[string]domain.Event{
+				domain.EventTypeConnectorListResponse: {
+					ID:          "event-1",
+					MessageType: domain.EventTypeConnectorListResponse,
+					OccurredAt:  twoMinutesAgo,
+					Payload: domain.ConnectorListResponsePayload{
+						NumConnectors: 5,
+					},
+				},
+				domain.EventTypeMeterValuesNotification: {
+					ID:          "event-2",
+					MessageType: domain.EventTypeMeterValuesNotification,
+					OccurredAt:  now,
+					Payload: domain.MeterValuesNotificationPayload{
+						StationID: "station-1",
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
+							domain.NewMeterValue(2, "200"),
+							domain.NewMeterValue(3, "300"),
+						},
+					},
+				},
+			},
+			want: domain.ConnectorConsistency{
+				StationID:     "station-1",
+				NumConnectors: 3,
+				Claims: []domain.ConnectorCountClaim{
+					{MessageType: domain.EventTypeMeterValuesNotification, EventID: "event-2", NumConnectors: 3, OccurredAt: now},
+					{MessageType: domain.EventTypeConnectorListResponse, EventID: "event-1", NumConnectors: 5, OccurredAt: twoMinutesAgo},
+				},
+				DeclaredNumConnectors:  numConnectors(5),
+				MeterValueConnectorIDs: []int32{1, 2, 3},
+				Verdict:                domain.ConnectorCountStale,
+			},
+		},
+		{
+			name: "meter values seen for undeclared connectors",
+			latestEvents: map[string]domain.Event{
+				domain.EventTypeConnectorListResponse: {
+					ID:          "event-1",
+					MessageType: domain.EventTypeConnectorListResponse,
+					OccurredAt:  now,
+					Payload: domain.ConnectorListResponsePayload{
+						NumConnectors: 1,
+					},
+				},
+				domain.EventTypeMeterValuesNotification: {
+					ID:          "event-2",
+					MessageType: domain.EventTypeMeterValuesNotification,
+					OccurredAt:  twoMinutesAgo,
+					Payload: domain.MeterValuesNotificationPayload{
+						StationID: "station-1",
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
+							domain.NewMeterValue(2, "200"),
+						},
+					},
+				},
+			},
+			want: domain.ConnectorConsistency{
+				StationID:     "station-1",
+				NumConnectors: 1,
+				Claims: []domain.ConnectorCountClaim{
+					{MessageType: domain.EventTypeConnectorListResponse, EventID: "event-1", NumConnectors: 1, OccurredAt: now},
+					{MessageType: domain.EventTypeMeterValuesNotification, EventID: "event-2", NumConnectors: 2, OccurredAt: twoMinutesAgo},
+				},
+				DeclaredNumConnectors:  numConnectors(1),
+				MeterValueConnectorIDs: []int32{1, 2},
+				UndeclaredConnectorIDs: []int32{2},
+				Verdict:                domain.ConnectorCountConflicting,
+			},
+		},
+		{
+			name: "latest sources disagree",
+			latestEvents: map[string]domain.Event{
+				domain.EventTypeConnectorListResponse: {
+					ID:          "event-1",
+					MessageType: domain.EventTypeConnectorListResponse,
+					OccurredAt:  now,
+					Payload: domain.ConnectorListResponsePayload{
+						NumConnectors: 3,
+					},
+				},
+				domain.EventTypeMeterValuesResponse: {
+					ID:          "event-2",
+					MessageType: domain.EventTypeMeterValuesResponse,
+					OccurredAt:  now,
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
+						},
+					},
+				},
+			},
+			want: domain.ConnectorConsistency{
+				StationID:     "station-1",
+				NumConnectors: 3,
+				Claims: []domain.ConnectorCountClaim{
+					{MessageType: domain.EventTypeConnectorListResponse, EventID: "event-1", NumConnectors: 3, OccurredAt: now},
+					{MessageType: domain.EventTypeMeterValuesResponse, EventID: "event-2", NumConnectors: 1, OccurredAt: now},
+				},
+				DeclaredNumConnectors:  numConnectors(3),
+				MeterValueConnectorIDs: []int32{1},
+				Verdict:                domain.ConnectorCountConflicting,
+			},
+		},
+		{
+			name: "meter values response to a request for one connector",
+			latestEvents: map[string]domain.Event{
+				domain.EventTypeConnectorListResponse: {
+					ID:          "event-1",
+					MessageType: domain.EventTypeConnectorListResponse,
+					OccurredAt:  twoMinutesAgo,
+					Payload: domain.ConnectorListResponsePayload{
+						NumConnectors: 2,
+					},
+				},
+				domain.EventTypeMeterValuesRequest: {
+					ID:          "event-2",
+					MessageType: domain.EventTypeMeterValuesRequest,
+					OccurredAt:  oneMinuteAgo,
+					Payload: domain.MeterValuesRequestPayload{
+						StationID:   "station-1",
+						ConnectorID: 1,
+					},
+				},
+				domain.EventTypeMeterValuesResponse: {
+					ID:          "event-3",
+					MessageType: domain.EventTypeMeterValuesResponse,
+					OccurredAt:  now,
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
+							domain.NewMeterValue(2, "200"),
+						},
+					},
+				},
+			},
+			want: domain.ConnectorConsistency{
+				StationID:     "station-1",
+				NumConnectors: 2,
+				Claims: []domain.ConnectorCountClaim{
+					{MessageType: domain.EventTypeMeterValuesResponse, EventID: "event-3", NumConnectors: 2, OccurredAt: now},
+					{MessageType: domain.EventTypeConnectorListResponse, EventID: "event-1", NumConnectors: 2, OccurredAt: twoMinutesAgo},
+				},
+				DeclaredNumConnectors:  numConnectors(2),
+				MeterValueConnectorIDs: []int32{1, 2},
+				Verdict:                domain.ConnectorCountConsistent,
+			},
+		},
+		{
+			name: "no claims",
+			latestEvents: map[string]domain.Event{
+				domain.EventTypeConnectorListRequest: {
+					ID:          "event-1",
+					MessageType: domain.EventTypeConnectorListRequest,
+					OccurredAt:  now,
+					Payload: domain.ConnectorListRequestPayload{
+						StationID: "station-1",
+					},
+				},
+			},
+			want: domain.ConnectorConsistency{
+				StationID: "station-1",
+				Verdict:   domain.ConnectorCountConsistent,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got, err := connectorConsistencyFromLatestEvents("station-1", tt.latestEvents)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConnectorConsistency_NotFound(t *testing.T) {
+	// arrange
+	ctrl := gomock.NewController(t)
+	mockEventSource := mock.NewMockEventSource(ctrl)
+	mockEventSource.EXPECT().GetAll(gomock.Any()).Return(nil)
+
+	projections := map[string]domain.ConsistencyProjection{
+		"basic":       NewBasicProjection(mockEventSource),
+		"incremental": NewIncrementalProjection(),
+	}
+
+	for name, p := range projections {
+		t.Run(name, func(t *testing.T) {
+			// act
+			_, err := p.ConnectorConsistency(context.Background(), "station-1")
+
+			// assert
+			assert.ErrorIs(t, err, domain.ErrChargingStationNotFound)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package projection
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// exchangeKey identifies the exchange of a request and its responses.
+type exchangeKey struct {
+	correlationID string
+	requestType   string
+}
+
+// exchange is a request and its responses and the errors it was answered with instead, each ordered by when they
+// occurred. Either the request or its answers may be missing.
+type exchange struct {
+	request   *domain.Event
+	responses []domain.Event
+	errors    []domain.Event
+}
+
+// stationID returns the ID of the station the exchange's request was sent to.
+func (e exchange) stationID() string {
+	if e.request.StationID != "" {
+		return e.request.StationID
+	}
+
+	return e.request.PayloadStationID()
+}
+
+// firstAnswer returns the exchange's first response or error, whichever occurred first, or false if it hasn't been
+// answered.
+func (e exchange) firstAnswer() (domain.Event, bool) {
+	switch {
+	case len(e.responses) == 0 && len(e.errors) == 0:
+		return domain.Event{}, false
+	case len(e.errors) == 0:
+		return e.responses[0], true
+	case len(e.responses) == 0 || e.responses[0].After(e.errors[0]):
+		return e.errors[0], true
+	}
+
+	return e.responses[0], true
+}
+
+// failed reports whether the exchange's request was answered with an error before any response.
+func (e exchange) failed() bool {
+	answer, ok := e.firstAnswer()
+
+	return ok && answer.MessageType == domain.EventTypeCallError
+}
+
+// exchanges pairs the requests in the events with their responses and errors by correlation ID, returning the
+// exchanges in the order their requests occurred in, and the responses and errors without a request in the order they
+// occurred in. An error answers every request with its correlation ID, whatever its message type.
+func exchanges(events []domain.Event) ([]exchange, []domain.Event) {
+	events = append([]domain.Event(nil), events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[j].After(events[i])
+	})
+
+	exchangesByKey := make(map[exchangeKey]*exchange)
+	var keys []exchangeKey
+	var errorEvents []domain.Event
+	for _, event := range events {
+		event := event
+		messageType, _ := domain.LookupMessageType(event.MessageType)
+		if messageType.Kind == domain.MessageKindError {
+			errorEvents = append(errorEvents, event)
+			continue
+		}
+		if messageType.Kind == domain.MessageKindRequest {
+			key := exchangeKey{correlationID: event.CorrelationID, requestType: event.MessageType}
+			e, ok := exchangesByKey[key]
+			if !ok {
+				e = &exchange{}
+				exchangesByKey[key] = e
+			}
+			// A request repeated with the same correlation ID is the same request.
+			if e.request == nil {
+				e.request = &event
+				keys = append(keys, key)
+			}
+			continue
+		}
+
+		if messageType.Kind != domain.MessageKindResponse {
+			continue
+		}
+		key := exchangeKey{correlationID: event.CorrelationID, requestType: messageType.RequestType}
+		e, ok := exchangesByKey[key]
+		if !ok {
+			e = &exchange{}
+			exchangesByKey[key] = e
+		}
+		if !containsSameMessage(e.responses, event) {
+			e.responses = append(e.responses, event)
+		}
+	}
+
+	keysByCorrelationID := make(map[string][]exchangeKey)
+	for _, key := range keys {
+		keysByCorrelationID[key.correlationID] = append(keysByCorrelationID[key.correlationID], key)
+	}
+	for _, event := range errorEvents {
+		for _, key := range keysByCorrelationID[event.CorrelationID] {
+			e := exchangesByKey[key]
+			if !containsSameMessage(e.errors, event) {
+				e.errors = append(e.errors, event)
+			}
+		}
+	}
+
+	paired := make([]exchange, 0, len(keys))
+	for _, key := range keys {
+		paired = append(paired, *exchangesByKey[key])
+	}
+
+	var orphans []domain.Event
+	for _, event := range events {
+		messageType, _ := domain.LookupMessageType(event.MessageType)
+		switch messageType.Kind {
+		case domain.MessageKindResponse:
+			if exchangesByKey[exchangeKey{correlationID: event.CorrelationID, requestType: messageType.RequestType}].request == nil {
+				orphans = append(orphans, event)
+			}
+		case domain.MessageKindError:
+			if len(keysByCorrelationID[event.CorrelationID]) == 0 {
+				orphans = append(orphans, event)
+			}
+		}
+	}
+
+	return paired, orphans
+}
+
+// containsSameMessage reports whether any of the events carries the same message as the event, i.e. is a
+// retransmission of it, whenever it was captured. Events with the same message ID have already been de-duplicated by
+// the event source, so these are copies of the message received with another message ID.
+func containsSameMessage(events []domain.Event, event domain.Event) bool {
+	for _, other := range events {
+		if other.SameMessage(event) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// correlationReport returns the report of the requests and responses in the events which haven't been paired, with
+// the ages of pending requests measured at the instant, or at the latest event if it is zero. Requests of a message
+// type registered as expecting no response aren't reported as pending or timed out.
+func correlationReport(events []domain.Event, at time.Time, deadline time.Duration) (domain.CorrelationReport, error) {
+	if at.IsZero() {
+		for _, event := range events {
+			if event.OccurredAt.After(at) {
+				at = event.OccurredAt
+			}
+		}
+	}
+
+	paired, orphanResponses := exchanges(events)
+	return correlationReportFromExchanges(paired, orphanResponses, at, deadline)
+}
+
+// correlationReportFromExchanges returns the report of the exchanges and the responses without a request, as returned
+// by exchanges, with the ages of pending requests measured at the instant.
+func correlationReportFromExchanges(paired []exchange, orphanResponses []domain.Event, at time.Time, deadline time.Duration) (domain.CorrelationReport, error) {
+	if deadline < 0 {
+		return domain.CorrelationReport{}, fmt.Errorf("%s is negative: %w", deadline, domain.ErrInvalidDeadline)
+	}
+
+	report := domain.CorrelationReport{
+		At:              at,
+		Deadline:        deadline,
+		OrphanResponses: orphanResponses,
+	}
+
+	for _, e := range paired {
+		request, stationID := *e.request, e.stationID()
+
+		answer, ok := e.firstAnswer()
+		if !ok {
+			if messageType, _ := domain.LookupMessageType(request.MessageType); messageType.ExpectsNoResponse {
+				continue
+			}
+			age := at.Sub(request.OccurredAt)
+			if deadline > 0 && age > deadline {
+				report.TimedOut = append(report.TimedOut, domain.TimedOutRequest{
+					StationID: stationID,
+					Request:   request,
+					Waited:    age,
+				})
+				continue
+			}
+			report.Pending = append(report.Pending, domain.PendingRequest{
+				StationID: stationID,
+				Request:   request,
+				Age:       age,
+			})
+			continue
+		}
+
+		if waited := answer.OccurredAt.Sub(request.OccurredAt); deadline > 0 && waited > deadline {
+			report.TimedOut = append(report.TimedOut, domain.TimedOutRequest{
+				StationID: stationID,
+				Request:   request,
+				Response:  &answer,
+				Waited:    waited,
+			})
+		}
+		if len(e.responses) > 1 {
+			report.MultipleResponses = append(report.MultipleResponses, domain.MultipleResponses{
+				StationID:     stationID,
+				CorrelationID: request.CorrelationID,
+				Request:       request,
+				Responses:     e.responses,
+			})
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,304 @@
+package projection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// unansweredRequestPayload is the payload of a request type registered by the tests as expecting no response.
+type unansweredRequestPayload struct {
+	StationID string `json:"stationId"`
+}
+
+func (unansweredRequestPayload) MessageType() string { return "UnansweredRequest" }
+
+func init() {
+	domain.RegisterMessageType(domain.MessageTypeOptions[unansweredRequestPayload]{
+		Kind:              domain.MessageKindRequest,
+		ExpectsNoResponse: true,
+		StationID:         func(payload unansweredRequestPayload) string { return payload.StationID },
+	})
+}
+
+func TestCorrelationReport(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	seconds := func(n int) time.Time {
+		return start.Add(time.Duration(n) * time.Second)
+	}
+	request := func(id, correlationID string, occurredAt time.Time) domain.Event {
+		return domain.Event{
+			ID:            id,
+			CorrelationID: correlationID,
+			MessageType:   domain.EventTypeMeterValuesRequest,
+			OccurredAt:    occurredAt,
+			Payload:       domain.MeterValuesRequestPayload{StationID: "station-1", ConnectorID: 1},
+		}
+	}
+	response := func(id, correlationID string, occurredAt time.Time, reading string) domain.Event {
+		return domain.Event{
+			ID:            id,
+			CorrelationID: correlationID,
+			MessageType:   domain.EventTypeMeterValuesResponse,
+			OccurredAt:    occurredAt,
+			Payload: domain.MeterValuesResponsePayload{MeterValues: []domain.MeterValue{
+				domain.NewMeterValue(1, reading),
+			}},
+		}
+	}
+	callError := func(id, correlationID string, occurredAt time.Time) domain.Event {
+		return domain.Event{
+			ID:            id,
+			CorrelationID: correlationID,
+			MessageType:   domain.EventTypeCallError,
+			OccurredAt:    occurredAt,
+			Payload:       domain.CallErrorPayload{ErrorCode: domain.CallErrorInternalError},
+		}
+	}
+
+	tests := []struct {
+		name                  string
+		events                []domain.Event
+		at                    time.Time
+		deadline              time.Duration
+		wantAt                time.Time
+		wantPending           []string
+		wantPendingAges       []time.Duration
+		wantTimedOut          []string
+		wantOrphanResponses   []string
+		wantMultipleResponses []string
+	}{
+		{
+			name: "answered",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+				response("response-1", "correlation-1", seconds(1), "100"),
+			},
+			deadline: 10 * time.Second,
+			wantAt:   seconds(1),
+		},
+		{
+			name: "pending, aged to the latest event",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+				request("request-2", "correlation-2", seconds(5)),
+				response("response-2", "correlation-2", seconds(6), "100"),
+			},
+			wantAt:          seconds(6),
+			wantPending:     []string{"request-1"},
+			wantPendingAges: []time.Duration{6 * time.Second},
+		},
+		{
+			name: "pending, aged to the instant",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+				request("request-2", "correlation-2", seconds(5)),
+				response("response-2", "correlation-2", seconds(6), "100"),
+			},
+			at:              seconds(30),
+			wantAt:          seconds(30),
+			wantPending:     []string{"request-1"},
+			wantPendingAges: []time.Duration{30 * time.Second},
+		},
+		{
+			name: "pending within the deadline",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+				request("request-2", "correlation-2", seconds(5)),
+				response("response-2", "correlation-2", seconds(6), "100"),
+			},
+			at:              seconds(8),
+			deadline:        10 * time.Second,
+			wantAt:          seconds(8),
+			wantPending:     []string{"request-1"},
+			wantPendingAges: []time.Duration{8 * time.Second},
+		},
+		{
+			name: "timed out while unanswered, so not pending",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+				request("request-2", "correlation-2", seconds(5)),
+				response("response-2", "correlation-2", seconds(6), "100"),
+			},
+			at:           seconds(30),
+			deadline:     10 * time.Second,
+			wantAt:       seconds(30),
+			wantTimedOut: []string{"request-1"},
+		},
+		{
+			name: "single unanswered request",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+			},
+			at:              seconds(30),
+			wantAt:          seconds(30),
+			wantPending:     []string{"request-1"},
+			wantPendingAges: []time.Duration{30 * time.Second},
+		},
+		{
+			name: "single unanswered request, timed out",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+			},
+			at:           seconds(30),
+			deadline:     10 * time.Second,
+			wantAt:       seconds(30),
+			wantTimedOut: []string{"request-1"},
+		},
+		{
+			name: "requests of a type expecting no response aren't tracked",
+			events: []domain.Event{
+				{
+					ID:            "unanswered-1",
+					CorrelationID: "correlation-1",
+					MessageType:   unansweredRequestPayload{}.MessageType(),
+					OccurredAt:    seconds(0),
+					Payload:       unansweredRequestPayload{StationID: "station-1"},
+				},
+				request("request-2", "correlation-2", seconds(5)),
+				response("response-2", "correlation-2", seconds(6), "100"),
+			},
+			at:       seconds(30),
+			deadline: 10 * time.Second,
+			wantAt:   seconds(30),
+		},
+		{
+			name: "answered after the deadline",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+				response("response-1", "correlation-1", seconds(20), "100"),
+			},
+			deadline:     10 * time.Second,
+			wantAt:       seconds(20),
+			wantTimedOut: []string{"request-1"},
+		},
+		{
+			name: "orphan responses",
+			events: []domain.Event{
+				response("response-2", "correlation-2", seconds(2), "100"),
+				request("request-1", "correlation-1", seconds(0)),
+				response("response-1", "correlation-1", seconds(1), "100"),
+				{
+					ID:            "response-3",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeConnectorListResponse,
+					OccurredAt:    seconds(3),
+					Payload:       domain.ConnectorListResponsePayload{NumConnectors: 2},
+				},
+			},
+			wantAt:              seconds(3),
+			wantOrphanResponses: []string{"response-2", "response-3"},
+		},
+		{
+			name: "answered with an error",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+				callError("error-1", "correlation-1", seconds(1)),
+			},
+			deadline: 10 * time.Second,
+			wantAt:   seconds(1),
+		},
+		{
+			name: "answered with an error after the deadline",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+				callError("error-1", "correlation-1", seconds(20)),
+				response("response-1", "correlation-1", seconds(30), "100"),
+			},
+			deadline:     10 * time.Second,
+			wantAt:       seconds(30),
+			wantTimedOut: []string{"request-1"},
+		},
+		{
+			name: "orphan errors",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+				callError("error-1", "correlation-1", seconds(1)),
+				callError("error-2", "correlation-2", seconds(2)),
+			},
+			wantAt:              seconds(2),
+			wantOrphanResponses: []string{"error-2"},
+		},
+		{
+			name: "multiple responses",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+				response("response-1", "correlation-1", seconds(1), "100"),
+				response("response-2", "correlation-1", seconds(2), "200"),
+			},
+			wantAt:                seconds(2),
+			wantMultipleResponses: []string{"request-1"},
+		},
+		{
+			name: "retransmitted response",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+				response("response-1", "correlation-1", seconds(1), "100"),
+				response("response-2", "correlation-1", seconds(1), "100"),
+			},
+			wantAt: seconds(1),
+		},
+		{
+			name: "retransmitted response captured later",
+			events: []domain.Event{
+				request("request-1", "correlation-1", seconds(0)),
+				response("response-1", "correlation-1", seconds(1), "100"),
+				response("response-2", "correlation-1", seconds(5), "100"),
+			},
+			wantAt: seconds(5),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			report, err := correlationReport(tt.events, tt.at, tt.deadline)
+
+			// assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAt, report.At)
+			assert.Equal(t, tt.deadline, report.Deadline)
+
+			var gotPending []string
+			var gotPendingAges []time.Duration
+			for _, pending := range report.Pending {
+				gotPending = append(gotPending, pending.Request.ID)
+				gotPendingAges = append(gotPendingAges, pending.Age)
+				assert.Equal(t, "station-1", pending.StationID)
+			}
+			assert.Equal(t, tt.wantPending, gotPending)
+			assert.Equal(t, tt.wantPendingAges, gotPendingAges)
+
+			var gotTimedOut []string
+			for _, timedOut := range report.TimedOut {
+				gotTimedOut = append(gotTimedOut, timedOut.Request.ID)
+				assert.Greater(t, timedOut.Waited, tt.deadline)
+			}
+			assert.Equal(t, tt.wantTimedOut, gotTimedOut)
+
+			var gotOrphanResponses []string
+			for _, response := range report.OrphanResponses {
+				gotOrphanResponses = append(gotOrphanResponses, response.ID)
+			}
+			assert.Equal(t, tt.wantOrphanResponses, gotOrphanResponses)
+
+			var gotMultipleResponses []string
+			for _, multiple := range report.MultipleResponses {
+				gotMultipleResponses = append(gotMultipleResponses, multiple.Request.ID)
+				assert.Len(t, multiple.Responses, 2)
+			}
+			assert.Equal(t, tt.wantMultipleResponses, gotMultipleResponses)
+		})
+	}
+}
+
+func TestCorrelationReport_InvalidDeadline(t *testing.T) {
+	// act
+	_, err := correlationReport(nil, time.Time{}, -time.Second)
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrInvalidDeadline)
+}
@@ -0,0 +1,165 @@
+package projection
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// interpolationScale is the number of digits after the decimal point of interpolated readings in Wh.
+const interpolationScale = 3
+
+// meterReading is a connector's meter reading in Wh at an instant.
+type meterReading struct {
+	occurredAt time.Time
+	wh         domain.Decimal
+}
+
+// energyDelivered returns the energy delivered by the connector in the period, from the readings of the station's
+// connectors in the order their events were created in.
+func energyDelivered(readings []domain.ConnectorReading, stationID string, connectorID int32, from, to time.Time) (domain.EnergyDelivered, error) {
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return domain.EnergyDelivered{}, fmt.Errorf("%s is before %s: %w", to, from, domain.ErrInvalidPeriod)
+	}
+
+	energy := domain.EnergyDelivered{
+		StationID:   stationID,
+		ConnectorID: connectorID,
+		From:        from,
+		To:          to,
+		Confidence:  domain.EnergyUnknown,
+	}
+
+	var meterReadings []meterReading
+	for _, reading := range selectConnectorReadings(readings, connectorID, time.Time{}, time.Time{}) {
+		// Readings which couldn't be parsed can't be measured from.
+		if reading.ReadingValue == nil {
+			continue
+		}
+		meterReadings = append(meterReadings, meterReading{
+			occurredAt: reading.OccurredAt,
+			wh:         reading.ReadingValue.Wh(),
+		})
+	}
+
+	if len(meterReadings) == 0 {
+		return energy, nil
+	}
+	first, last := meterReadings[0], meterReadings[len(meterReadings)-1]
+	// If the period is entirely outside the readings, nothing is known about it.
+	if (!to.IsZero() && !to.After(first.occurredAt)) || (!from.IsZero() && from.After(last.occurredAt)) {
+		return energy, nil
+	}
+
+	// Measure from the meter readings at the boundaries of the period, through each of the readings inside it.
+	start, startConfidence := first, domain.EnergyExact
+	if !from.IsZero() {
+		start, startConfidence = meterReadingAt(meterReadings, from)
+	}
+	end, endConfidence := last, domain.EnergyExact
+	if !to.IsZero() {
+		end, endConfidence = meterReadingAt(meterReadings, to)
+	}
+	energy.Confidence = startConfidence.LeastConfident(endConfidence)
+
+	points := []meterReading{start}
+	for _, reading := range meterReadings {
+		if reading.occurredAt.After(start.occurredAt) && reading.occurredAt.Before(end.occurredAt) {
+			points = append(points, reading)
+		}
+	}
+	points = append(points, end)
+
+	for i := 1; i < len(points); i++ {
+		previous, current := points[i-1].wh, points[i].wh
+		if current.Cmp(previous) >= 0 {
+			energy.Wh = energy.Wh.Add(current.Sub(previous))
+			continue
+		}
+
+		// The meter went backwards, so it has either been reset or wrapped around on reaching its capacity. Without
+		// knowing the capacity, the energy up to it can't be measured, so the fall is measured as a reset.
+		energy.Wh = energy.Wh.Add(current)
+		energy.Resets++
+		if nearRegisterCapacity(previous) {
+			energy.PossibleRollovers++
+			energy.Confidence = energy.Confidence.LeastConfident(domain.EnergyUncertain)
+		}
+	}
+
+	return energy, nil
+}
+
+// meterReadingAt returns the meter reading at the instant, from the meter readings ordered by when they occurred,
+// which must not all be before the instant, or all after it.
+func meterReadingAt(meterReadings []meterReading, at time.Time) (meterReading, domain.EnergyConfidence) {
+	i := sort.Search(len(meterReadings), func(i int) bool {
+		return !meterReadings[i].occurredAt.Before(at)
+	})
+
+	switch {
+	case i < len(meterReadings) && meterReadings[i].occurredAt.Equal(at):
+		return meterReadings[i], domain.EnergyExact
+	case i == 0:
+		// The instant is before the first reading, so the period is measured from the first reading.
+		return meterReadings[0], domain.EnergyBracketed
+	case i == len(meterReadings):
+		// The instant is after the last reading, so the period is measured to the last reading.
+		return meterReadings[i-1], domain.EnergyBracketed
+	}
+
+	before, after := meterReadings[i-1], meterReadings[i]
+	if after.wh.Cmp(before.wh) < 0 {
+		// The meter went backwards between the readings, so there is no line to interpolate along.
+		return meterReading{occurredAt: at, wh: before.wh}, domain.EnergyBracketed
+	}
+
+	elapsed := at.Sub(before.occurredAt)
+	interval := after.occurredAt.Sub(before.occurredAt)
+	wh := before.wh.Add(after.wh.Sub(before.wh).MulRatio(int64(elapsed), int64(interval), interpolationScale))
+
+	return meterReading{occurredAt: at, wh: wh}, domain.EnergyInterpolated
+}
+
+// nearRegisterCapacity reports whether the reading is within a tenth of the capacity of a register with as many
+// digits, so that a meter which went backwards from it may have wrapped around to zero rather than been reset.
+func nearRegisterCapacity(wh domain.Decimal) bool {
+	integer, _, _ := strings.Cut(wh.String(), ".")
+	capacity := domain.MustParseDecimal("1" + strings.Repeat("0", len(integer)))
+
+	return wh.Cmp(capacity.MulRatio(9, 10, 0)) >= 0
+}
+
+// stationEnergyDelivered returns the energy delivered by each of the station's connectors in the period, from the
+// readings of the station's connectors in the order their events were created in.
+func stationEnergyDelivered(readings []domain.ConnectorReading, stationID string, from, to time.Time) (domain.StationEnergyDelivered, error) {
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return domain.StationEnergyDelivered{}, fmt.Errorf("%s is before %s: %w", to, from, domain.ErrInvalidPeriod)
+	}
+
+	connectorIDs := readingConnectorIDs(readings)
+
+	stationEnergy := domain.StationEnergyDelivered{
+		StationID:  stationID,
+		From:       from,
+		To:         to,
+		Confidence: domain.EnergyExact,
+	}
+	if len(connectorIDs) == 0 {
+		stationEnergy.Confidence = domain.EnergyUnknown
+	}
+	for _, connectorID := range connectorIDs {
+		energy, err := energyDelivered(readings, stationID, connectorID, from, to)
+		if err != nil {
+			return domain.StationEnergyDelivered{}, fmt.Errorf("energy delivered by connector %d: %w", connectorID, err)
+		}
+		stationEnergy.Wh = stationEnergy.Wh.Add(energy.Wh)
+		stationEnergy.Confidence = stationEnergy.Confidence.LeastConfident(energy.Confidence)
+		stationEnergy.Connectors = append(stationEnergy.Connectors, energy)
+	}
+
+	return stationEnergy, nil
+}
@@ -0,0 +1,189 @@
+package projection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+func TestEnergyDelivered(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	minutes := func(n int) time.Time {
+		return start.Add(time.Duration(n) * time.Minute)
+	}
+	newReadings := func(readings ...string) []domain.ConnectorReading {
+		var connectorReadings []domain.ConnectorReading
+		for i, reading := range readings {
+			connectorReadings = append(connectorReadings, domain.ConnectorReading{
+				StationID:    "station-1",
+				ConnectorID:  1,
+				Reading:      reading,
+				ReadingValue: readingValue(reading),
+				OccurredAt:   minutes(i * 10),
+			})
+		}
+		return connectorReadings
+	}
+
+	tests := []struct {
+		name           string
+		readings       []domain.ConnectorReading
+		from           time.Time
+		to             time.Time
+		wantWh         string
+		wantConfidence domain.EnergyConfidence
+		wantResets     int
+		// wantPossibleRollovers is the number of the resets which may have been rollovers.
+		wantPossibleRollovers int
+	}{
+		{
+			name:           "unbounded",
+			readings:       newReadings("100", "150", "300"),
+			wantWh:         "200",
+			wantConfidence: domain.EnergyExact,
+		},
+		{
+			name:           "readings at the boundaries",
+			readings:       newReadings("100", "150", "300"),
+			from:           minutes(10),
+			to:             minutes(20),
+			wantWh:         "150",
+			wantConfidence: domain.EnergyExact,
+		},
+		{
+			name:           "interpolated",
+			readings:       newReadings("100", "150", "300"),
+			from:           minutes(5),
+			to:             minutes(15),
+			wantWh:         "100.000",
+			wantConfidence: domain.EnergyInterpolated,
+		},
+		{
+			name:           "units",
+			readings:       newReadings("0.1 kWh", "150Wh", "0.3kWh"),
+			from:           minutes(10),
+			wantWh:         "150",
+			wantConfidence: domain.EnergyExact,
+		},
+		{
+			name:           "period starts before the first reading",
+			readings:       newReadings("100", "150", "300"),
+			from:           minutes(-10),
+			to:             minutes(10),
+			wantWh:         "50",
+			wantConfidence: domain.EnergyBracketed,
+		},
+		{
+			name:           "period ends after the last reading",
+			readings:       newReadings("100", "150", "300"),
+			from:           minutes(10),
+			to:             minutes(60),
+			wantWh:         "150",
+			wantConfidence: domain.EnergyBracketed,
+		},
+		{
+			name:           "reset",
+			readings:       newReadings("100", "150", "20", "50"),
+			wantWh:         "100",
+			wantConfidence: domain.EnergyExact,
+			wantResets:     1,
+		},
+		{
+			name:           "reset at an interpolated boundary",
+			readings:       newReadings("100", "150", "20", "50"),
+			from:           minutes(15),
+			wantWh:         "50",
+			wantConfidence: domain.EnergyBracketed,
+			wantResets:     1,
+		},
+		{
+			name:                  "possible rollover measured as a reset",
+			readings:              newReadings("99950", "99990", "30"),
+			wantWh:                "70",
+			wantConfidence:        domain.EnergyUncertain,
+			wantResets:            1,
+			wantPossibleRollovers: 1,
+		},
+		{
+			name:           "fall from a round reading far from a register's capacity",
+			readings:       newReadings("1000", "1500", "30"),
+			wantWh:         "530",
+			wantConfidence: domain.EnergyExact,
+			wantResets:     1,
+		},
+		{
+			name:           "period before the readings",
+			readings:       newReadings("100", "150"),
+			from:           minutes(-20),
+			to:             minutes(-10),
+			wantWh:         "0",
+			wantConfidence: domain.EnergyUnknown,
+		},
+		{
+			name:           "no readings",
+			wantWh:         "0",
+			wantConfidence: domain.EnergyUnknown,
+		},
+		{
+			name: "unparsable readings are skipped",
+			readings: append(newReadings("100", "150"), domain.ConnectorReading{
+				ConnectorID: 1,
+				Reading:     "twelve",
+				OccurredAt:  minutes(30),
+			}),
+			wantWh:         "50",
+			wantConfidence: domain.EnergyExact,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got, err := energyDelivered(tt.readings, "station-1", 1, tt.from, tt.to)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, "station-1", got.StationID)
+			assert.Equal(t, int32(1), got.ConnectorID)
+			assert.Equal(t, tt.wantWh, got.Wh.String())
+			assert.Equal(t, tt.wantConfidence, got.Confidence)
+			assert.Equal(t, tt.wantResets, got.Resets)
+			assert.Equal(t, tt.wantPossibleRollovers, got.PossibleRollovers)
+		})
+	}
+}
+
+func TestEnergyDelivered_InvalidPeriod(t *testing.T) {
+	// act
+	_, err := energyDelivered(nil, "station-1", 1, now, oneMinuteAgo)
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrInvalidPeriod)
+}
+
+func TestStationEnergyDelivered(t *testing.T) {
+	// arrange
+	readings := []domain.ConnectorReading{
+		{ConnectorID: 2, Reading: "1", ReadingValue: readingValue("1"), OccurredAt: twoMinutesAgo},
+		{ConnectorID: 1, Reading: "100", ReadingValue: readingValue("100"), OccurredAt: twoMinutesAgo},
+		{ConnectorID: 1, Reading: "0.3 kWh", ReadingValue: readingValue("0.3 kWh"), OccurredAt: now},
+		{ConnectorID: 2, Reading: "2", ReadingValue: readingValue("2"), OccurredAt: oneMinuteAgo},
+	}
+
+	// act
+	got, err := stationEnergyDelivered(readings, "station-1", twoMinutesAgo, now)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, "201", got.Wh.String())
+	assert.Equal(t, "0.201", got.KWh().String())
+	assert.Equal(t, domain.EnergyBracketed, got.Confidence)
+	require.Len(t, got.Connectors, 2)
+	assert.Equal(t, int32(1), got.Connectors[0].ConnectorID)
+	assert.Equal(t, domain.EnergyExact, got.Connectors[0].Confidence)
+	assert.Equal(t, int32(2), got.Connectors[1].ConnectorID)
+	assert.Equal(t, domain.EnergyBracketed, got.Connectors[1].Confidence)
+}
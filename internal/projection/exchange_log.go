@@ -0,0 +1,188 @@
+package projection
+
+import (
+	"sort"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// exchangeLog maintains the exchanges of the events added to it, paired as exchanges pairs them, so that they can be
+// queried without pairing all of the events again. Events may be added in any order.
+type exchangeLog struct {
+	// exchangesByKey holds the exchange of each correlation ID and request type, including those of responses without
+	// a request.
+	exchangesByKey map[exchangeKey]*exchange
+	// requested holds the exchanges with a request, ordered by when their requests occurred, and requestedByStationID
+	// those of each station.
+	requested            []*exchange
+	requestedByStationID map[string][]*exchange
+	// requestKeysByCorrelationID holds the keys of the exchanges with a request with each correlation ID, which an
+	// error with the correlation ID answers.
+	requestKeysByCorrelationID map[string][]exchangeKey
+	// errorsByCorrelationID holds the errors with each correlation ID, ordered by when they occurred.
+	errorsByCorrelationID map[string][]domain.Event
+	// orphanResponsesByKey and orphanErrorsByCorrelationID hold every response and error, including retransmissions,
+	// of the exchanges and correlation IDs without a request yet, ordered by when they occurred.
+	orphanResponsesByKey        map[exchangeKey][]domain.Event
+	orphanErrorsByCorrelationID map[string][]domain.Event
+}
+
+func newExchangeLog() *exchangeLog {
+	return &exchangeLog{
+		exchangesByKey:             make(map[exchangeKey]*exchange),
+		requestedByStationID:       make(map[string][]*exchange),
+		requestKeysByCorrelationID: make(map[string][]exchangeKey),
+		errorsByCorrelationID:      make(map[string][]domain.Event),
+
+		orphanResponsesByKey:        make(map[exchangeKey][]domain.Event),
+		orphanErrorsByCorrelationID: make(map[string][]domain.Event),
+	}
+}
+
+// add adds the event to its exchanges if it is a request, a response or an error.
+func (l *exchangeLog) add(event domain.Event) {
+	messageType, _ := domain.LookupMessageType(event.MessageType)
+	switch messageType.Kind {
+	case domain.MessageKindRequest:
+		l.addRequest(exchangeKey{correlationID: event.CorrelationID, requestType: event.MessageType}, event)
+	case domain.MessageKindResponse:
+		key := exchangeKey{correlationID: event.CorrelationID, requestType: messageType.RequestType}
+		e := l.exchange(key)
+		e.responses = insertMessage(e.responses, event)
+		if e.request == nil {
+			l.orphanResponsesByKey[key] = insertEvent(l.orphanResponsesByKey[key], event)
+		}
+	case domain.MessageKindError:
+		l.errorsByCorrelationID[event.CorrelationID] = insertMessage(l.errorsByCorrelationID[event.CorrelationID], event)
+		if len(l.requestKeysByCorrelationID[event.CorrelationID]) == 0 {
+			l.orphanErrorsByCorrelationID[event.CorrelationID] = insertEvent(l.orphanErrorsByCorrelationID[event.CorrelationID], event)
+		}
+		for _, key := range l.requestKeysByCorrelationID[event.CorrelationID] {
+			e := l.exchangesByKey[key]
+			e.errors = insertMessage(e.errors, event)
+		}
+	}
+}
+
+// addRequest makes the request the request of its exchange, unless the exchange already has an earlier one: a request
+// repeated with the same correlation ID is the same request.
+func (l *exchangeLog) addRequest(key exchangeKey, request domain.Event) {
+	e := l.exchange(key)
+	if e.request != nil {
+		if !e.request.After(request) {
+			return
+		}
+		l.requested = removeExchange(l.requested, e)
+		stationID := e.stationID()
+		l.requestedByStationID[stationID] = removeExchange(l.requestedByStationID[stationID], e)
+	} else {
+		l.requestKeysByCorrelationID[key.correlationID] = append(l.requestKeysByCorrelationID[key.correlationID], key)
+		e.errors = append([]domain.Event(nil), l.errorsByCorrelationID[key.correlationID]...)
+		delete(l.orphanResponsesByKey, key)
+		delete(l.orphanErrorsByCorrelationID, key.correlationID)
+	}
+
+	e.request = &request
+	l.requested = insertExchange(l.requested, e)
+	stationID := e.stationID()
+	l.requestedByStationID[stationID] = insertExchange(l.requestedByStationID[stationID], e)
+}
+
+// exchange returns the exchange with the key, creating it if it doesn't exist.
+func (l *exchangeLog) exchange(key exchangeKey) *exchange {
+	e, ok := l.exchangesByKey[key]
+	if !ok {
+		e = &exchange{}
+		l.exchangesByKey[key] = e
+	}
+
+	return e
+}
+
+// exchanges returns the exchanges with a request, ordered by when their requests occurred, and the responses and
+// errors without a request, ordered by when they occurred.
+func (l *exchangeLog) exchanges() ([]exchange, []domain.Event) {
+	var orphans []domain.Event
+	for _, responses := range l.orphanResponsesByKey {
+		orphans = append(orphans, responses...)
+	}
+	for _, errorEvents := range l.orphanErrorsByCorrelationID {
+		orphans = append(orphans, errorEvents...)
+	}
+	sort.SliceStable(orphans, func(i, j int) bool {
+		return orphans[j].After(orphans[i])
+	})
+
+	return copyExchanges(l.requested), orphans
+}
+
+// stationExchanges returns the exchanges of the requests sent to the station, ordered by when the requests occurred.
+func (l *exchangeLog) stationExchanges(stationID string) []exchange {
+	return copyExchanges(l.requestedByStationID[stationID])
+}
+
+// copyExchanges returns copies of the exchanges, which are unaffected by events added to the log afterwards, as the
+// events of an exchange are replaced rather than changed in place.
+func copyExchanges(exchanges []*exchange) []exchange {
+	copied := make([]exchange, 0, len(exchanges))
+	for _, e := range exchanges {
+		copied = append(copied, *e)
+	}
+
+	return copied
+}
+
+// insertMessage returns the events, which are ordered by when they occurred, with the event inserted in order. Of the
+// events carrying the same message, only the earliest is kept, as exchanges does. The events aren't changed in place.
+func insertMessage(events []domain.Event, event domain.Event) []domain.Event {
+	for i, other := range events {
+		if !other.SameMessage(event) {
+			continue
+		}
+		if !other.After(event) {
+			return events
+		}
+		events = append(events[:i:i], events[i+1:]...)
+		break
+	}
+
+	return insertEvent(events, event)
+}
+
+// insertEvent returns the events, which are ordered by when they occurred, with the event inserted after those which
+// didn't occur after it. The events aren't changed in place.
+func insertEvent(events []domain.Event, event domain.Event) []domain.Event {
+	i := sort.Search(len(events), func(i int) bool {
+		return events[i].After(event)
+	})
+
+	return append(events[:i:i], append([]domain.Event{event}, events[i:]...)...)
+}
+
+// insertExchange inserts the exchange into the exchanges, which are ordered by when their requests occurred, keeping
+// them in order.
+func insertExchange(exchanges []*exchange, e *exchange) []*exchange {
+	i := sort.Search(len(exchanges), func(i int) bool {
+		return exchanges[i].request.After(*e.request)
+	})
+	if i == len(exchanges) {
+		return append(exchanges, e)
+	}
+
+	exchanges = append(exchanges, nil)
+	copy(exchanges[i+1:], exchanges[i:])
+	exchanges[i] = e
+
+	return exchanges
+}
+
+// removeExchange removes the exchange from the exchanges.
+func removeExchange(exchanges []*exchange, e *exchange) []*exchange {
+	for i, other := range exchanges {
+		if other == e {
+			return append(exchanges[:i], exchanges[i+1:]...)
+		}
+	}
+
+	return exchanges
+}
@@ -0,0 +1,109 @@
+package projection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+func TestExchangeLog_MatchesExchanges(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	seconds := func(n int) time.Time {
+		return start.Add(time.Duration(n) * time.Second)
+	}
+	request := func(id, stationID, correlationID string, occurredAt time.Time) domain.Event {
+		return domain.Event{
+			ID:            id,
+			CorrelationID: correlationID,
+			MessageType:   domain.EventTypeMeterValuesRequest,
+			OccurredAt:    occurredAt,
+			Payload:       domain.MeterValuesRequestPayload{StationID: stationID, ConnectorID: 1},
+		}
+	}
+	response := func(id, correlationID string, occurredAt time.Time, reading string) domain.Event {
+		return domain.Event{
+			ID:            id,
+			CorrelationID: correlationID,
+			MessageType:   domain.EventTypeMeterValuesResponse,
+			OccurredAt:    occurredAt,
+			Payload: domain.MeterValuesResponsePayload{MeterValues: []domain.MeterValue{
+				domain.NewMeterValue(1, reading),
+			}},
+		}
+	}
+	callError := func(id, correlationID string, occurredAt time.Time) domain.Event {
+		return domain.Event{
+			ID:            id,
+			CorrelationID: correlationID,
+			MessageType:   domain.EventTypeCallError,
+			OccurredAt:    occurredAt,
+			Payload:       domain.CallErrorPayload{ErrorCode: domain.CallErrorInternalError},
+		}
+	}
+
+	events := []domain.Event{
+		request("request-1", "station-1", "correlation-1", seconds(0)),
+		response("response-1", "correlation-1", seconds(2), "100"),
+		// A retransmission of the response captured before it, and another captured after it.
+		response("response-1-early", "correlation-1", seconds(1), "100"),
+		response("response-1-late", "correlation-1", seconds(3), "100"),
+		// A request repeated later with the same correlation ID.
+		request("request-1-repeated", "station-1", "correlation-1", seconds(4)),
+		// An error captured before its request.
+		callError("error-2", "correlation-2", seconds(5)),
+		request("request-2", "station-2", "correlation-2", seconds(6)),
+		// A response and an error without a request, each retransmitted.
+		response("response-3", "correlation-3", seconds(7), "300"),
+		response("response-3-retransmitted", "correlation-3", seconds(8), "300"),
+		callError("error-4", "correlation-4", seconds(9)),
+		callError("error-4-retransmitted", "correlation-4", seconds(10)),
+		request("request-5", "station-1", "correlation-5", seconds(11)),
+	}
+	reversed := make([]domain.Event, 0, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		reversed = append(reversed, events[i])
+	}
+	interleaved := make([]domain.Event, 0, len(events))
+	for i := 0; i < len(events); i += 2 {
+		interleaved = append(interleaved, events[i])
+	}
+	for i := 1; i < len(events); i += 2 {
+		interleaved = append(interleaved, events[i])
+	}
+
+	tests := []struct {
+		name   string
+		events []domain.Event
+	}{
+		{name: "in order", events: events},
+		{name: "reversed", events: reversed},
+		{name: "interleaved", events: interleaved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			log := newExchangeLog()
+			for _, event := range tt.events {
+				log.add(event)
+			}
+			wantPaired, wantOrphans := exchanges(tt.events)
+
+			// act
+			paired, orphans := log.exchanges()
+
+			// assert
+			assert.Equal(t, wantPaired, paired)
+			assert.Equal(t, wantOrphans, orphans)
+			var wantStation1 []exchange
+			for _, e := range wantPaired {
+				if e.stationID() == "station-1" {
+					wantStation1 = append(wantStation1, e)
+				}
+			}
+			assert.Equal(t, wantStation1, log.stationExchanges("station-1"))
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package projection
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// failures counts each station's requests of each message type in the events, and those which were answered with an
+// error before any response, ordered by station and message type. Only the message types with a failed request are
+// included.
+func failures(events []domain.Event) ([]domain.RequestFailures, error) {
+	paired, _ := exchanges(events)
+
+	return failuresFromExchanges(paired)
+}
+
+// failuresFromExchanges counts each station's requests of each message type in the exchanges, and those which failed.
+func failuresFromExchanges(paired []exchange) ([]domain.RequestFailures, error) {
+	type failuresKey struct {
+		stationID   string
+		messageType string
+	}
+	failuresByKey := make(map[failuresKey]*domain.RequestFailures)
+	var keys []failuresKey
+	for _, e := range paired {
+		key := failuresKey{stationID: e.stationID(), messageType: e.request.MessageType}
+		requestFailures, ok := failuresByKey[key]
+		if !ok {
+			requestFailures = &domain.RequestFailures{StationID: key.stationID, MessageType: key.messageType}
+			failuresByKey[key] = requestFailures
+			keys = append(keys, key)
+		}
+
+		requestFailures.Requests++
+		if !e.failed() {
+			continue
+		}
+		answer, _ := e.firstAnswer()
+		payload, err := domain.EventPayload(answer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get event payload: %w", err)
+		}
+		requestFailures.Failed++
+		if requestFailures.FailedByErrorCode == nil {
+			requestFailures.FailedByErrorCode = make(map[domain.CallErrorCode]int)
+		}
+		requestFailures.FailedByErrorCode[payload.(domain.CallErrorPayload).ErrorCode]++
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].stationID != keys[j].stationID {
+			return keys[i].stationID < keys[j].stationID
+		}
+		return keys[i].messageType < keys[j].messageType
+	})
+	result := make([]domain.RequestFailures, 0, len(keys))
+	for _, key := range keys {
+		if requestFailures := failuresByKey[key]; requestFailures.Failed > 0 {
+			result = append(result, *requestFailures)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package projection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+	idgenerator "github.com/zucchinho/ocpp/internal/id_generator"
+	inmemoryeventsource "github.com/zucchinho/ocpp/internal/in_memory_event_source"
+)
+
+func TestFailures(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	seconds := func(n int) time.Time {
+		return start.Add(time.Duration(n) * time.Second)
+	}
+	meterValuesRequest := func(id string, occurredAt time.Time) domain.Event {
+		return domain.Event{
+			ID:            id,
+			MessageID:     "1",
+			CorrelationID: "correlation-" + id,
+			MessageType:   domain.EventTypeMeterValuesRequest,
+			OccurredAt:    occurredAt,
+			Payload:       domain.MeterValuesRequestPayload{StationID: "station-1", ConnectorID: 1},
+		}
+	}
+	meterValuesResponse := func(requestID string, occurredAt time.Time) domain.Event {
+		return domain.Event{
+			ID:            requestID + "-response",
+			MessageID:     "2",
+			CorrelationID: "correlation-" + requestID,
+			MessageType:   domain.EventTypeMeterValuesResponse,
+			OccurredAt:    occurredAt,
+			Payload: domain.MeterValuesResponsePayload{MeterValues: []domain.MeterValue{
+				domain.NewMeterValue(1, "100"),
+			}},
+		}
+	}
+	callError := func(requestID string, occurredAt time.Time, errorCode domain.CallErrorCode) domain.Event {
+		return domain.Event{
+			ID:            requestID + "-error",
+			MessageID:     "3",
+			CorrelationID: "correlation-" + requestID,
+			MessageType:   domain.EventTypeCallError,
+			OccurredAt:    occurredAt,
+			Payload:       domain.CallErrorPayload{ErrorCode: errorCode, ErrorDescription: "failed"},
+		}
+	}
+	authorize := func(id string, occurredAt time.Time) domain.Event {
+		return domain.Event{
+			ID:            id,
+			MessageID:     "1",
+			CorrelationID: "correlation-" + id,
+			MessageType:   domain.EventTypeAuthorize,
+			OccurredAt:    occurredAt,
+			Payload:       domain.AuthorizePayload{StationID: "station-2", IDTag: "tag-1"},
+		}
+	}
+	events := []domain.Event{
+		meterValuesRequest("request-1", seconds(0)),
+		callError("request-1", seconds(1), domain.CallErrorInternalError),
+		meterValuesRequest("request-2", seconds(2)),
+		meterValuesResponse("request-2", seconds(3)),
+		meterValuesRequest("request-3", seconds(4)),
+		callError("request-3", seconds(5), domain.CallErrorNotSupported),
+		// Answered, then failed.
+		meterValuesRequest("request-4", seconds(6)),
+		meterValuesResponse("request-4", seconds(7)),
+		callError("request-4", seconds(8), domain.CallErrorInternalError),
+		// Failed, then answered.
+		meterValuesRequest("request-5", seconds(9)),
+		callError("request-5", seconds(10), domain.CallErrorInternalError),
+		meterValuesResponse("request-5", seconds(11)),
+		{
+			ID:            "heartbeat-1",
+			MessageID:     "1",
+			CorrelationID: "correlation-heartbeat-1",
+			MessageType:   domain.EventTypeHeartbeat,
+			OccurredAt:    seconds(12),
+			Payload:       domain.HeartbeatPayload{StationID: "station-1"},
+		},
+		authorize("authorize-1", seconds(0)),
+		callError("authorize-1", seconds(1), domain.CallErrorFormationViolation),
+		// Unanswered.
+		authorize("authorize-2", seconds(2)),
+		// Without a request.
+		callError("request-6", seconds(3), domain.CallErrorGenericError),
+	}
+	want := []domain.RequestFailures{
+		{
+			StationID:   "station-1",
+			MessageType: domain.EventTypeMeterValuesRequest,
+			Requests:    5,
+			Failed:      3,
+			FailedByErrorCode: map[domain.CallErrorCode]int{
+				domain.CallErrorInternalError: 2,
+				domain.CallErrorNotSupported:  1,
+			},
+		},
+		{
+			StationID:         "station-2",
+			MessageType:       domain.EventTypeAuthorize,
+			Requests:          2,
+			Failed:            1,
+			FailedByErrorCode: map[domain.CallErrorCode]int{domain.CallErrorFormationViolation: 1},
+		},
+	}
+
+	ctx := context.Background()
+	eventSource := inmemoryeventsource.NewInMemoryEventSource(idgenerator.NewULIDGenerator(), domain.OrderBySequence)
+	ip := NewIncrementalProjection()
+	eventSource.Subscribe(ip)
+	for _, event := range events {
+		_, err := eventSource.Create(ctx, event)
+		require.NoError(t, err)
+	}
+
+	for name, projection := range map[string]domain.CorrelationProjection{
+		"basic":       NewBasicProjection(eventSource),
+		"incremental": ip,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// act
+			got, err := projection.Failures(ctx)
+
+			// assert
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
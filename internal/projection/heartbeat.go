@@ -0,0 +1,71 @@
+package projection
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// defaultHeartbeatInterval is the interval a station is assumed to send heartbeats at if it wasn't told one in
+// response to its latest BootNotification.
+const defaultHeartbeatInterval = 5 * time.Minute
+
+// missedHeartbeatIntervals is the number of heartbeat intervals a station may go without being heard from before it
+// is considered offline, so that a single late or lost heartbeat doesn't take it offline.
+const missedHeartbeatIntervals = 2
+
+// stationLifecycleFromLatestEvents fills in the charging station's details from its latest BootNotification, when it
+// last sent a Heartbeat, and whether it is online at the instant, judged by when it last sent either of them.
+func stationLifecycleFromLatestEvents(chargingStation *domain.ChargingStation, latestEvents map[string]domain.Event, now time.Time) error {
+	var lastHeardAt time.Time
+
+	if bootNotificationEvent, ok := latestEvents[domain.EventTypeBootNotification]; ok {
+		payload, err := domain.EventPayload(bootNotificationEvent)
+		if err != nil {
+			return fmt.Errorf("failed to get event payload: %w", err)
+		}
+		bootNotification := payload.(domain.BootNotificationPayload)
+		chargingStation.Vendor = bootNotification.ChargePointVendor
+		chargingStation.Model = bootNotification.ChargePointModel
+		chargingStation.SerialNumber = bootNotification.ChargePointSerialNumber
+		chargingStation.FirmwareVersion = bootNotification.FirmwareVersion
+
+		lastHeardAt = bootNotificationEvent.OccurredAt
+	}
+
+	if heartbeatEvent, ok := latestEvents[domain.EventTypeHeartbeat]; ok {
+		lastHeartbeatAt := heartbeatEvent.OccurredAt
+		chargingStation.LastHeartbeatAt = &lastHeartbeatAt
+
+		if lastHeartbeatAt.After(lastHeardAt) {
+			lastHeardAt = lastHeartbeatAt
+		}
+	}
+
+	if lastHeardAt.IsZero() {
+		return nil
+	}
+	if lastHeardAt.After(chargingStation.UpdatedAt) {
+		chargingStation.UpdatedAt = lastHeardAt
+	}
+
+	// The response paired with the latest BootNotification sets the interval, as a station forgets it on rebooting.
+	chargingStation.HeartbeatInterval = defaultHeartbeatInterval
+	if bootNotificationResponseEvent, ok := latestEvents[domain.EventTypeBootNotificationResponse]; ok {
+		payload, err := domain.EventPayload(bootNotificationResponseEvent)
+		if err != nil {
+			return fmt.Errorf("failed to get event payload: %w", err)
+		}
+		if interval := payload.(domain.BootNotificationResponsePayload).Interval; interval > 0 {
+			chargingStation.HeartbeatInterval = time.Duration(interval) * time.Second
+		}
+	}
+
+	chargingStation.Status = domain.StationOffline
+	if now.Sub(lastHeardAt) <= missedHeartbeatIntervals*chargingStation.HeartbeatInterval {
+		chargingStation.Status = domain.StationOnline
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package projection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+	idgenerator "github.com/zucchinho/ocpp/internal/id_generator"
+	inmemoryeventsource "github.com/zucchinho/ocpp/internal/in_memory_event_source"
+)
+
+func TestChargingStation_Lifecycle(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	minutes := func(n int) time.Time {
+		return start.Add(time.Duration(n) * time.Minute)
+	}
+	bootNotification := func(correlationID string, occurredAt time.Time, firmwareVersion string) domain.Event {
+		return domain.Event{
+			CorrelationID: correlationID,
+			MessageType:   domain.EventTypeBootNotification,
+			OccurredAt:    occurredAt,
+			Payload: domain.BootNotificationPayload{
+				StationID:               "station-1",
+				ChargePointVendor:       "vendor",
+				ChargePointModel:        "model",
+				ChargePointSerialNumber: "serial",
+				FirmwareVersion:         firmwareVersion,
+			},
+		}
+	}
+	bootNotificationResponse := func(correlationID string, occurredAt time.Time, interval int) domain.Event {
+		return domain.Event{
+			CorrelationID: correlationID,
+			MessageType:   domain.EventTypeBootNotificationResponse,
+			OccurredAt:    occurredAt,
+			Payload: domain.BootNotificationResponsePayload{
+				Status:      domain.RegistrationAccepted,
+				CurrentTime: occurredAt,
+				Interval:    interval,
+			},
+		}
+	}
+	heartbeat := func(correlationID string, occurredAt time.Time) domain.Event {
+		return domain.Event{
+			CorrelationID: correlationID,
+			MessageType:   domain.EventTypeHeartbeat,
+			OccurredAt:    occurredAt,
+			Payload:       domain.HeartbeatPayload{StationID: "station-1"},
+		}
+	}
+	// notification is sent by another station, and marks how late it is.
+	notification := func(occurredAt time.Time) domain.Event {
+		return domain.Event{
+			CorrelationID: "correlation-other",
+			MessageType:   domain.EventTypeMeterValuesNotification,
+			OccurredAt:    occurredAt,
+			Payload:       domain.MeterValuesNotificationPayload{StationID: "station-2"},
+		}
+	}
+	lastHeartbeatAt := minutes(20)
+
+	tests := []struct {
+		name   string
+		events []domain.Event
+		want   domain.ChargingStation
+	}{
+		{
+			name: "booted with an interval: online within two intervals",
+			events: []domain.Event{
+				bootNotification("correlation-1", minutes(0), "1.0"),
+				bootNotificationResponse("correlation-1", minutes(0), 600),
+				notification(minutes(20)),
+			},
+			want: domain.ChargingStation{
+				ID:                "station-1",
+				UpdatedAt:         minutes(0),
+				Vendor:            "vendor",
+				Model:             "model",
+				SerialNumber:      "serial",
+				FirmwareVersion:   "1.0",
+				HeartbeatInterval: 10 * time.Minute,
+				Status:            domain.StationOnline,
+			},
+		},
+		{
+			name: "booted with an interval: offline after two intervals",
+			events: []domain.Event{
+				bootNotification("correlation-1", minutes(0), "1.0"),
+				bootNotificationResponse("correlation-1", minutes(0), 600),
+				notification(minutes(21)),
+			},
+			want: domain.ChargingStation{
+				ID:                "station-1",
+				UpdatedAt:         minutes(0),
+				Vendor:            "vendor",
+				Model:             "model",
+				SerialNumber:      "serial",
+				FirmwareVersion:   "1.0",
+				HeartbeatInterval: 10 * time.Minute,
+				Status:            domain.StationOffline,
+			},
+		},
+		{
+			name: "heartbeat without boot notification: default interval",
+			events: []domain.Event{
+				heartbeat("correlation-1", minutes(20)),
+				notification(minutes(31)),
+			},
+			want: domain.ChargingStation{
+				ID:                "station-1",
+				UpdatedAt:         minutes(20),
+				LastHeartbeatAt:   &lastHeartbeatAt,
+				HeartbeatInterval: defaultHeartbeatInterval,
+				Status:            domain.StationOffline,
+			},
+		},
+		{
+			name: "rebooted: details and interval of the latest boot notification",
+			events: []domain.Event{
+				bootNotification("correlation-1", minutes(0), "1.0"),
+				bootNotificationResponse("correlation-1", minutes(0), 60),
+				bootNotification("correlation-2", minutes(10), "1.1"),
+				heartbeat("correlation-3", minutes(20)),
+				notification(minutes(25)),
+			},
+			want: domain.ChargingStation{
+				ID:                "station-1",
+				UpdatedAt:         minutes(20),
+				Vendor:            "vendor",
+				Model:             "model",
+				SerialNumber:      "serial",
+				FirmwareVersion:   "1.1",
+				LastHeartbeatAt:   &lastHeartbeatAt,
+				HeartbeatInterval: defaultHeartbeatInterval,
+				Status:            domain.StationOnline,
+			},
+		},
+		{
+			name: "never heard from: no status",
+			events: []domain.Event{
+				{
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeConnectorListRequest,
+					OccurredAt:    minutes(0),
+					Payload:       domain.ConnectorListRequestPayload{StationID: "station-1"},
+				},
+			},
+			want: domain.ChargingStation{
+				ID: "station-1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			ctx := context.Background()
+			eventSource := inmemoryeventsource.NewInMemoryEventSource(idgenerator.NewULIDGenerator(), domain.OrderBySequence)
+			ip := NewIncrementalProjection()
+			eventSource.Subscribe(ip)
+			for _, event := range tt.events {
+				_, err := eventSource.Create(ctx, event)
+				require.NoError(t, err)
+			}
+
+			for name, projection := range map[string]domain.ChargingStationProjection{
+				"basic":       NewBasicProjection(eventSource),
+				"incremental": ip,
+			} {
+				// act
+				got, err := projection.ChargingStation(ctx, "station-1")
+
+				// assert
+				assert.NoError(t, err, name)
+				assert.Equal(t, tt.want, got, name)
+			}
+		})
+	}
+}
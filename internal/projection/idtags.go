@@ -0,0 +1,155 @@
+package projection
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// idTagUsage returns how each idTag named by the Authorize, StartTransaction and StopTransaction requests in the
+// events has been used, ordered by idTag. The latest verdict on an idTag is taken from the response to the latest
+// request naming it which was answered with one.
+func idTagUsage(events []domain.Event) ([]domain.IDTagUsage, error) {
+	paired, _ := exchanges(events)
+
+	return idTagUsageFromExchanges(paired)
+}
+
+// idTagUsageFromExchanges returns how each idTag named by the exchanges' requests has been used, ordered by idTag. The
+// exchanges must be ordered by when their requests occurred.
+func idTagUsageFromExchanges(paired []exchange) ([]domain.IDTagUsage, error) {
+	usagesByIDTag := make(map[string]*domain.IDTagUsage)
+	stationIDsByIDTag := make(map[string]map[string]bool)
+	for _, e := range paired {
+		idTag, idTagInfo, err := exchangeIDTag(e)
+		if err != nil {
+			return nil, err
+		}
+		if idTag == "" {
+			continue
+		}
+
+		usage, ok := usagesByIDTag[idTag]
+		if !ok {
+			usage = &domain.IDTagUsage{IDTag: idTag}
+			usagesByIDTag[idTag] = usage
+			stationIDsByIDTag[idTag] = make(map[string]bool)
+		}
+		stationID := e.stationID()
+		if stationID != "" && !stationIDsByIDTag[idTag][stationID] {
+			stationIDsByIDTag[idTag][stationID] = true
+			usage.StationIDs = append(usage.StationIDs, stationID)
+		}
+		// The exchanges are ordered by when their requests occurred.
+		usage.LastSeenAt = e.request.OccurredAt
+		usage.LastSeenStationID = stationID
+		if idTagInfo != nil {
+			usage.LastStatus = idTagInfo.Status
+		}
+	}
+
+	usages := make([]domain.IDTagUsage, 0, len(usagesByIDTag))
+	for _, usage := range usagesByIDTag {
+		sort.Strings(usage.StationIDs)
+		usages = append(usages, *usage)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].IDTag < usages[j].IDTag
+	})
+
+	return usages, nil
+}
+
+// exchangeIDTag returns the idTag named by the exchange's request, if it is an Authorize, StartTransaction or
+// StopTransaction, and the central system's verdict on it from the exchange's first response, if it gave one.
+func exchangeIDTag(e exchange) (string, *domain.IDTagInfo, error) {
+	payload, err := domain.EventPayload(*e.request)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get event payload: %w", err)
+	}
+
+	var idTag string
+	switch payload := payload.(type) {
+	case domain.AuthorizePayload:
+		idTag = payload.IDTag
+	case domain.StartTransactionPayload:
+		idTag = payload.IDTag
+	case domain.StopTransactionPayload:
+		idTag = payload.IDTag
+	default:
+		return "", nil, nil
+	}
+	if len(e.responses) == 0 {
+		return idTag, nil, nil
+	}
+
+	responsePayload, err := domain.EventPayload(e.responses[0])
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get event payload: %w", err)
+	}
+	switch response := responsePayload.(type) {
+	case domain.AuthorizeResponsePayload:
+		return idTag, &response.IDTagInfo, nil
+	case domain.StartTransactionResponsePayload:
+		return idTag, &response.IDTagInfo, nil
+	case domain.StopTransactionResponsePayload:
+		return idTag, response.IDTagInfo, nil
+	}
+
+	return idTag, nil, nil
+}
+
+// authorizations counts the verdicts on each station's Authorize requests in the events, ordered by station. A request
+// is judged by its first response.
+func authorizations(events []domain.Event) ([]domain.StationAuthorizations, error) {
+	paired, _ := exchanges(events)
+
+	return authorizationsFromExchanges(paired)
+}
+
+// authorizationsFromExchanges counts the verdicts on each station's Authorize requests in the exchanges, ordered by
+// station.
+func authorizationsFromExchanges(paired []exchange) ([]domain.StationAuthorizations, error) {
+	authorizationsByStationID := make(map[string]*domain.StationAuthorizations)
+	var stationIDs []string
+	for _, e := range paired {
+		if e.request.MessageType != domain.EventTypeAuthorize {
+			continue
+		}
+		_, idTagInfo, err := exchangeIDTag(e)
+		if err != nil {
+			return nil, err
+		}
+
+		stationID := e.stationID()
+		stationAuthorizations, ok := authorizationsByStationID[stationID]
+		if !ok {
+			stationAuthorizations = &domain.StationAuthorizations{StationID: stationID}
+			authorizationsByStationID[stationID] = stationAuthorizations
+			stationIDs = append(stationIDs, stationID)
+		}
+
+		stationAuthorizations.Total++
+		switch {
+		case idTagInfo == nil:
+			stationAuthorizations.Unanswered++
+		case idTagInfo.Status == domain.AuthorizationAccepted:
+			stationAuthorizations.Accepted++
+		default:
+			stationAuthorizations.Rejected++
+			if stationAuthorizations.RejectedByStatus == nil {
+				stationAuthorizations.RejectedByStatus = make(map[domain.AuthorizationStatus]int)
+			}
+			stationAuthorizations.RejectedByStatus[idTagInfo.Status]++
+		}
+	}
+
+	sort.Strings(stationIDs)
+	result := make([]domain.StationAuthorizations, 0, len(stationIDs))
+	for _, stationID := range stationIDs {
+		result = append(result, *authorizationsByStationID[stationID])
+	}
+
+	return result, nil
+}
@@ -0,0 +1,135 @@
+package projection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+func TestIDTags(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	minutes := func(n int) time.Time {
+		return start.Add(time.Duration(n) * time.Minute)
+	}
+	authorize := func(id string, occurredAt time.Time, stationID string, idTag string) domain.Event {
+		return domain.Event{
+			ID:            id,
+			CorrelationID: "correlation-" + id,
+			MessageType:   domain.EventTypeAuthorize,
+			OccurredAt:    occurredAt,
+			Payload:       domain.AuthorizePayload{StationID: stationID, IDTag: idTag},
+		}
+	}
+	authorizeResponse := func(requestID string, occurredAt time.Time, status domain.AuthorizationStatus) domain.Event {
+		return domain.Event{
+			ID:            requestID + "-response",
+			CorrelationID: "correlation-" + requestID,
+			MessageType:   domain.EventTypeAuthorizeResponse,
+			OccurredAt:    occurredAt,
+			Payload:       domain.AuthorizeResponsePayload{IDTagInfo: domain.IDTagInfo{Status: status}},
+		}
+	}
+	events := []domain.Event{
+		authorize("authorize-1", minutes(0), "station-2", "tag-1"),
+		authorizeResponse("authorize-1", minutes(0), domain.AuthorizationAccepted),
+		authorize("authorize-2", minutes(1), "station-1", "tag-1"),
+		authorizeResponse("authorize-2", minutes(1), domain.AuthorizationAccepted),
+		{
+			ID:            "start-1",
+			CorrelationID: "correlation-start-1",
+			MessageType:   domain.EventTypeStartTransaction,
+			OccurredAt:    minutes(2),
+			Payload:       domain.StartTransactionPayload{StationID: "station-1", ConnectorID: 1, IDTag: "tag-1"},
+		},
+		// Stopped by another idTag, without a verdict on it.
+		{
+			ID:            "stop-1",
+			CorrelationID: "correlation-stop-1",
+			MessageType:   domain.EventTypeStopTransaction,
+			OccurredAt:    minutes(3),
+			Payload:       domain.StopTransactionPayload{StationID: "station-1", TransactionID: 1, IDTag: "tag-2"},
+		},
+		{
+			ID:            "stop-1-response",
+			CorrelationID: "correlation-stop-1",
+			MessageType:   domain.EventTypeStopTransactionResponse,
+			OccurredAt:    minutes(3),
+			Payload:       domain.StopTransactionResponsePayload{},
+		},
+		authorize("authorize-3", minutes(4), "station-1", "tag-3"),
+		authorizeResponse("authorize-3", minutes(4), domain.AuthorizationBlocked),
+		authorize("authorize-4", minutes(5), "station-1", "tag-3"),
+		authorizeResponse("authorize-4", minutes(5), domain.AuthorizationExpired),
+		authorize("authorize-5", minutes(6), "station-1", "tag-4"),
+		authorizeResponse("authorize-5", minutes(6), domain.AuthorizationInvalid),
+		// Unanswered.
+		authorize("authorize-6", minutes(7), "station-2", "tag-1"),
+	}
+
+	t.Run("idTags", func(t *testing.T) {
+		// act
+		got, err := idTagUsage(events)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, []domain.IDTagUsage{
+			{
+				IDTag:             "tag-1",
+				StationIDs:        []string{"station-1", "station-2"},
+				LastSeenAt:        minutes(7),
+				LastSeenStationID: "station-2",
+				LastStatus:        domain.AuthorizationAccepted,
+			},
+			{
+				IDTag:             "tag-2",
+				StationIDs:        []string{"station-1"},
+				LastSeenAt:        minutes(3),
+				LastSeenStationID: "station-1",
+			},
+			{
+				IDTag:             "tag-3",
+				StationIDs:        []string{"station-1"},
+				LastSeenAt:        minutes(5),
+				LastSeenStationID: "station-1",
+				LastStatus:        domain.AuthorizationExpired,
+			},
+			{
+				IDTag:             "tag-4",
+				StationIDs:        []string{"station-1"},
+				LastSeenAt:        minutes(6),
+				LastSeenStationID: "station-1",
+				LastStatus:        domain.AuthorizationInvalid,
+			},
+		}, got)
+	})
+
+	t.Run("authorizations", func(t *testing.T) {
+		// act
+		got, err := authorizations(events)
+
+		// assert
+		require.NoError(t, err)
+		assert.Equal(t, []domain.StationAuthorizations{
+			{
+				StationID: "station-1",
+				Total:     4,
+				Accepted:  1,
+				Rejected:  3,
+				RejectedByStatus: map[domain.AuthorizationStatus]int{
+					domain.AuthorizationBlocked: 1,
+					domain.AuthorizationExpired: 1,
+					domain.AuthorizationInvalid: 1,
+				},
+			},
+			{
+				StationID:  "station-2",
+				Total:      2,
+				Accepted:   1,
+				Unanswered: 1,
+			},
+		}, got)
+	})
+}
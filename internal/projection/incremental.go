@@ -0,0 +1,435 @@
+package projection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// IncrementalProjection maintains the state of the charging stations by folding in each event as it is created,
+// so that queries don't need to rescan the event source.
+//
+// It answers the same questions as the BasicProjection, with the same semantics.
+type IncrementalProjection struct {
+	mu sync.RWMutex
+	// stationIDs holds the IDs of the known stations, in the order they were first seen.
+	stationIDs []string
+	// latestEventsByStationID holds the latest request/notification event of each message type for each station.
+	latestEventsByStationID map[string]map[string]domain.Event
+	// latestResponsesByCorrelationID holds the latest response event of each message type for each correlation ID.
+	// Responses don't carry a station ID, and may be folded in before their request, so they are kept aside and
+	// paired with the station's latest request when queried.
+	latestResponsesByCorrelationID map[string]map[string]domain.Event
+	// meterValuesEventsByStationID holds the meter values events of each station, in the order they were handled, and
+	// stationIDsByCorrelationID the station of each correlation ID. Responses handled before any event naming the
+	// station with their correlation ID are kept in pendingMeterValuesEventsByCorrelationID until one is handled.
+	meterValuesEventsByStationID            map[string][]domain.Event
+	stationIDsByCorrelationID               map[string]string
+	pendingMeterValuesEventsByCorrelationID map[string][]domain.Event
+	// meterValuesRequestsByCorrelationID holds the first MeterValuesRequest with each correlation ID, the connector of
+	// which selects the readings trusted from its responses.
+	meterValuesRequestsByCorrelationID map[string]domain.Event
+	// statusNotificationsByStationID holds the StatusNotification events of each station, in the order they were
+	// handled.
+	statusNotificationsByStationID map[string][]domain.Event
+	// exchanges holds the requests folded in paired with their responses and errors, which the correlation, latency,
+	// validation, session, idTag and failure queries are answered from.
+	exchanges *exchangeLog
+	// events holds the events folded in, in the order they were handled, and eventIndexesByOccurredAt their indexes
+	// ordered by when they occurred, for views as of an earlier instant to be folded from.
+	events                   []domain.Event
+	eventIndexesByOccurredAt []int
+	eventIDs                 map[string]bool
+	// latestOccurredAt is when the latest event folded in occurred, the instant stations are judged online at.
+	latestOccurredAt time.Time
+}
+
+var (
+	_ domain.Projection   = &IncrementalProjection{}
+	_ domain.EventHandler = &IncrementalProjection{}
+)
+
+func NewIncrementalProjection() *IncrementalProjection {
+	return &IncrementalProjection{
+		latestEventsByStationID:        make(map[string]map[string]domain.Event),
+		latestResponsesByCorrelationID: make(map[string]map[string]domain.Event),
+		eventIDs:                       make(map[string]bool),
+
+		meterValuesEventsByStationID:            make(map[string][]domain.Event),
+		meterValuesRequestsByCorrelationID:      make(map[string]domain.Event),
+		stationIDsByCorrelationID:               make(map[string]string),
+		pendingMeterValuesEventsByCorrelationID: make(map[string][]domain.Event),
+		statusNotificationsByStationID:          make(map[string][]domain.Event),
+		exchanges:                               newExchangeLog(),
+	}
+}
+
+// HandleEvent folds the event into the projection. Handling the same event more than once has no further effect.
+func (ip *IncrementalProjection) HandleEvent(ctx context.Context, event domain.Event) error {
+	stationID, err := stationIDFromEvent(event)
+	if err != nil {
+		return fmt.Errorf("station ID from event: %w", err)
+	}
+
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+
+	if event.ID == "" || !ip.eventIDs[event.ID] {
+		ip.eventIDs[event.ID] = true
+		ip.indexEvent(event)
+		ip.exchanges.add(event)
+		ip.foldMeterValuesEvent(stationID, event)
+		if event.MessageType == domain.EventTypeStatusNotification && stationID != "" {
+			ip.statusNotificationsByStationID[stationID] = append(ip.statusNotificationsByStationID[stationID], event)
+		}
+		if event.OccurredAt.After(ip.latestOccurredAt) {
+			ip.latestOccurredAt = event.OccurredAt
+		}
+	}
+
+	switch messageType, _ := domain.LookupMessageType(event.MessageType); messageType.Kind {
+	case domain.MessageKindResponse:
+		foldLatestEvent(ip.latestResponsesByCorrelationID, event.CorrelationID, event)
+	default:
+		if stationID == "" {
+			return nil
+		}
+		if _, ok := ip.latestEventsByStationID[stationID]; !ok {
+			ip.stationIDs = append(ip.stationIDs, stationID)
+		}
+		foldLatestEvent(ip.latestEventsByStationID, stationID, event)
+	}
+
+	return nil
+}
+
+// Replay folds all of the events currently in the event source into the projection.
+func (ip *IncrementalProjection) Replay(ctx context.Context, eventSource domain.EventSource) error {
+	for _, event := range eventSource.GetAll(ctx) {
+		if err := ip.HandleEvent(ctx, event); err != nil {
+			return fmt.Errorf("handle event %s: %w", event.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (ip *IncrementalProjection) NumChargingStations(ctx context.Context) (int, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	return len(ip.stationIDs), nil
+}
+
+func (ip *IncrementalProjection) NumConnectors(ctx context.Context, stationID string) (int, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	stationEvents, ok := ip.stationEventsForStationID(stationID)
+	if !ok {
+		return 0, domain.ErrChargingStationNotFound
+	}
+
+	return numConnectorsFromLatestEvents(stationEvents.latest)
+}
+
+func (ip *IncrementalProjection) ChargingStation(ctx context.Context, stationID string) (domain.ChargingStation, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	stationEvents, ok := ip.stationEventsForStationID(stationID)
+	if !ok {
+		return domain.ChargingStation{}, domain.ErrChargingStationNotFound
+	}
+
+	return chargingStationFromStationEvents(stationID, stationEvents)
+}
+
+func (ip *IncrementalProjection) ChargingStations(ctx context.Context) ([]domain.ChargingStation, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	var chargingStations []domain.ChargingStation
+	for _, stationID := range ip.stationIDs {
+		stationEvents, _ := ip.stationEventsForStationID(stationID)
+		chargingStation, err := chargingStationFromStationEvents(stationID, stationEvents)
+		if err == nil {
+			chargingStations = append(chargingStations, chargingStation)
+		}
+	}
+
+	return chargingStations, nil
+}
+
+func (ip *IncrementalProjection) ConnectorConsistency(ctx context.Context, stationID string) (domain.ConnectorConsistency, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	stationEvents, ok := ip.stationEventsForStationID(stationID)
+	if !ok {
+		return domain.ConnectorConsistency{}, domain.ErrChargingStationNotFound
+	}
+
+	return connectorConsistencyFromLatestEvents(stationID, stationEvents.latest)
+}
+
+func (ip *IncrementalProjection) ConnectorReadings(ctx context.Context, stationID string, connectorID int32, from, to time.Time) ([]domain.ConnectorReading, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	readings, err := ip.stationReadings(stationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return selectConnectorReadings(readings, connectorID, from, to), nil
+}
+
+func (ip *IncrementalProjection) EnergyDelivered(ctx context.Context, stationID string, connectorID int32, from, to time.Time) (domain.EnergyDelivered, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	readings, err := ip.stationReadings(stationID)
+	if err != nil {
+		return domain.EnergyDelivered{}, err
+	}
+
+	return energyDelivered(readings, stationID, connectorID, from, to)
+}
+
+func (ip *IncrementalProjection) StationEnergyDelivered(ctx context.Context, stationID string, from, to time.Time) (domain.StationEnergyDelivered, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	readings, err := ip.stationReadings(stationID)
+	if err != nil {
+		return domain.StationEnergyDelivered{}, err
+	}
+
+	return stationEnergyDelivered(readings, stationID, from, to)
+}
+
+func (ip *IncrementalProjection) ReadingAnomalies(ctx context.Context, stationID string) ([]domain.ReadingAnomaly, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	readings, err := ip.stationReadings(stationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return readingAnomalies(readings), nil
+}
+
+func (ip *IncrementalProjection) Correlations(ctx context.Context, at time.Time, deadline time.Duration) (domain.CorrelationReport, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	if at.IsZero() {
+		at = ip.latestOccurredAt
+	}
+	paired, orphanResponses := ip.exchanges.exchanges()
+
+	return correlationReportFromExchanges(paired, orphanResponses, at, deadline)
+}
+
+func (ip *IncrementalProjection) RequestLatencies(ctx context.Context) ([]domain.StationLatencies, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	paired, _ := ip.exchanges.exchanges()
+
+	return requestLatenciesFromExchanges(paired), nil
+}
+
+func (ip *IncrementalProjection) ValidationLog(ctx context.Context, filter domain.ValidationLogFilter) ([]domain.ResponseViolation, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	paired, _ := ip.exchanges.exchanges()
+
+	return validationLogFromExchanges(paired, filter)
+}
+
+func (ip *IncrementalProjection) ConnectorStatusTransitions(ctx context.Context, stationID string) ([]domain.ConnectorStatusTransition, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	_, transitions, err := connectorStatuses(stationID, ip.statusNotificationsByStationID[stationID])
+	return transitions, err
+}
+
+func (ip *IncrementalProjection) Sessions(ctx context.Context, stationID string) ([]domain.Session, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	readings, err := ip.stationReadings(stationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionsFromExchanges(ip.exchanges.stationExchanges(stationID), readings, stationID)
+}
+
+func (ip *IncrementalProjection) IDTags(ctx context.Context) ([]domain.IDTagUsage, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	paired, _ := ip.exchanges.exchanges()
+
+	return idTagUsageFromExchanges(paired)
+}
+
+func (ip *IncrementalProjection) Authorizations(ctx context.Context) ([]domain.StationAuthorizations, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	paired, _ := ip.exchanges.exchanges()
+
+	return authorizationsFromExchanges(paired)
+}
+
+func (ip *IncrementalProjection) Failures(ctx context.Context) ([]domain.RequestFailures, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	paired, _ := ip.exchanges.exchanges()
+
+	return failuresFromExchanges(paired)
+}
+
+// stationReadings returns the readings of all of the station's connectors, in the order their events were created in.
+// The caller must hold the lock.
+func (ip *IncrementalProjection) stationReadings(stationID string) ([]domain.ConnectorReading, error) {
+	events := append([]domain.Event(nil), ip.meterValuesEventsByStationID[stationID]...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Sequence < events[j].Sequence
+	})
+
+	var readings []domain.ConnectorReading
+	for _, event := range events {
+		var request *domain.Event
+		if meterValuesRequestEvent, ok := ip.meterValuesRequestsByCorrelationID[event.CorrelationID]; ok && event.MessageType == domain.EventTypeMeterValuesResponse {
+			request = &meterValuesRequestEvent
+		}
+		eventReadings, err := readingsFromEvent(stationID, event, request)
+		if err != nil {
+			return nil, fmt.Errorf("readings from event %s: %w", event.ID, err)
+		}
+		readings = append(readings, eventReadings...)
+	}
+
+	return readings, nil
+}
+
+// AsOf returns a view of the projection over the events folded in so far which occurred at or before the instant.
+// Only those events are folded into the view, in the order they were handled. Unlike the projection itself, the view
+// isn't updated as further events are folded in.
+func (ip *IncrementalProjection) AsOf(at time.Time) domain.Projection {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	n := sort.Search(len(ip.eventIndexesByOccurredAt), func(i int) bool {
+		return ip.events[ip.eventIndexesByOccurredAt[i]].OccurredAt.After(at)
+	})
+	indexes := append([]int(nil), ip.eventIndexesByOccurredAt[:n]...)
+	sort.Ints(indexes)
+
+	asOf := NewIncrementalProjection()
+	for _, i := range indexes {
+		// The events were folded in successfully once, so they fold in again.
+		_ = asOf.HandleEvent(context.Background(), ip.events[i])
+	}
+
+	return asOf
+}
+
+// indexEvent appends the event to the events folded in, and inserts its index after those of the events which didn't
+// occur after it. The caller must hold the lock.
+func (ip *IncrementalProjection) indexEvent(event domain.Event) {
+	ip.events = append(ip.events, event)
+
+	i := sort.Search(len(ip.eventIndexesByOccurredAt), func(i int) bool {
+		return ip.events[ip.eventIndexesByOccurredAt[i]].OccurredAt.After(event.OccurredAt)
+	})
+	ip.eventIndexesByOccurredAt = append(ip.eventIndexesByOccurredAt, 0)
+	copy(ip.eventIndexesByOccurredAt[i+1:], ip.eventIndexesByOccurredAt[i:])
+	ip.eventIndexesByOccurredAt[i] = len(ip.events) - 1
+}
+
+// stationEventsForStationID returns the events of the station its read model is built from: the latest events of
+// each message type for the station, including the latest response to each of the station's latest requests, and its
+// StatusNotification events. The caller must hold the lock.
+func (ip *IncrementalProjection) stationEventsForStationID(stationID string) (stationEvents, bool) {
+	latestStationEvents, ok := ip.latestEventsByStationID[stationID]
+	if !ok {
+		return stationEvents{}, false
+	}
+
+	latestEvents := make(map[string]domain.Event, 2*len(latestStationEvents))
+	for messageType, event := range latestStationEvents {
+		latestEvents[messageType] = event
+
+		registered, _ := domain.LookupMessageType(messageType)
+		if registered.Kind != domain.MessageKindRequest {
+			continue
+		}
+		if response, ok := ip.latestResponsesByCorrelationID[event.CorrelationID][registered.ResponseType]; ok {
+			latestEvents[registered.ResponseType] = response
+		}
+	}
+
+	return stationEvents{
+		latest:              latestEvents,
+		statusNotifications: ip.statusNotificationsByStationID[stationID],
+		now:                 ip.latestOccurredAt,
+	}, true
+}
+
+// foldMeterValuesEvent keeps the event under the station it is attributed to if it is a meter values event. The
+// station is the one named by its payload, else the one it was attributed to by the event source, else the one of the
+// events with its correlation ID. A MeterValuesRequest is kept by its correlation ID. The caller must hold the lock.
+func (ip *IncrementalProjection) foldMeterValuesEvent(stationID string, event domain.Event) {
+	if stationID == "" {
+		stationID = event.StationID
+	}
+	if _, ok := ip.stationIDsByCorrelationID[event.CorrelationID]; !ok && stationID != "" && event.CorrelationID != "" {
+		ip.stationIDsByCorrelationID[event.CorrelationID] = stationID
+		ip.meterValuesEventsByStationID[stationID] = append(ip.meterValuesEventsByStationID[stationID], ip.pendingMeterValuesEventsByCorrelationID[event.CorrelationID]...)
+		delete(ip.pendingMeterValuesEventsByCorrelationID, event.CorrelationID)
+	}
+
+	if _, ok := ip.meterValuesRequestsByCorrelationID[event.CorrelationID]; !ok && event.MessageType == domain.EventTypeMeterValuesRequest {
+		ip.meterValuesRequestsByCorrelationID[event.CorrelationID] = event
+	}
+	if event.MessageType != domain.EventTypeMeterValuesNotification && event.MessageType != domain.EventTypeMeterValuesResponse {
+		return
+	}
+	if stationID == "" {
+		stationID = ip.stationIDsByCorrelationID[event.CorrelationID]
+	}
+	if stationID == "" {
+		if event.CorrelationID != "" {
+			ip.pendingMeterValuesEventsByCorrelationID[event.CorrelationID] = append(ip.pendingMeterValuesEventsByCorrelationID[event.CorrelationID], event)
+		}
+		return
+	}
+	ip.meterValuesEventsByStationID[stationID] = append(ip.meterValuesEventsByStationID[stationID], event)
+}
+
+// foldLatestEvent keeps the event under the key if it is the first, or newer than the existing one, of its message type.
+func foldLatestEvent(latestEvents map[string]map[string]domain.Event, key string, event domain.Event) {
+	eventsByType, ok := latestEvents[key]
+	if !ok {
+		eventsByType = make(map[string]domain.Event)
+		latestEvents[key] = eventsByType
+	}
+
+	if existing, ok := eventsByType[event.MessageType]; !ok || event.After(existing) {
+		eventsByType[event.MessageType] = event
+	}
+}
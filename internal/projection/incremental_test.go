@@ -0,0 +1,445 @@
+package projection
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+	idgenerator "github.com/zucchinho/ocpp/internal/id_generator"
+	inmemoryeventsource "github.com/zucchinho/ocpp/internal/in_memory_event_source"
+)
+
+func TestIncrementalProjection_ChargingStation(t *testing.T) {
+	tests := []struct {
+		name      string
+		events    []domain.Event
+		stationID string
+		want      domain.ChargingStation
+		wantErr   error
+	}{
+		{
+			name: "no events for station",
+			events: []domain.Event{
+				{
+					ID:            "event-1",
+					MessageID:     "message-1",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeMeterValuesRequest,
+					OccurredAt:    oneMinuteAgo,
+					Payload: domain.MeterValuesRequestPayload{
+						StationID: "station-1",
+					},
+				},
+			},
+			stationID: "station-2",
+			wantErr:   domain.ErrChargingStationNotFound,
+		},
+		{
+			name: "response handled before request",
+			events: []domain.Event{
+				{
+					ID:            "event-2",
+					MessageID:     "message-2",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeConnectorListResponse,
+					OccurredAt:    now,
+					Payload: domain.ConnectorListResponsePayload{
+						NumConnectors: 2,
+					},
+				},
+				{
+					ID:            "event-1",
+					MessageID:     "message-1",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeConnectorListRequest,
+					OccurredAt:    oneMinuteAgo,
+					Payload: domain.ConnectorListRequestPayload{
+						StationID: "station-1",
+					},
+				},
+			},
+			stationID: "station-1",
+			want: domain.ChargingStation{
+				ID:            "station-1",
+				NumConnectors: 2,
+				UpdatedAt:     now,
+			},
+		},
+		{
+			name: "meter values notification and meter values request/response: connector reading updated",
+			events: []domain.Event{
+				{
+					ID:            "event-3",
+					MessageID:     "message-3",
+					CorrelationID: "correlation-2",
+					MessageType:   domain.EventTypeMeterValuesResponse,
+					OccurredAt:    now,
+					Payload: domain.MeterValuesResponsePayload{
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "120"),
+						},
+					},
+				},
+				{
+					ID:            "event-1",
+					MessageID:     "message-1",
+					CorrelationID: "correlation-1",
+					MessageType:   domain.EventTypeMeterValuesNotification,
+					OccurredAt:    twoMinutesAgo,
+					Payload: domain.MeterValuesNotificationPayload{
+						StationID: "station-1",
+						MeterValues: []domain.MeterValue{
+							domain.NewMeterValue(1, "100"),
+							domain.NewMeterValue(2, "200"),
+						},
+					},
+				},
+				{
+					ID:            "event-2",
+					MessageID:     "message-2",
+					CorrelationID: "correlation-2",
+					MessageType:   domain.EventTypeMeterValuesRequest,
+					OccurredAt:    oneMinuteAgo,
+					Payload: domain.MeterValuesRequestPayload{
+						StationID: "station-1",
+					},
+				},
+			},
+			stationID: "station-1",
+			want: domain.ChargingStation{
+				ID:            "station-1",
+				NumConnectors: 2,
+				UpdatedAt:     now,
+				Connectors: []domain.Connector{
+					{
+						ID:                1,
+						ChargingStationID: "station-1",
+						Reading:           "120",
+						ReadingValue:      wh("120"),
+						UpdatedAt:         now,
+					},
+					{
+						ID:                2,
+						ChargingStationID: "station-1",
+						Reading:           "200",
+						ReadingValue:      wh("200"),
+						UpdatedAt:         twoMinutesAgo,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			ip := NewIncrementalProjection()
+			for _, event := range tt.events {
+				require.NoError(t, ip.HandleEvent(context.Background(), event))
+			}
+
+			// act
+			got, err := ip.ChargingStation(context.Background(), tt.stationID)
+
+			// assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIncrementalProjection_HandleEvent_Idempotent(t *testing.T) {
+	// arrange
+	ip := NewIncrementalProjection()
+	event := domain.Event{
+		ID:            "event-1",
+		MessageID:     "message-1",
+		CorrelationID: "correlation-1",
+		MessageType:   domain.EventTypeMeterValuesNotification,
+		OccurredAt:    now,
+		Payload: domain.MeterValuesNotificationPayload{
+			StationID: "station-1",
+			MeterValues: []domain.MeterValue{
+				domain.NewMeterValue(1, "100"),
+			},
+		},
+	}
+
+	// act
+	require.NoError(t, ip.HandleEvent(context.Background(), event))
+	require.NoError(t, ip.HandleEvent(context.Background(), event))
+
+	// assert
+	numChargingStations, err := ip.NumChargingStations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, numChargingStations)
+
+	numConnectors, err := ip.NumConnectors(context.Background(), "station-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, numConnectors)
+}
+
+func TestIncrementalProjection_HandleEvent_UnknownEventType(t *testing.T) {
+	// arrange
+	ip := NewIncrementalProjection()
+
+	// act
+	err := ip.HandleEvent(context.Background(), domain.Event{
+		ID:          "event-1",
+		MessageType: "Unknown",
+	})
+
+	// assert
+	assert.Error(t, err)
+}
+
+// sampleEventFiles are the sample events, with those exercising each kind of message kept apart from events.json.
+var sampleEventFiles = []string{
+	"../../events.json",
+	"../../testdata/lifecycle.json",
+	"../../testdata/statuses.json",
+	"../../testdata/sessions.json",
+	"../../testdata/authorizations.json",
+	"../../testdata/failures.json",
+}
+
+// readEventFiles returns the events of the files, in order.
+func readEventFiles(t *testing.T, paths ...string) []domain.Event {
+	t.Helper()
+
+	var events []domain.Event
+	for _, path := range paths {
+		jsonBytes, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var fileEvents []domain.Event
+		require.NoError(t, json.Unmarshal(jsonBytes, &fileEvents))
+		events = append(events, fileEvents...)
+	}
+
+	return events
+}
+
+func TestIncrementalProjection_MatchesBasicProjection(t *testing.T) {
+	// arrange
+	events := readEventFiles(t, sampleEventFiles...)
+
+	ctx := context.Background()
+	eventSource := inmemoryeventsource.NewInMemoryEventSource(idgenerator.NewULIDGenerator(), domain.OrderBySequence)
+	ip := NewIncrementalProjection()
+	eventSource.Subscribe(ip)
+	for _, event := range events {
+		_, err := eventSource.Create(ctx, event)
+		require.NoError(t, err)
+	}
+	bp := NewBasicProjection(eventSource)
+
+	// act
+	wantNumChargingStations, err := bp.NumChargingStations(ctx)
+	require.NoError(t, err)
+	gotNumChargingStations, err := ip.NumChargingStations(ctx)
+	require.NoError(t, err)
+
+	wantChargingStations, err := bp.ChargingStations(ctx)
+	require.NoError(t, err)
+	gotChargingStations, err := ip.ChargingStations(ctx)
+	require.NoError(t, err)
+
+	wantCorrelations, err := bp.Correlations(ctx, time.Time{}, time.Minute)
+	require.NoError(t, err)
+	gotCorrelations, err := ip.Correlations(ctx, time.Time{}, time.Minute)
+	require.NoError(t, err)
+
+	wantLatencies, err := bp.RequestLatencies(ctx)
+	require.NoError(t, err)
+	gotLatencies, err := ip.RequestLatencies(ctx)
+	require.NoError(t, err)
+
+	wantValidationLog, err := bp.ValidationLog(ctx, domain.ValidationLogFilter{})
+	require.NoError(t, err)
+	gotValidationLog, err := ip.ValidationLog(ctx, domain.ValidationLogFilter{})
+	require.NoError(t, err)
+
+	wantIDTags, err := bp.IDTags(ctx)
+	require.NoError(t, err)
+	gotIDTags, err := ip.IDTags(ctx)
+	require.NoError(t, err)
+
+	wantAuthorizations, err := bp.Authorizations(ctx)
+	require.NoError(t, err)
+	gotAuthorizations, err := ip.Authorizations(ctx)
+	require.NoError(t, err)
+
+	wantFailures, err := bp.Failures(ctx)
+	require.NoError(t, err)
+	gotFailures, err := ip.Failures(ctx)
+	require.NoError(t, err)
+
+	// assert
+	assert.Equal(t, wantNumChargingStations, gotNumChargingStations)
+	assert.ElementsMatch(t, wantChargingStations, gotChargingStations)
+	assert.Equal(t, wantCorrelations, gotCorrelations)
+	assert.NotEmpty(t, gotLatencies)
+	assert.Equal(t, wantLatencies, gotLatencies)
+	assert.Equal(t, wantValidationLog, gotValidationLog)
+	assert.NotEmpty(t, gotIDTags)
+	assert.Equal(t, wantIDTags, gotIDTags)
+	assert.NotEmpty(t, gotAuthorizations)
+	assert.Equal(t, wantAuthorizations, gotAuthorizations)
+	assert.NotEmpty(t, gotFailures)
+	assert.Equal(t, wantFailures, gotFailures)
+	for _, station := range wantChargingStations {
+		wantNumConnectors, err := bp.NumConnectors(ctx, station.ID)
+		require.NoError(t, err)
+		gotNumConnectors, err := ip.NumConnectors(ctx, station.ID)
+		require.NoError(t, err)
+		assert.Equal(t, wantNumConnectors, gotNumConnectors, station.ID)
+
+		wantEnergy, err := bp.StationEnergyDelivered(ctx, station.ID, time.Time{}, time.Time{})
+		require.NoError(t, err)
+		gotEnergy, err := ip.StationEnergyDelivered(ctx, station.ID, time.Time{}, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, wantEnergy, gotEnergy, station.ID)
+
+		wantAnomalies, err := bp.ReadingAnomalies(ctx, station.ID)
+		require.NoError(t, err)
+		gotAnomalies, err := ip.ReadingAnomalies(ctx, station.ID)
+		require.NoError(t, err)
+		assert.Equal(t, wantAnomalies, gotAnomalies, station.ID)
+
+		wantTransitions, err := bp.ConnectorStatusTransitions(ctx, station.ID)
+		require.NoError(t, err)
+		gotTransitions, err := ip.ConnectorStatusTransitions(ctx, station.ID)
+		require.NoError(t, err)
+		assert.Equal(t, wantTransitions, gotTransitions, station.ID)
+
+		wantSessions, err := bp.Sessions(ctx, station.ID)
+		require.NoError(t, err)
+		gotSessions, err := ip.Sessions(ctx, station.ID)
+		require.NoError(t, err)
+		assert.Equal(t, wantSessions, gotSessions, station.ID)
+
+		for _, connector := range station.Connectors {
+			wantReadings, err := bp.ConnectorReadings(ctx, station.ID, connector.ID, time.Time{}, time.Time{})
+			require.NoError(t, err)
+			gotReadings, err := ip.ConnectorReadings(ctx, station.ID, connector.ID, time.Time{}, time.Time{})
+			require.NoError(t, err)
+			assert.NotEmpty(t, gotReadings)
+			assert.Equal(t, wantReadings, gotReadings, station.ID)
+		}
+	}
+}
+
+func TestIncrementalProjection_AsOf_MatchesBasicProjection(t *testing.T) {
+	// arrange
+	events := readEventFiles(t, sampleEventFiles...)
+
+	ctx := context.Background()
+	eventSource := inmemoryeventsource.NewInMemoryEventSource(idgenerator.NewULIDGenerator(), domain.OrderBySequence)
+	ip := NewIncrementalProjection()
+	eventSource.Subscribe(ip)
+	for _, event := range events {
+		_, err := eventSource.Create(ctx, event)
+		require.NoError(t, err)
+	}
+	bp := NewBasicProjection(eventSource)
+
+	for _, event := range events {
+		for _, at := range []time.Time{event.OccurredAt.Add(-time.Nanosecond), event.OccurredAt} {
+			// act
+			wantChargingStations, err := bp.AsOf(at).ChargingStations(ctx)
+			require.NoError(t, err)
+			gotChargingStations, err := ip.AsOf(at).ChargingStations(ctx)
+			require.NoError(t, err)
+
+			// assert
+			assert.ElementsMatch(t, wantChargingStations, gotChargingStations, at)
+		}
+	}
+}
+
+func TestIncrementalProjection_AsOf_NotUpdated(t *testing.T) {
+	// arrange
+	ip := NewIncrementalProjection()
+	newEvent := func(id string, occurredAt time.Time) domain.Event {
+		return domain.Event{
+			ID:          id,
+			MessageType: domain.EventTypeMeterValuesNotification,
+			OccurredAt:  occurredAt,
+			Payload: domain.MeterValuesNotificationPayload{
+				StationID: id,
+			},
+		}
+	}
+	require.NoError(t, ip.HandleEvent(context.Background(), newEvent("station-1", twoMinutesAgo)))
+	require.NoError(t, ip.HandleEvent(context.Background(), newEvent("station-2", now)))
+
+	// act
+	asOf := ip.AsOf(oneMinuteAgo)
+	require.NoError(t, ip.HandleEvent(context.Background(), newEvent("station-3", twoMinutesAgo)))
+
+	// assert
+	numChargingStations, err := asOf.NumChargingStations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, numChargingStations)
+
+	numChargingStations, err = ip.AsOf(oneMinuteAgo).NumChargingStations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, numChargingStations)
+}
+
+func TestIncrementalProjection_ConnectorReadings_ResponseBeforeRequest(t *testing.T) {
+	// arrange
+	ip := NewIncrementalProjection()
+	for _, event := range []domain.Event{
+		{
+			ID:            "event-2",
+			MessageID:     "message-2",
+			CorrelationID: "correlation-1",
+			MessageType:   domain.EventTypeMeterValuesResponse,
+			OccurredAt:    now,
+			Payload: domain.MeterValuesResponsePayload{
+				MeterValues: []domain.MeterValue{
+					domain.NewMeterValue(1, "120"),
+				},
+			},
+		},
+		{
+			ID:            "event-1",
+			MessageID:     "message-1",
+			CorrelationID: "correlation-1",
+			MessageType:   domain.EventTypeMeterValuesRequest,
+			OccurredAt:    oneMinuteAgo,
+			Payload: domain.MeterValuesRequestPayload{
+				StationID: "station-1",
+			},
+		},
+	} {
+		require.NoError(t, ip.HandleEvent(context.Background(), event))
+	}
+
+	// act
+	got, err := ip.ConnectorReadings(context.Background(), "station-1", 1, time.Time{}, time.Time{})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.ConnectorReading{
+		{
+			StationID:    "station-1",
+			ConnectorID:  1,
+			Reading:      "120",
+			ReadingValue: wh("120"),
+			OccurredAt:   now,
+			MessageType:  domain.EventTypeMeterValuesResponse,
+			EventID:      "event-2",
+		},
+	}, got)
+}
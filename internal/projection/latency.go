@@ -0,0 +1,100 @@
+package projection
+
+import (
+	"sort"
+	"time"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// requestLatencies returns the statistics of the time each station took to answer the requests in the events, from
+// each request to its first response which didn't occur before it.
+func requestLatencies(events []domain.Event) []domain.StationLatencies {
+	paired, _ := exchanges(events)
+
+	return requestLatenciesFromExchanges(paired)
+}
+
+// requestLatenciesFromExchanges returns the statistics of the time each station took to answer the exchanges'
+// requests.
+func requestLatenciesFromExchanges(paired []exchange) []domain.StationLatencies {
+	var stationIDs []string
+	latenciesByStationID := make(map[string]map[string][]time.Duration)
+	for _, e := range paired {
+		latency, ok := e.latency()
+		if !ok {
+			continue
+		}
+
+		stationID := e.stationID()
+		latenciesByMessageType, ok := latenciesByStationID[stationID]
+		if !ok {
+			latenciesByMessageType = make(map[string][]time.Duration)
+			latenciesByStationID[stationID] = latenciesByMessageType
+			stationIDs = append(stationIDs, stationID)
+		}
+		latenciesByMessageType[e.request.MessageType] = append(latenciesByMessageType[e.request.MessageType], latency)
+	}
+	sort.Strings(stationIDs)
+
+	stationLatencies := make([]domain.StationLatencies, 0, len(stationIDs))
+	for _, stationID := range stationIDs {
+		latenciesByMessageType := latenciesByStationID[stationID]
+		messageTypes := make([]string, 0, len(latenciesByMessageType))
+		for messageType := range latenciesByMessageType {
+			messageTypes = append(messageTypes, messageType)
+		}
+		sort.Strings(messageTypes)
+
+		var all []time.Duration
+		latencies := domain.StationLatencies{StationID: stationID}
+		for _, messageType := range messageTypes {
+			all = append(all, latenciesByMessageType[messageType]...)
+			latencies.ByMessageType = append(latencies.ByMessageType, latencyStats(messageType, latenciesByMessageType[messageType]))
+		}
+		latencies.All = latencyStats("", all)
+		stationLatencies = append(stationLatencies, latencies)
+	}
+
+	return stationLatencies
+}
+
+// latency returns the time from the exchange's request to its first response which didn't occur before it, or false if
+// there is no such response.
+func (e exchange) latency() (time.Duration, bool) {
+	for _, response := range e.responses {
+		if latency := response.OccurredAt.Sub(e.request.OccurredAt); latency >= 0 {
+			return latency, true
+		}
+	}
+
+	return 0, false
+}
+
+// latencyStats returns the statistics of the latencies of requests of the message type, which must not be empty.
+func latencyStats(messageType string, latencies []time.Duration) domain.LatencyStats {
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	return domain.LatencyStats{
+		MessageType: messageType,
+		Count:       len(sorted),
+		Min:         sorted[0],
+		P50:         percentile(sorted, 50),
+		P95:         percentile(sorted, 95),
+		Max:         sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the percentile of the sorted latencies by the nearest rank method: the smallest latency which
+// is at least as large as the percentage of them.
+func percentile(sorted []time.Duration, percentage int) time.Duration {
+	rank := (percentage*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
@@ -0,0 +1,131 @@
+package projection
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+func TestRequestLatencies(t *testing.T) {
+	// arrange
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	var events []domain.Event
+	exchange := func(stationID, requestType, responseType string, latency time.Duration) {
+		correlationID := fmt.Sprintf("correlation-%d", len(events))
+		events = append(events,
+			domain.Event{
+				CorrelationID: correlationID,
+				MessageType:   requestType,
+				OccurredAt:    start,
+				StationID:     stationID,
+			},
+			domain.Event{
+				CorrelationID: correlationID,
+				MessageType:   responseType,
+				OccurredAt:    start.Add(latency),
+			},
+		)
+	}
+	for i := 1; i <= 20; i++ {
+		exchange("station-2", domain.EventTypeMeterValuesRequest, domain.EventTypeMeterValuesResponse, time.Duration(i)*time.Second)
+	}
+	exchange("station-2", domain.EventTypeConnectorListRequest, domain.EventTypeConnectorListResponse, time.Minute)
+	exchange("station-1", domain.EventTypeMeterValuesRequest, domain.EventTypeMeterValuesResponse, time.Second)
+	// A response before its request isn't a latency.
+	exchange("station-1", domain.EventTypeMeterValuesRequest, domain.EventTypeMeterValuesResponse, -time.Second)
+	// Nor is a request without a response.
+	events = append(events, domain.Event{
+		CorrelationID: "unanswered",
+		MessageType:   domain.EventTypeMeterValuesRequest,
+		OccurredAt:    start,
+		Payload:       domain.MeterValuesRequestPayload{StationID: "station-1"},
+	})
+
+	// act
+	latencies := requestLatencies(events)
+
+	// assert
+	assert.Equal(t, []domain.StationLatencies{
+		{
+			StationID: "station-1",
+			All:       domain.LatencyStats{Count: 1, Min: time.Second, P50: time.Second, P95: time.Second, Max: time.Second},
+			ByMessageType: []domain.LatencyStats{
+				{MessageType: domain.EventTypeMeterValuesRequest, Count: 1, Min: time.Second, P50: time.Second, P95: time.Second, Max: time.Second},
+			},
+		},
+		{
+			StationID: "station-2",
+			All:       domain.LatencyStats{Count: 21, Min: time.Second, P50: 11 * time.Second, P95: 20 * time.Second, Max: time.Minute},
+			ByMessageType: []domain.LatencyStats{
+				{MessageType: domain.EventTypeConnectorListRequest, Count: 1, Min: time.Minute, P50: time.Minute, P95: time.Minute, Max: time.Minute},
+				{MessageType: domain.EventTypeMeterValuesRequest, Count: 20, Min: time.Second, P50: 10 * time.Second, P95: 19 * time.Second, Max: 20 * time.Second},
+			},
+		},
+	}, latencies)
+}
+
+func TestRequestLatencies_ResponseBeforeRequest(t *testing.T) {
+	// arrange
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []domain.Event{
+		{
+			CorrelationID: "correlation-1",
+			MessageType:   domain.EventTypeMeterValuesRequest,
+			OccurredAt:    start,
+			StationID:     "station-1",
+		},
+		// Captured with a clock behind the request's, so it isn't a latency.
+		{
+			CorrelationID: "correlation-1",
+			MessageType:   domain.EventTypeMeterValuesResponse,
+			OccurredAt:    start.Add(-time.Second),
+			Payload:       domain.MeterValuesResponsePayload{},
+		},
+		{
+			CorrelationID: "correlation-1",
+			MessageType:   domain.EventTypeMeterValuesResponse,
+			OccurredAt:    start.Add(2 * time.Second),
+			Payload:       domain.MeterValuesResponsePayload{MeterValues: []domain.MeterValue{domain.NewMeterValue(1, "100")}},
+		},
+	}
+
+	// act
+	latencies := requestLatencies(events)
+
+	// assert
+	stats := domain.LatencyStats{Count: 1, Min: 2 * time.Second, P50: 2 * time.Second, P95: 2 * time.Second, Max: 2 * time.Second}
+	byMessageType := stats
+	byMessageType.MessageType = domain.EventTypeMeterValuesRequest
+	assert.Equal(t, []domain.StationLatencies{
+		{
+			StationID:     "station-1",
+			All:           stats,
+			ByMessageType: []domain.LatencyStats{byMessageType},
+		},
+	}, latencies)
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	tests := []struct {
+		percentage int
+		want       time.Duration
+	}{
+		{percentage: 0, want: 1},
+		{percentage: 10, want: 1},
+		{percentage: 11, want: 2},
+		{percentage: 50, want: 5},
+		{percentage: 95, want: 10},
+		{percentage: 100, want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("p%d", tt.percentage), func(t *testing.T) {
+			assert.Equal(t, tt.want, percentile(sorted, tt.percentage))
+		})
+	}
+}
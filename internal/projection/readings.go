@@ -0,0 +1,124 @@
+package projection
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// meterValuesEventTypes are the message types of the events which report connector readings.
+var meterValuesEventTypes = []string{
+	domain.EventTypeMeterValuesNotification,
+	domain.EventTypeMeterValuesResponse,
+}
+
+// readingsFromEvent returns the readings reported by the meter values event, attributed to the station. Of a
+// MeterValuesResponse, only the readings of the connector its request asked for are trusted, as by ChargingStation,
+// unless the request is nil as it isn't known. Events of other message types report no readings.
+func readingsFromEvent(stationID string, event domain.Event, request *domain.Event) ([]domain.ConnectorReading, error) {
+	payload, err := domain.EventPayload(event)
+	if err != nil {
+		return nil, fmt.Errorf("event payload: %w", err)
+	}
+
+	var meterValues []domain.MeterValue
+	switch payload := payload.(type) {
+	case domain.MeterValuesNotificationPayload:
+		meterValues = payload.MeterValues
+	case domain.MeterValuesResponsePayload:
+		meterValues, err = requestedMeterValues(request, payload)
+		if err != nil {
+			return nil, fmt.Errorf("requested meter values: %w", err)
+		}
+	default:
+		return nil, nil
+	}
+
+	readings := make([]domain.ConnectorReading, 0, len(meterValues))
+	for _, meterValue := range meterValues {
+		readings = append(readings, domain.ConnectorReading{
+			StationID:    stationID,
+			ConnectorID:  meterValue.ConnectorID,
+			Reading:      meterValue.Reading,
+			ReadingValue: meterValue.Value,
+			OccurredAt:   event.OccurredAt,
+			MessageType:  event.MessageType,
+			EventID:      event.ID,
+		})
+	}
+
+	return readings, nil
+}
+
+// meterValuesRequest returns the first of the events which is a MeterValuesRequest, or nil if there isn't one.
+func meterValuesRequest(events []domain.Event) *domain.Event {
+	for _, event := range events {
+		if event.MessageType == domain.EventTypeMeterValuesRequest {
+			return &event
+		}
+	}
+
+	return nil
+}
+
+// selectConnectorReadings returns the readings of the connector in the window, ordered by when they occurred. Of the
+// readings of the same value at the same time, only the first is kept. The readings must be in the order their events
+// were created in, which orders the readings which occurred at the same time.
+func selectConnectorReadings(readings []domain.ConnectorReading, connectorID int32, from, to time.Time) []domain.ConnectorReading {
+	var selected []domain.ConnectorReading
+	for _, reading := range readings {
+		if reading.ConnectorID != connectorID || !inWindow(reading.OccurredAt, from, to) {
+			continue
+		}
+		selected = append(selected, reading)
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].OccurredAt.Before(selected[j].OccurredAt)
+	})
+
+	var deduplicated []domain.ConnectorReading
+	var seen map[string]bool
+	for i, reading := range selected {
+		if i == 0 || !reading.OccurredAt.Equal(selected[i-1].OccurredAt) {
+			seen = make(map[string]bool)
+		}
+		if seen[reading.Reading] {
+			continue
+		}
+		seen[reading.Reading] = true
+		deduplicated = append(deduplicated, reading)
+	}
+
+	return deduplicated
+}
+
+// readingConnectorIDs returns the IDs of the connectors with readings, in ascending order.
+func readingConnectorIDs(readings []domain.ConnectorReading) []int32 {
+	var connectorIDs []int32
+	seen := make(map[int32]bool)
+	for _, reading := range readings {
+		if !seen[reading.ConnectorID] {
+			seen[reading.ConnectorID] = true
+			connectorIDs = append(connectorIDs, reading.ConnectorID)
+		}
+	}
+	sortConnectorIDs(connectorIDs)
+
+	return connectorIDs
+}
+
+// inWindow reports whether the time is in the window from the time, inclusive, to the time, exclusive, with the
+// window unbounded at a zero time.
+func inWindow(t, from, to time.Time) bool {
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && !t.Before(to) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,144 @@
+package projection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+	idgenerator "github.com/zucchinho/ocpp/internal/id_generator"
+	inmemoryeventsource "github.com/zucchinho/ocpp/internal/in_memory_event_source"
+)
+
+func TestSelectConnectorReadings(t *testing.T) {
+	readings := []domain.ConnectorReading{
+		{ConnectorID: 1, Reading: "120", OccurredAt: now, EventID: "event-3"},
+		{ConnectorID: 1, Reading: "100", OccurredAt: twoMinutesAgo, EventID: "event-1"},
+		{ConnectorID: 2, Reading: "200", OccurredAt: twoMinutesAgo, EventID: "event-1"},
+		{ConnectorID: 1, Reading: "110", OccurredAt: oneMinuteAgo, EventID: "event-2"},
+		{ConnectorID: 1, Reading: "110", OccurredAt: oneMinuteAgo, EventID: "event-4"},
+		{ConnectorID: 1, Reading: "111", OccurredAt: oneMinuteAgo, EventID: "event-5"},
+	}
+
+	tests := []struct {
+		name        string
+		connectorID int32
+		from        time.Time
+		to          time.Time
+		want        []string
+	}{
+		{
+			name:        "unbounded",
+			connectorID: 1,
+			// The reading of the same value at the same time by event-4 is dropped.
+			want: []string{"event-1", "event-2", "event-5", "event-3"},
+		},
+		{
+			name:        "from is inclusive",
+			connectorID: 1,
+			from:        oneMinuteAgo,
+			want:        []string{"event-2", "event-5", "event-3"},
+		},
+		{
+			name:        "to is exclusive",
+			connectorID: 1,
+			to:          oneMinuteAgo,
+			want:        []string{"event-1"},
+		},
+		{
+			name:        "other connector",
+			connectorID: 2,
+			want:        []string{"event-1"},
+		},
+		{
+			name:        "no readings",
+			connectorID: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got := selectConnectorReadings(readings, tt.connectorID, tt.from, tt.to)
+
+			// assert
+			var gotEventIDs []string
+			for _, reading := range got {
+				assert.Equal(t, tt.connectorID, reading.ConnectorID)
+				gotEventIDs = append(gotEventIDs, reading.EventID)
+			}
+			assert.Equal(t, tt.want, gotEventIDs)
+		})
+	}
+}
+
+func TestReadings_UnrequestedConnector(t *testing.T) {
+	// arrange
+	request := func(id, correlationID string, occurredAt time.Time) domain.Event {
+		return domain.Event{
+			ID:            id,
+			MessageID:     "message-" + id,
+			CorrelationID: correlationID,
+			MessageType:   domain.EventTypeMeterValuesRequest,
+			OccurredAt:    occurredAt,
+			Payload:       domain.MeterValuesRequestPayload{StationID: "station-1", ConnectorID: 1},
+		}
+	}
+	// Each response also has a meter value for connector 2, which its request didn't ask for.
+	response := func(id, correlationID string, occurredAt time.Time, reading, unrequestedReading string) domain.Event {
+		return domain.Event{
+			ID:            id,
+			MessageID:     "message-" + id,
+			CorrelationID: correlationID,
+			MessageType:   domain.EventTypeMeterValuesResponse,
+			OccurredAt:    occurredAt,
+			Payload: domain.MeterValuesResponsePayload{MeterValues: []domain.MeterValue{
+				domain.NewMeterValue(1, reading),
+				domain.NewMeterValue(2, unrequestedReading),
+			}},
+		}
+	}
+	events := []domain.Event{
+		request("request-1", "correlation-1", twoMinutesAgo),
+		response("response-1", "correlation-1", twoMinutesAgo, "100", "400"),
+		// Captured before its request.
+		response("response-2", "correlation-2", now, "200", "900000"),
+		request("request-2", "correlation-2", now),
+	}
+
+	ctx := context.Background()
+	eventSource := inmemoryeventsource.NewInMemoryEventSource(idgenerator.NewULIDGenerator(), domain.OrderBySequence)
+	ip := NewIncrementalProjection()
+	eventSource.Subscribe(ip)
+	for _, event := range events {
+		_, err := eventSource.Create(ctx, event)
+		require.NoError(t, err)
+	}
+
+	for name, projection := range map[string]domain.Projection{
+		"basic":       NewBasicProjection(eventSource),
+		"incremental": ip,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// act
+			readings, err := projection.ConnectorReadings(ctx, "station-1", 2, time.Time{}, time.Time{})
+			require.NoError(t, err)
+			connectorReadings, err := projection.ConnectorReadings(ctx, "station-1", 1, time.Time{}, time.Time{})
+			require.NoError(t, err)
+			energy, err := projection.StationEnergyDelivered(ctx, "station-1", time.Time{}, time.Time{})
+			require.NoError(t, err)
+			anomalies, err := projection.ReadingAnomalies(ctx, "station-1")
+			require.NoError(t, err)
+
+			// assert
+			assert.Empty(t, readings)
+			assert.Len(t, connectorReadings, 2)
+			assert.Equal(t, "100", energy.Wh.String())
+			require.Len(t, energy.Connectors, 1)
+			assert.Equal(t, int32(1), energy.Connectors[0].ConnectorID)
+			assert.Empty(t, anomalies)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package projection
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// sessionTolerancePercent is how far, as a percentage of the energy a session's transaction delivered, the energy
+// measured from the meter values may differ from it for the session to be consistent. At least 1 Wh is tolerated.
+const sessionTolerancePercent = 1
+
+// transactionEventTypes are the message types of the events which sessions are made from.
+var transactionEventTypes = []string{
+	domain.EventTypeStartTransaction,
+	domain.EventTypeStartTransactionResponse,
+	domain.EventTypeStopTransaction,
+	domain.EventTypeStopTransactionResponse,
+}
+
+// sessions pairs the station's StartTransaction and StopTransaction events in the events by the transaction ID
+// assigned in response to the StartTransaction, and checks the energy each stopped session delivered against the
+// readings of the station's connectors. A StopTransaction for a transaction whose start hasn't been seen is a session
+// of its own, and a StopTransaction for a session which has already stopped is ignored.
+func sessions(events []domain.Event, readings []domain.ConnectorReading, stationID string) ([]domain.Session, error) {
+	paired, _ := exchanges(events)
+
+	return sessionsFromExchanges(paired, readings, stationID)
+}
+
+// sessionsFromExchanges returns the station's sessions from the exchanges, which must be ordered by when their
+// requests occurred, checked against the readings of the station's connectors.
+func sessionsFromExchanges(paired []exchange, readings []domain.ConnectorReading, stationID string) ([]domain.Session, error) {
+	var stationSessions []*domain.Session
+	sessionsByTransactionID := make(map[int]*domain.Session)
+	for _, e := range paired {
+		if e.stationID() != stationID {
+			continue
+		}
+		payload, err := domain.EventPayload(*e.request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get event payload: %w", err)
+		}
+
+		switch payload := payload.(type) {
+		case domain.StartTransactionPayload:
+			session := &domain.Session{
+				StationID:    stationID,
+				ConnectorID:  payload.ConnectorID,
+				IDTag:        payload.IDTag,
+				StartedAt:    transactionTime(payload.Timestamp, *e.request),
+				MeterStart:   payload.MeterStart,
+				StartEventID: e.request.ID,
+			}
+			if len(e.responses) > 0 {
+				responsePayload, err := domain.EventPayload(e.responses[0])
+				if err != nil {
+					return nil, fmt.Errorf("failed to get event payload: %w", err)
+				}
+				response := responsePayload.(domain.StartTransactionResponsePayload)
+				transactionID := response.TransactionID
+				session.TransactionID = &transactionID
+				session.IDTagStatus = response.IDTagInfo.Status
+				sessionsByTransactionID[transactionID] = session
+			}
+			stationSessions = append(stationSessions, session)
+		case domain.StopTransactionPayload:
+			session, ok := sessionsByTransactionID[payload.TransactionID]
+			if ok && session.StoppedAt != nil {
+				continue
+			}
+			if !ok {
+				transactionID := payload.TransactionID
+				session = &domain.Session{
+					StationID:     stationID,
+					TransactionID: &transactionID,
+				}
+				sessionsByTransactionID[transactionID] = session
+				stationSessions = append(stationSessions, session)
+			}
+
+			stoppedAt := transactionTime(payload.Timestamp, *e.request)
+			meterStop := payload.MeterStop
+			session.StoppedAt = &stoppedAt
+			session.MeterStop = &meterStop
+			session.StopReason = payload.Reason
+			if session.StopReason == "" {
+				session.StopReason = domain.StopReasonLocal
+			}
+			if session.IDTag == "" {
+				session.IDTag = payload.IDTag
+			}
+			session.StopEventID = e.request.ID
+		}
+	}
+
+	result := make([]domain.Session, 0, len(stationSessions))
+	for _, session := range stationSessions {
+		if err := checkSession(session, readings); err != nil {
+			return nil, err
+		}
+		result = append(result, *session)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].ConnectorID != result[j].ConnectorID {
+			return result[i].ConnectorID < result[j].ConnectorID
+		}
+		return result[i].StartedAt.Before(result[j].StartedAt)
+	})
+
+	return result, nil
+}
+
+// checkSession measures the energy delivered by a session which has started and stopped, and checks it against the
+// energy measured from the connector's readings during the session.
+func checkSession(session *domain.Session, readings []domain.ConnectorReading) error {
+	session.Check = domain.SessionUnchecked
+	if session.StartEventID == "" || session.StopEventID == "" {
+		return nil
+	}
+
+	session.Duration = session.StoppedAt.Sub(session.StartedAt)
+	wh := domain.MustParseDecimal(strconv.Itoa(*session.MeterStop - session.MeterStart))
+	session.Wh = &wh
+	// A session which stopped before it started can't be measured.
+	if session.Duration < 0 {
+		return nil
+	}
+
+	energy, err := energyDelivered(readings, session.StationID, session.ConnectorID, session.StartedAt, *session.StoppedAt)
+	if err != nil {
+		return fmt.Errorf("energy delivered by connector %d: %w", session.ConnectorID, err)
+	}
+	if energy.Confidence == domain.EnergyUnknown {
+		return nil
+	}
+	session.MeterValues = &energy
+	session.Check = sessionCheck(wh, energy)
+
+	return nil
+}
+
+// sessionCheck judges whether the energy measured from the meter values agrees with the energy a session's
+// transaction delivered.
+func sessionCheck(wh domain.Decimal, energy domain.EnergyDelivered) domain.SessionCheck {
+	difference := energy.Wh.Sub(wh)
+	if difference.Sign() < 0 {
+		difference = wh.Sub(energy.Wh)
+	}
+	tolerance := wh.MulRatio(sessionTolerancePercent, 100, 0)
+	if minimum := domain.MustParseDecimal("1"); tolerance.Cmp(minimum) < 0 {
+		tolerance = minimum
+	}
+	if difference.Cmp(tolerance) <= 0 {
+		return domain.SessionConsistent
+	}
+
+	// Energy measured from the nearest readings inside the session, or across a possible rollover, may be missing some,
+	// so only counts against the transaction if it is more.
+	if (energy.Confidence == domain.EnergyBracketed || energy.Confidence == domain.EnergyUncertain) && energy.Wh.Cmp(wh) < 0 {
+		return domain.SessionUnchecked
+	}
+
+	return domain.SessionInconsistent
+}
+
+// transactionTime returns when a transaction started or stopped according to the station, or when its event occurred
+// if the station didn't say.
+func transactionTime(timestamp time.Time, event domain.Event) time.Time {
+	if timestamp.IsZero() {
+		return event.OccurredAt
+	}
+
+	return timestamp
+}
@@ -0,0 +1,273 @@
+package projection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+	idgenerator "github.com/zucchinho/ocpp/internal/id_generator"
+	inmemoryeventsource "github.com/zucchinho/ocpp/internal/in_memory_event_source"
+)
+
+func TestSessions(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	minutes := func(n int) time.Time {
+		return start.Add(time.Duration(n) * time.Minute)
+	}
+	meterValues := func(id string, occurredAt time.Time, readings ...string) domain.Event {
+		payload := domain.MeterValuesNotificationPayload{StationID: "station-1"}
+		for i, reading := range readings {
+			payload.MeterValues = append(payload.MeterValues, domain.NewMeterValue(int32(i+1), reading))
+		}
+		return domain.Event{
+			ID:            id,
+			CorrelationID: "correlation-" + id,
+			MessageType:   domain.EventTypeMeterValuesNotification,
+			OccurredAt:    occurredAt,
+			Payload:       payload,
+		}
+	}
+	startTransaction := func(id string, occurredAt time.Time, connectorID int32, idTag string, meterStart int) domain.Event {
+		return domain.Event{
+			ID:            id,
+			CorrelationID: "correlation-" + id,
+			MessageType:   domain.EventTypeStartTransaction,
+			OccurredAt:    occurredAt,
+			Payload: domain.StartTransactionPayload{
+				StationID:   "station-1",
+				ConnectorID: connectorID,
+				IDTag:       idTag,
+				MeterStart:  meterStart,
+				Timestamp:   occurredAt,
+			},
+		}
+	}
+	startTransactionResponse := func(id string, requestID string, occurredAt time.Time, transactionID int) domain.Event {
+		return domain.Event{
+			ID:            id,
+			CorrelationID: "correlation-" + requestID,
+			MessageType:   domain.EventTypeStartTransactionResponse,
+			OccurredAt:    occurredAt,
+			Payload: domain.StartTransactionResponsePayload{
+				IDTagInfo:     domain.IDTagInfo{Status: domain.AuthorizationAccepted},
+				TransactionID: transactionID,
+			},
+		}
+	}
+	stopTransaction := func(id string, occurredAt time.Time, transactionID int, meterStop int, reason domain.StopReason) domain.Event {
+		return domain.Event{
+			ID:            id,
+			CorrelationID: "correlation-" + id,
+			MessageType:   domain.EventTypeStopTransaction,
+			// The station's clock is a second behind.
+			OccurredAt: occurredAt.Add(time.Second),
+			Payload: domain.StopTransactionPayload{
+				StationID:     "station-1",
+				TransactionID: transactionID,
+				MeterStop:     meterStop,
+				Timestamp:     occurredAt,
+				Reason:        reason,
+			},
+		}
+	}
+	events := []domain.Event{
+		meterValues("meter-values-1", minutes(0), "1000", "0"),
+		meterValues("meter-values-2", minutes(10), "2000"),
+		meterValues("meter-values-3", minutes(20), "3000", "2000"),
+		// Agrees with the meter values.
+		startTransaction("start-1", minutes(0), 1, "tag-1", 1000),
+		startTransactionResponse("start-1-response", "start-1", minutes(0), 1),
+		stopTransaction("stop-1", minutes(20), 1, 3000, domain.StopReasonEVDisconnected),
+		// Stopped again.
+		stopTransaction("stop-1-again", minutes(21), 1, 3100, domain.StopReasonOther),
+		// Delivered 500 Wh, where the meter values delivered 1000 Wh.
+		startTransaction("start-2", minutes(5), 2, "tag-2", 500),
+		startTransactionResponse("start-2-response", "start-2", minutes(5), 2),
+		stopTransaction("stop-2", minutes(15), 2, 1000, ""),
+		// Ongoing, and not yet answered.
+		startTransaction("start-3", minutes(25), 1, "tag-3", 3000),
+		// Stopped, but not started as far as is known.
+		stopTransaction("stop-4", minutes(30), 4, 100, domain.StopReasonRemote),
+	}
+	stoppedAt := func(n int) *time.Time {
+		t := minutes(n)
+		return &t
+	}
+	intPtr := func(i int) *int {
+		return &i
+	}
+	decimal := func(s string) *domain.Decimal {
+		d := domain.MustParseDecimal(s)
+		return &d
+	}
+	want := []domain.Session{
+		{
+			StationID:     "station-1",
+			ConnectorID:   0,
+			TransactionID: intPtr(4),
+			StoppedAt:     stoppedAt(30),
+			MeterStop:     intPtr(100),
+			StopReason:    domain.StopReasonRemote,
+			Check:         domain.SessionUnchecked,
+			StopEventID:   "stop-4",
+		},
+		{
+			StationID:     "station-1",
+			ConnectorID:   1,
+			TransactionID: intPtr(1),
+			IDTag:         "tag-1",
+			IDTagStatus:   domain.AuthorizationAccepted,
+			StartedAt:     minutes(0),
+			StoppedAt:     stoppedAt(20),
+			Duration:      20 * time.Minute,
+			MeterStart:    1000,
+			MeterStop:     intPtr(3000),
+			Wh:            decimal("2000"),
+			StopReason:    domain.StopReasonEVDisconnected,
+			MeterValues: &domain.EnergyDelivered{
+				StationID:   "station-1",
+				ConnectorID: 1,
+				From:        minutes(0),
+				To:          minutes(20),
+				Wh:          domain.MustParseDecimal("2000"),
+				Confidence:  domain.EnergyExact,
+			},
+			Check:        domain.SessionConsistent,
+			StartEventID: "start-1",
+			StopEventID:  "stop-1",
+		},
+		{
+			StationID:    "station-1",
+			ConnectorID:  1,
+			IDTag:        "tag-3",
+			StartedAt:    minutes(25),
+			MeterStart:   3000,
+			Check:        domain.SessionUnchecked,
+			StartEventID: "start-3",
+		},
+		{
+			StationID:     "station-1",
+			ConnectorID:   2,
+			TransactionID: intPtr(2),
+			IDTag:         "tag-2",
+			IDTagStatus:   domain.AuthorizationAccepted,
+			StartedAt:     minutes(5),
+			StoppedAt:     stoppedAt(15),
+			Duration:      10 * time.Minute,
+			MeterStart:    500,
+			MeterStop:     intPtr(1000),
+			Wh:            decimal("500"),
+			StopReason:    domain.StopReasonLocal,
+			MeterValues: &domain.EnergyDelivered{
+				StationID:   "station-1",
+				ConnectorID: 2,
+				From:        minutes(5),
+				To:          minutes(15),
+				Wh:          domain.MustParseDecimal("1000.000"),
+				Confidence:  domain.EnergyInterpolated,
+			},
+			Check:        domain.SessionInconsistent,
+			StartEventID: "start-2",
+			StopEventID:  "stop-2",
+		},
+	}
+
+	ctx := context.Background()
+	eventSource := inmemoryeventsource.NewInMemoryEventSource(idgenerator.NewULIDGenerator(), domain.OrderBySequence)
+	ip := NewIncrementalProjection()
+	eventSource.Subscribe(ip)
+	for _, event := range events {
+		_, err := eventSource.Create(ctx, event)
+		require.NoError(t, err)
+	}
+
+	for name, projection := range map[string]domain.SessionProjection{
+		"basic":       NewBasicProjection(eventSource),
+		"incremental": ip,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// act
+			got, err := projection.Sessions(ctx, "station-1")
+
+			// assert
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestSessionCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		wh         string
+		measuredWh string
+		confidence domain.EnergyConfidence
+		want       domain.SessionCheck
+	}{
+		{
+			name:       "within 1%",
+			wh:         "10000",
+			measuredWh: "10099",
+			confidence: domain.EnergyInterpolated,
+			want:       domain.SessionConsistent,
+		},
+		{
+			name:       "at least 1 Wh tolerated",
+			wh:         "10",
+			measuredWh: "11",
+			confidence: domain.EnergyExact,
+			want:       domain.SessionConsistent,
+		},
+		{
+			name:       "more than 1% more",
+			wh:         "10000",
+			measuredWh: "10101",
+			confidence: domain.EnergyExact,
+			want:       domain.SessionInconsistent,
+		},
+		{
+			name:       "more than 1% less",
+			wh:         "10000",
+			measuredWh: "9899",
+			confidence: domain.EnergyExact,
+			want:       domain.SessionInconsistent,
+		},
+		{
+			name:       "less, but bracketed",
+			wh:         "10000",
+			measuredWh: "5000",
+			confidence: domain.EnergyBracketed,
+			want:       domain.SessionUnchecked,
+		},
+		{
+			name:       "more, and bracketed",
+			wh:         "10000",
+			measuredWh: "15000",
+			confidence: domain.EnergyBracketed,
+			want:       domain.SessionInconsistent,
+		},
+		{
+			name:       "less, across a possible rollover",
+			wh:         "10000",
+			measuredWh: "5000",
+			confidence: domain.EnergyUncertain,
+			want:       domain.SessionUnchecked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			got := sessionCheck(domain.MustParseDecimal(tt.wh), domain.EnergyDelivered{
+				Wh:         domain.MustParseDecimal(tt.measuredWh),
+				Confidence: tt.confidence,
+			})
+
+			// assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package projection
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// connectorStatuses folds the station's StatusNotification events, in the order they occurred, into the current
+// status of each of its connectors, by connector ID, and the transitions between them, ordered by when they occurred.
+// A notification which repeats the connector's status and error code isn't a transition, but updates the status.
+func connectorStatuses(stationID string, statusNotifications []domain.Event) (map[int32]*domain.ConnectorStatus, []domain.ConnectorStatusTransition, error) {
+	events := append([]domain.Event(nil), statusNotifications...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[j].After(events[i])
+	})
+
+	statuses := make(map[int32]*domain.ConnectorStatus)
+	var transitions []domain.ConnectorStatusTransition
+	for _, event := range events {
+		payload, err := domain.EventPayload(event)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get event payload: %w", err)
+		}
+		notification := payload.(domain.StatusNotificationPayload)
+
+		current, ok := statuses[notification.ConnectorID]
+		if !ok || current.Status != notification.Status || current.ErrorCode != notification.ErrorCode {
+			transition := domain.ConnectorStatusTransition{
+				StationID:   stationID,
+				ConnectorID: notification.ConnectorID,
+				To:          notification.Status,
+				ErrorCode:   notification.ErrorCode,
+				EventID:     event.ID,
+				OccurredAt:  event.OccurredAt,
+			}
+			if ok {
+				transition.From = current.Status
+			}
+			transitions = append(transitions, transition)
+
+			current = &domain.ConnectorStatus{Since: event.OccurredAt}
+			statuses[notification.ConnectorID] = current
+		}
+
+		current.Status = notification.Status
+		current.ErrorCode = notification.ErrorCode
+		current.Info = notification.Info
+		current.VendorID = notification.VendorID
+		current.VendorErrorCode = notification.VendorErrorCode
+		current.UpdatedAt = event.OccurredAt
+	}
+
+	return statuses, transitions, nil
+}
+
+// applyConnectorStatuses sets the status of each of the charging station's connectors, adding the connectors which
+// have only been seen in StatusNotifications, and the status of the station as a whole from connector 0.
+func applyConnectorStatuses(chargingStation *domain.ChargingStation, statusNotifications []domain.Event) error {
+	statuses, _, err := connectorStatuses(chargingStation.ID, statusNotifications)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		if status.UpdatedAt.After(chargingStation.UpdatedAt) {
+			chargingStation.UpdatedAt = status.UpdatedAt
+		}
+	}
+
+	for i := range chargingStation.Connectors {
+		connector := &chargingStation.Connectors[i]
+		connector.Status = statuses[connector.ID]
+		delete(statuses, connector.ID)
+	}
+
+	chargingStation.ConnectorStatus = statuses[0]
+	delete(statuses, 0)
+
+	var connectorIDs []int32
+	for connectorID := range statuses {
+		connectorIDs = append(connectorIDs, connectorID)
+	}
+	sortConnectorIDs(connectorIDs)
+	for _, connectorID := range connectorIDs {
+		chargingStation.Connectors = append(chargingStation.Connectors, domain.Connector{
+			ID:                connectorID,
+			ChargingStationID: chargingStation.ID,
+			UpdatedAt:         statuses[connectorID].UpdatedAt,
+			Status:            statuses[connectorID],
+		})
+	}
+
+	return nil
+}
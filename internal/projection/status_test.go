@@ -0,0 +1,172 @@
+package projection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+	idgenerator "github.com/zucchinho/ocpp/internal/id_generator"
+	inmemoryeventsource "github.com/zucchinho/ocpp/internal/in_memory_event_source"
+)
+
+func TestConnectorStatuses(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	minutes := func(n int) time.Time {
+		return start.Add(time.Duration(n) * time.Minute)
+	}
+	statusNotification := func(id string, occurredAt time.Time, connectorID int32, status domain.ChargePointStatus, errorCode domain.ChargePointErrorCode) domain.Event {
+		return domain.Event{
+			ID:            id,
+			CorrelationID: "correlation-" + id,
+			MessageType:   domain.EventTypeStatusNotification,
+			OccurredAt:    occurredAt,
+			Payload: domain.StatusNotificationPayload{
+				StationID:   "station-1",
+				ConnectorID: connectorID,
+				Status:      status,
+				ErrorCode:   errorCode,
+			},
+		}
+	}
+	meterValuesNotification := domain.Event{
+		ID:            "meter-values",
+		CorrelationID: "correlation-meter-values",
+		MessageType:   domain.EventTypeMeterValuesNotification,
+		OccurredAt:    minutes(0),
+		Payload: domain.MeterValuesNotificationPayload{
+			StationID:   "station-1",
+			MeterValues: []domain.MeterValue{domain.NewMeterValue(1, "100")},
+		},
+	}
+	events := []domain.Event{
+		meterValuesNotification,
+		statusNotification("status-1", minutes(1), 1, domain.StatusAvailable, domain.NoError),
+		statusNotification("status-2", minutes(2), 0, domain.StatusAvailable, domain.NoError),
+		statusNotification("status-3", minutes(3), 1, domain.StatusPreparing, domain.NoError),
+		// A repeated status isn't a transition.
+		statusNotification("status-4", minutes(4), 1, domain.StatusPreparing, domain.NoError),
+		// Received late, but occurred before the connector was preparing.
+		statusNotification("status-5", minutes(2), 2, domain.StatusAvailable, domain.NoError),
+		statusNotification("status-6", minutes(5), 1, domain.StatusCharging, domain.NoError),
+		// The same status with another error is a transition.
+		statusNotification("status-7", minutes(6), 2, domain.StatusAvailable, "HighTemperature"),
+		statusNotification("status-8", minutes(7), 2, domain.StatusFaulted, "GroundFailure"),
+	}
+
+	ctx := context.Background()
+	eventSource := inmemoryeventsource.NewInMemoryEventSource(idgenerator.NewULIDGenerator(), domain.OrderBySequence)
+	ip := NewIncrementalProjection()
+	eventSource.Subscribe(ip)
+	for _, event := range events {
+		_, err := eventSource.Create(ctx, event)
+		require.NoError(t, err)
+	}
+
+	for name, projection := range map[string]interface {
+		domain.StatusProjection
+		domain.ChargingStationProjection
+	}{
+		"basic":       NewBasicProjection(eventSource),
+		"incremental": ip,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// act
+			transitions, err := projection.ConnectorStatusTransitions(ctx, "station-1")
+			require.NoError(t, err)
+			chargingStation, err := projection.ChargingStation(ctx, "station-1")
+			require.NoError(t, err)
+
+			// assert
+			assert.Equal(t, []domain.ConnectorStatusTransition{
+				{StationID: "station-1", ConnectorID: 1, To: domain.StatusAvailable, ErrorCode: domain.NoError, EventID: "status-1", OccurredAt: minutes(1)},
+				{StationID: "station-1", ConnectorID: 0, To: domain.StatusAvailable, ErrorCode: domain.NoError, EventID: "status-2", OccurredAt: minutes(2)},
+				{StationID: "station-1", ConnectorID: 2, To: domain.StatusAvailable, ErrorCode: domain.NoError, EventID: "status-5", OccurredAt: minutes(2)},
+				{StationID: "station-1", ConnectorID: 1, From: domain.StatusAvailable, To: domain.StatusPreparing, ErrorCode: domain.NoError, EventID: "status-3", OccurredAt: minutes(3)},
+				{StationID: "station-1", ConnectorID: 1, From: domain.StatusPreparing, To: domain.StatusCharging, ErrorCode: domain.NoError, EventID: "status-6", OccurredAt: minutes(5)},
+				{StationID: "station-1", ConnectorID: 2, From: domain.StatusAvailable, To: domain.StatusAvailable, ErrorCode: "HighTemperature", EventID: "status-7", OccurredAt: minutes(6)},
+				{StationID: "station-1", ConnectorID: 2, From: domain.StatusAvailable, To: domain.StatusFaulted, ErrorCode: "GroundFailure", EventID: "status-8", OccurredAt: minutes(7)},
+			}, transitions)
+
+			assert.Equal(t, 1, chargingStation.NumConnectors)
+			assert.Equal(t, minutes(7), chargingStation.UpdatedAt)
+			assert.Equal(t, &domain.ConnectorStatus{
+				Status:    domain.StatusAvailable,
+				ErrorCode: domain.NoError,
+				Since:     minutes(2),
+				UpdatedAt: minutes(2),
+			}, chargingStation.ConnectorStatus)
+			assert.Equal(t, []domain.Connector{
+				{
+					ID:                1,
+					ChargingStationID: "station-1",
+					Reading:           "100",
+					ReadingValue:      readingValue("100"),
+					UpdatedAt:         minutes(0),
+					Status: &domain.ConnectorStatus{
+						Status:    domain.StatusCharging,
+						ErrorCode: domain.NoError,
+						Since:     minutes(5),
+						UpdatedAt: minutes(5),
+					},
+				},
+				// Only seen in status notifications.
+				{
+					ID:                2,
+					ChargingStationID: "station-1",
+					UpdatedAt:         minutes(7),
+					Status: &domain.ConnectorStatus{
+						Status:    domain.StatusFaulted,
+						ErrorCode: "GroundFailure",
+						Since:     minutes(7),
+						UpdatedAt: minutes(7),
+					},
+				},
+			}, chargingStation.Connectors)
+		})
+	}
+}
+
+func TestConnectorStatuses_RepeatedStatusUpdates(t *testing.T) {
+	// arrange
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []domain.Event{
+		{
+			ID:          "status-1",
+			MessageType: domain.EventTypeStatusNotification,
+			OccurredAt:  start,
+			Payload: domain.StatusNotificationPayload{
+				StationID: "station-1", ConnectorID: 1, Status: domain.StatusFaulted, ErrorCode: "OtherError", Info: "first",
+			},
+		},
+		{
+			ID:          "status-2",
+			MessageType: domain.EventTypeStatusNotification,
+			OccurredAt:  start.Add(time.Minute),
+			Payload: domain.StatusNotificationPayload{
+				StationID: "station-1", ConnectorID: 1, Status: domain.StatusFaulted, ErrorCode: "OtherError", Info: "second",
+				VendorID: "vendor", VendorErrorCode: "E42",
+			},
+		},
+	}
+
+	// act
+	statuses, transitions, err := connectorStatuses("station-1", events)
+
+	// assert
+	require.NoError(t, err)
+	assert.Len(t, transitions, 1)
+	assert.Equal(t, map[int32]*domain.ConnectorStatus{
+		1: {
+			Status:          domain.StatusFaulted,
+			ErrorCode:       "OtherError",
+			Info:            "second",
+			VendorID:        "vendor",
+			VendorErrorCode: "E42",
+			Since:           start,
+			UpdatedAt:       start.Add(time.Minute),
+		},
+	}, statuses)
+}
@@ -0,0 +1,117 @@
+package projection
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+// validationLog returns the violations of the responses in the events against their requests which are selected by
+// the filter, ordered by when the responses occurred.
+func validationLog(events []domain.Event, filter domain.ValidationLogFilter) ([]domain.ResponseViolation, error) {
+	paired, _ := exchanges(events)
+
+	return validationLogFromExchanges(paired, filter)
+}
+
+// validationLogFromExchanges returns the violations of the exchanges' responses against their requests which are
+// selected by the filter, ordered by when the responses occurred.
+func validationLogFromExchanges(paired []exchange, filter domain.ValidationLogFilter) ([]domain.ResponseViolation, error) {
+	var violations []domain.ResponseViolation
+	for _, e := range paired {
+		exchangeViolations, err := responseViolations(e)
+		if err != nil {
+			return nil, fmt.Errorf("validate responses to request %s: %w", e.request.ID, err)
+		}
+		for _, violation := range exchangeViolations {
+			if filter.Matches(violation) {
+				violations = append(violations, violation)
+			}
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[j].Response.After(violations[i].Response)
+	})
+
+	return violations, nil
+}
+
+// responseViolations returns the violations of the exchange's responses against its request.
+func responseViolations(e exchange) ([]domain.ResponseViolation, error) {
+	request, stationID := *e.request, e.stationID()
+
+	var requestedConnectorID int32
+	if request.MessageType == domain.EventTypeMeterValuesRequest {
+		payload, err := domain.EventPayload(request)
+		if err != nil {
+			return nil, fmt.Errorf("event payload: %w", err)
+		}
+		requestedConnectorID = payload.(domain.MeterValuesRequestPayload).ConnectorID
+	}
+
+	var violations []domain.ResponseViolation
+	for _, response := range e.responses {
+		newViolation := func(kind domain.ResponseViolationKind, detail string) domain.ResponseViolation {
+			return domain.ResponseViolation{
+				StationID:     stationID,
+				CorrelationID: request.CorrelationID,
+				Kind:          kind,
+				Request:       request,
+				Response:      response,
+				Detail:        detail,
+			}
+		}
+
+		if stationID == "" {
+			violations = append(violations, newViolation(domain.ResponseViolationUnknownStation, "the request doesn't name a station"))
+		}
+		if response.OccurredAt.Before(request.OccurredAt) {
+			violations = append(violations, newViolation(domain.ResponseViolationBeforeRequest, fmt.Sprintf("occurred %s before the request", request.OccurredAt.Sub(response.OccurredAt))))
+		}
+
+		if response.MessageType != domain.EventTypeMeterValuesResponse {
+			continue
+		}
+		payload, err := domain.EventPayload(response)
+		if err != nil {
+			return nil, fmt.Errorf("event payload: %w", err)
+		}
+		for _, meterValue := range payload.(domain.MeterValuesResponsePayload).MeterValues {
+			if requestedConnectorID == 0 || meterValue.ConnectorID == requestedConnectorID {
+				continue
+			}
+			violation := newViolation(domain.ResponseViolationUnrequestedConnector, fmt.Sprintf("connector %d was requested", requestedConnectorID))
+			violation.ConnectorID = meterValue.ConnectorID
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations, nil
+}
+
+// requestedMeterValues returns the meter values of the MeterValuesResponse for the connector its request asked for, or
+// all of them if the request didn't ask for one, or is nil as it isn't known.
+func requestedMeterValues(request *domain.Event, payload domain.MeterValuesResponsePayload) ([]domain.MeterValue, error) {
+	if request == nil {
+		return payload.MeterValues, nil
+	}
+	requestPayload, err := domain.EventPayload(*request)
+	if err != nil {
+		return nil, fmt.Errorf("event payload: %w", err)
+	}
+	connectorID := requestPayload.(domain.MeterValuesRequestPayload).ConnectorID
+	if connectorID == 0 {
+		return payload.MeterValues, nil
+	}
+
+	var meterValues []domain.MeterValue
+	for _, meterValue := range payload.MeterValues {
+		if meterValue.ConnectorID == connectorID {
+			meterValues = append(meterValues, meterValue)
+		}
+	}
+
+	return meterValues, nil
+}
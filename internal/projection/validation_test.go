@@ -0,0 +1,147 @@
+package projection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+func TestValidationLog(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []domain.Event{
+		// Answered with the requested connector and another one.
+		{
+			ID:            "request-1",
+			CorrelationID: "correlation-1",
+			MessageType:   domain.EventTypeMeterValuesRequest,
+			OccurredAt:    start,
+			StationID:     "station-1",
+			Payload:       domain.MeterValuesRequestPayload{StationID: "station-1", ConnectorID: 1},
+		},
+		{
+			ID:            "response-1",
+			CorrelationID: "correlation-1",
+			MessageType:   domain.EventTypeMeterValuesResponse,
+			OccurredAt:    start.Add(time.Minute),
+			Payload: domain.MeterValuesResponsePayload{MeterValues: []domain.MeterValue{
+				domain.NewMeterValue(1, "100"),
+				domain.NewMeterValue(2, "200"),
+			}},
+		},
+		// Answered before it was sent.
+		{
+			ID:            "request-2",
+			CorrelationID: "correlation-2",
+			MessageType:   domain.EventTypeConnectorListRequest,
+			OccurredAt:    start.Add(3 * time.Minute),
+			StationID:     "station-2",
+			Payload:       domain.ConnectorListRequestPayload{StationID: "station-2"},
+		},
+		{
+			ID:            "response-2",
+			CorrelationID: "correlation-2",
+			MessageType:   domain.EventTypeConnectorListResponse,
+			OccurredAt:    start.Add(2 * time.Minute),
+			Payload:       domain.ConnectorListResponsePayload{NumConnectors: 2},
+		},
+		// Sent to no station.
+		{
+			ID:            "request-3",
+			CorrelationID: "correlation-3",
+			MessageType:   domain.EventTypeMeterValuesRequest,
+			OccurredAt:    start.Add(4 * time.Minute),
+			Payload:       domain.MeterValuesRequestPayload{ConnectorID: 1},
+		},
+		{
+			ID:            "response-3",
+			CorrelationID: "correlation-3",
+			MessageType:   domain.EventTypeMeterValuesResponse,
+			OccurredAt:    start.Add(5 * time.Minute),
+			Payload: domain.MeterValuesResponsePayload{MeterValues: []domain.MeterValue{
+				domain.NewMeterValue(1, "100"),
+			}},
+		},
+		// Asked for no connector in particular.
+		{
+			ID:            "request-4",
+			CorrelationID: "correlation-4",
+			MessageType:   domain.EventTypeMeterValuesRequest,
+			OccurredAt:    start.Add(6 * time.Minute),
+			StationID:     "station-1",
+			Payload:       domain.MeterValuesRequestPayload{StationID: "station-1"},
+		},
+		{
+			ID:            "response-4",
+			CorrelationID: "correlation-4",
+			MessageType:   domain.EventTypeMeterValuesResponse,
+			OccurredAt:    start.Add(7 * time.Minute),
+			Payload: domain.MeterValuesResponsePayload{MeterValues: []domain.MeterValue{
+				domain.NewMeterValue(1, "100"),
+				domain.NewMeterValue(2, "200"),
+			}},
+		},
+	}
+
+	type violation struct {
+		kind        domain.ResponseViolationKind
+		responseID  string
+		connectorID int32
+	}
+
+	tests := []struct {
+		name   string
+		filter domain.ValidationLogFilter
+		want   []violation
+	}{
+		{
+			name: "all",
+			want: []violation{
+				{kind: domain.ResponseViolationUnrequestedConnector, responseID: "response-1", connectorID: 2},
+				{kind: domain.ResponseViolationBeforeRequest, responseID: "response-2"},
+				{kind: domain.ResponseViolationUnknownStation, responseID: "response-3"},
+			},
+		},
+		{
+			name:   "by station",
+			filter: domain.ValidationLogFilter{StationID: "station-2"},
+			want: []violation{
+				{kind: domain.ResponseViolationBeforeRequest, responseID: "response-2"},
+			},
+		},
+		{
+			name:   "by kind",
+			filter: domain.ValidationLogFilter{Kinds: []domain.ResponseViolationKind{domain.ResponseViolationUnknownStation, domain.ResponseViolationUnrequestedConnector}},
+			want: []violation{
+				{kind: domain.ResponseViolationUnrequestedConnector, responseID: "response-1", connectorID: 2},
+				{kind: domain.ResponseViolationUnknownStation, responseID: "response-3"},
+			},
+		},
+		{
+			name:   "by when the response occurred",
+			filter: domain.ValidationLogFilter{OccurredFrom: start.Add(2 * time.Minute), OccurredTo: start.Add(5 * time.Minute)},
+			want: []violation{
+				{kind: domain.ResponseViolationBeforeRequest, responseID: "response-2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// act
+			violations, err := validationLog(events, tt.filter)
+
+			// assert
+			require.NoError(t, err)
+			var got []violation
+			for _, v := range violations {
+				got = append(got, violation{kind: v.Kind, responseID: v.Response.ID, connectorID: v.ConnectorID})
+				assert.Equal(t, v.Request.CorrelationID, v.CorrelationID)
+				assert.NotEmpty(t, v.Detail)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
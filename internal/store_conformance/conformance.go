@@ -0,0 +1,320 @@
+// Package storeconformance contains the tests which every domain.Store implementation must pass.
+package storeconformance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zucchinho/ocpp/internal/domain"
+)
+
+var now = time.Date(2022, 1, 1, 0, 1, 0, 0, time.UTC)
+var oneMinuteAgo = now.Add(-1 * time.Minute)
+
+// Run runs the conformance tests against stores created by newStore. Each test gets a new, empty store.
+func Run(t *testing.T, newStore func(t *testing.T) domain.Store) {
+	t.Run("GetChargingStation_NotFound", func(t *testing.T) {
+		testGetChargingStationNotFound(t, newStore(t))
+	})
+	t.Run("UpsertChargingStation", func(t *testing.T) {
+		testUpsertChargingStation(t, newStore(t))
+	})
+	t.Run("UpsertChargingStation_Replaces", func(t *testing.T) {
+		testUpsertChargingStationReplaces(t, newStore(t))
+	})
+	t.Run("UpsertChargingStation_NoID", func(t *testing.T) {
+		testUpsertChargingStationNoID(t, newStore(t))
+	})
+	t.Run("UpsertConnector", func(t *testing.T) {
+		testUpsertConnector(t, newStore(t))
+	})
+	t.Run("UpsertConnector_Replaces", func(t *testing.T) {
+		testUpsertConnectorReplaces(t, newStore(t))
+	})
+	t.Run("UpsertConnector_ChargingStationNotFound", func(t *testing.T) {
+		testUpsertConnectorChargingStationNotFound(t, newStore(t))
+	})
+	t.Run("GetChargingStations", func(t *testing.T) {
+		testGetChargingStations(t, newStore(t))
+	})
+	t.Run("ReturnedChargingStationsAreCopies", func(t *testing.T) {
+		testReturnedChargingStationsAreCopies(t, newStore(t))
+	})
+	t.Run("ConcurrentUpserts", func(t *testing.T) {
+		testConcurrentUpserts(t, newStore(t))
+	})
+}
+
+func testGetChargingStationNotFound(t *testing.T, store domain.Store) {
+	// act
+	chargingStation, err := store.GetChargingStation(context.Background(), "station-1")
+
+	// assert
+	assert.Equal(t, domain.ChargingStation{}, chargingStation)
+	assert.ErrorIs(t, err, domain.ErrChargingStationNotFound)
+}
+
+func testUpsertChargingStation(t *testing.T, store domain.Store) {
+	// arrange
+	chargingStation := domain.ChargingStation{
+		ID:            "station-1",
+		NumConnectors: 1,
+		Connectors: []domain.Connector{
+			{
+				ID:                1,
+				ChargingStationID: "station-1",
+				Reading:           "100",
+				UpdatedAt:         now,
+			},
+		},
+		UpdatedAt: now,
+	}
+
+	// act
+	id, err := store.UpsertChargingStation(context.Background(), chargingStation)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, "station-1", id)
+
+	got, err := store.GetChargingStation(context.Background(), "station-1")
+	require.NoError(t, err)
+	assert.Equal(t, chargingStation, got)
+}
+
+func testUpsertChargingStationReplaces(t *testing.T, store domain.Store) {
+	// arrange
+	_, err := store.UpsertChargingStation(context.Background(), domain.ChargingStation{
+		ID:            "station-1",
+		NumConnectors: 2,
+		UpdatedAt:     oneMinuteAgo,
+	})
+	require.NoError(t, err)
+	chargingStation := domain.ChargingStation{
+		ID:            "station-1",
+		NumConnectors: 3,
+		UpdatedAt:     now,
+	}
+
+	// act
+	_, err = store.UpsertChargingStation(context.Background(), chargingStation)
+
+	// assert
+	require.NoError(t, err)
+
+	got, err := store.GetChargingStation(context.Background(), "station-1")
+	require.NoError(t, err)
+	assert.Equal(t, chargingStation, got)
+}
+
+func testUpsertChargingStationNoID(t *testing.T, store domain.Store) {
+	// act
+	_, err := store.UpsertChargingStation(context.Background(), domain.ChargingStation{
+		NumConnectors: 1,
+	})
+
+	// assert
+	assert.Error(t, err)
+
+	chargingStations, err := store.GetChargingStations(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, chargingStations)
+}
+
+func testUpsertConnector(t *testing.T, store domain.Store) {
+	// arrange
+	_, err := store.UpsertChargingStation(context.Background(), domain.ChargingStation{
+		ID:        "station-1",
+		UpdatedAt: oneMinuteAgo,
+	})
+	require.NoError(t, err)
+	connector := domain.Connector{
+		ID:                2,
+		ChargingStationID: "station-1",
+		Reading:           "200",
+		UpdatedAt:         now,
+	}
+
+	// act
+	id, err := store.UpsertConnector(context.Background(), connector)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, "2", id)
+
+	got, err := store.GetChargingStation(context.Background(), "station-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChargingStation{
+		ID:            "station-1",
+		NumConnectors: 1,
+		Connectors:    []domain.Connector{connector},
+		UpdatedAt:     now,
+	}, got)
+}
+
+func testUpsertConnectorReplaces(t *testing.T, store domain.Store) {
+	// arrange
+	_, err := store.UpsertChargingStation(context.Background(), domain.ChargingStation{
+		ID:            "station-1",
+		NumConnectors: 2,
+		Connectors: []domain.Connector{
+			{
+				ID:                1,
+				ChargingStationID: "station-1",
+				Reading:           "100",
+				UpdatedAt:         oneMinuteAgo,
+			},
+			{
+				ID:                2,
+				ChargingStationID: "station-1",
+				Reading:           "200",
+				UpdatedAt:         oneMinuteAgo,
+			},
+		},
+		UpdatedAt: oneMinuteAgo,
+	})
+	require.NoError(t, err)
+
+	// act
+	_, err = store.UpsertConnector(context.Background(), domain.Connector{
+		ID:                1,
+		ChargingStationID: "station-1",
+		Reading:           "120",
+		UpdatedAt:         now,
+	})
+
+	// assert
+	require.NoError(t, err)
+
+	got, err := store.GetChargingStation(context.Background(), "station-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChargingStation{
+		ID:            "station-1",
+		NumConnectors: 2,
+		Connectors: []domain.Connector{
+			{
+				ID:                1,
+				ChargingStationID: "station-1",
+				Reading:           "120",
+				UpdatedAt:         now,
+			},
+			{
+				ID:                2,
+				ChargingStationID: "station-1",
+				Reading:           "200",
+				UpdatedAt:         oneMinuteAgo,
+			},
+		},
+		UpdatedAt: now,
+	}, got)
+}
+
+func testUpsertConnectorChargingStationNotFound(t *testing.T, store domain.Store) {
+	// act
+	_, err := store.UpsertConnector(context.Background(), domain.Connector{
+		ID:                1,
+		ChargingStationID: "station-1",
+		Reading:           "100",
+		UpdatedAt:         now,
+	})
+
+	// assert
+	assert.ErrorIs(t, err, domain.ErrChargingStationNotFound)
+}
+
+func testGetChargingStations(t *testing.T, store domain.Store) {
+	// arrange
+	for _, id := range []string{"station-2", "station-1", "station-3"} {
+		_, err := store.UpsertChargingStation(context.Background(), domain.ChargingStation{
+			ID:            id,
+			NumConnectors: 1,
+			UpdatedAt:     now,
+		})
+		require.NoError(t, err)
+	}
+
+	// act
+	got, err := store.GetChargingStations(context.Background())
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ChargingStation{
+		{ID: "station-1", NumConnectors: 1, UpdatedAt: now},
+		{ID: "station-2", NumConnectors: 1, UpdatedAt: now},
+		{ID: "station-3", NumConnectors: 1, UpdatedAt: now},
+	}, got)
+}
+
+func testReturnedChargingStationsAreCopies(t *testing.T, store domain.Store) {
+	// arrange
+	_, err := store.UpsertChargingStation(context.Background(), domain.ChargingStation{
+		ID:            "station-1",
+		NumConnectors: 1,
+		Connectors: []domain.Connector{
+			{
+				ID:                1,
+				ChargingStationID: "station-1",
+				Reading:           "100",
+				UpdatedAt:         now,
+			},
+		},
+		UpdatedAt: now,
+	})
+	require.NoError(t, err)
+
+	// act
+	got, err := store.GetChargingStation(context.Background(), "station-1")
+	require.NoError(t, err)
+	got.Connectors[0].Reading = "999"
+
+	// assert
+	got, err = store.GetChargingStation(context.Background(), "station-1")
+	require.NoError(t, err)
+	assert.Equal(t, "100", got.Connectors[0].Reading)
+}
+
+func testConcurrentUpserts(t *testing.T, store domain.Store) {
+	// arrange
+	const numStations = 10
+	const numConnectors = 5
+	for i := 0; i < numStations; i++ {
+		_, err := store.UpsertChargingStation(context.Background(), domain.ChargingStation{
+			ID:        fmt.Sprintf("station-%d", i),
+			UpdatedAt: now,
+		})
+		require.NoError(t, err)
+	}
+
+	// act
+	var wg sync.WaitGroup
+	for i := 0; i < numStations; i++ {
+		for j := 1; j <= numConnectors; j++ {
+			wg.Add(1)
+			go func(stationID string, connectorID int32) {
+				defer wg.Done()
+				_, err := store.UpsertConnector(context.Background(), domain.Connector{
+					ID:                connectorID,
+					ChargingStationID: stationID,
+					Reading:           "100",
+					UpdatedAt:         now,
+				})
+				assert.NoError(t, err)
+			}(fmt.Sprintf("station-%d", i), int32(j))
+		}
+	}
+	wg.Wait()
+
+	// assert
+	chargingStations, err := store.GetChargingStations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, chargingStations, numStations)
+	for _, chargingStation := range chargingStations {
+		assert.Equal(t, numConnectors, chargingStation.NumConnectors, chargingStation.ID)
+		assert.Len(t, chargingStation.Connectors, numConnectors, chargingStation.ID)
+	}
+}